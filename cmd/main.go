@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
 
+	"admission-portal-backend/internal/auth"
 	"admission-portal-backend/internal/config"
+	"admission-portal-backend/internal/controllers"
+	"admission-portal-backend/internal/mailer"
+	"admission-portal-backend/internal/migrations"
+	"admission-portal-backend/internal/payments"
+	"admission-portal-backend/internal/repositories"
 	"admission-portal-backend/internal/routes"
+	"admission-portal-backend/internal/storage"
 )
 
 func main() {
@@ -17,8 +27,38 @@ func main() {
 		log.Println("No .env file found")
 	}
 
-	// Connect to MongoDB
-	config.ConnectDB()
+	keys, err := auth.KeyringFromEnv()
+	if err != nil {
+		log.Fatal("Error loading JWT keys: ", err)
+	}
+	auth.SetKeyring(keys)
+	log.Printf("Signing tokens with key %s", keys.SigningKeyID())
+
+	// Pick the storage backend. STORAGE=memory runs the API without MongoDB.
+	var store *repositories.Store
+	if os.Getenv("STORAGE") == "memory" {
+		store = repositories.NewMemoryStore()
+		log.Println("Using in-memory storage")
+	} else {
+		config.ConnectDB()
+		migrateOnStart(config.DB)
+		store = repositories.NewMongoStore(config.DB)
+	}
+
+	mail, err := mailer.FromEnv()
+	if err != nil {
+		log.Fatal("Error configuring mailer: ", err)
+	}
+
+	blobs, err := storage.FromEnv()
+	if err != nil {
+		log.Fatal("Error configuring blob storage: ", err)
+	}
+
+	gateway, err := payments.FromEnv()
+	if err != nil {
+		log.Fatal("Error configuring payment gateway: ", err)
+	}
 
 	// Initialize Gin router
 	router := gin.Default()
@@ -27,8 +67,26 @@ func main() {
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 
+	handler := controllers.NewHandler(store, mail, blobs, gateway)
+
+	// On first run, issue the invite that creates the first super admin
+	if err := handler.Bootstrap(context.Background()); err != nil {
+		log.Fatal("Error preparing first-run bootstrap: ", err)
+	}
+
+	// OFFER_WINDOW is how long applicants have to answer an offer, e.g. 72h
+	if raw := os.Getenv("OFFER_WINDOW"); raw != "" {
+		window, err := time.ParseDuration(raw)
+		if err != nil || window < 0 {
+			log.Fatal("Invalid OFFER_WINDOW: ", raw)
+		}
+		handler.AdmissionService.OfferWindow = window
+	}
+	go handler.AdmissionService.RunOfferExpiry(context.Background(), time.Minute)
+	go handler.Audit.RunQueue(context.Background(), time.Minute)
+
 	// Register all API routes
-	routes.SetupRoutes(router)
+	routes.SetupRoutes(router, handler)
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -40,3 +98,24 @@ func main() {
 		log.Fatal("Failed to start server: ", err)
 	}
 }
+
+// migrateOnStart applies pending database migrations before serving, unless
+// MIGRATE_ON_START=false leaves them to portalctl, in which case it only
+// warns about what is pending.
+func migrateOnStart(db *mongo.Database) {
+	ctx := context.Background()
+	runner := migrations.NewRunner(db)
+	if os.Getenv("MIGRATE_ON_START") == "false" {
+		pending, err := runner.Pending(ctx)
+		if err != nil {
+			log.Fatal("Error checking database migrations: ", err)
+		}
+		if len(pending) > 0 {
+			log.Printf("Warning: %d database migrations are pending; run portalctl migrate up", len(pending))
+		}
+		return
+	}
+	if _, err := runner.Up(ctx); err != nil {
+		log.Fatal("Error migrating database: ", err)
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"admission-portal-backend/internal/audit"
+)
+
+var exportCommand = command{
+	usage:   "export [-o FILE] [COLLECTION...]",
+	summary: "write collections, all but schema_migrations by default, to FILE or standard output as extended JSON",
+	run:     runExport,
+}
+
+var importCommand = command{
+	usage:   "import [-replace] FILE",
+	summary: "load an export; documents whose _id exists are skipped, or overwritten with -replace",
+	run:     runImport,
+}
+
+// skippedCollections are never exported: each database keeps its own
+// record of the migrations applied to it.
+var skippedCollections = map[string]bool{"schema_migrations": true}
+
+// importBatchSize is how many documents import writes at a time.
+const importBatchSize = 500
+
+// An export is one JSON object, {"exportedAt": DATE, "collections": {NAME:
+// [DOCUMENT, ...], ...}}. Documents are in canonical extended JSON so that
+// ObjectIDs, dates and numbers survive the trip, and both export and import
+// handle them one at a time so the file never has to fit in memory.
+
+// collectionCount reports how many documents were exported from or
+// imported into a collection.
+type collectionCount struct {
+	Collection string `json:"collection"`
+	Documents  int    `json:"documents"`
+	Inserted   int    `json:"inserted,omitempty"`
+	Replaced   int    `json:"replaced,omitempty"`
+	Skipped    int    `json:"skipped,omitempty"`
+}
+
+func runExport(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("o", "-", "file to write, - for standard output")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	db := a.database()
+	names := flags.Args()
+	if len(names) == 0 {
+		all, err := db.ListCollectionNames(ctx, bson.M{})
+		if err != nil {
+			return err
+		}
+		for _, name := range all {
+			if !skippedCollections[name] {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	out := os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	} else {
+		// Keep the summary out of the export
+		a.out = os.Stderr
+	}
+	w := bufio.NewWriter(out)
+
+	fmt.Fprintf(w, "{\"exportedAt\":{\"$date\":{\"$numberLong\":\"%d\"}},\"collections\":{", time.Now().UnixMilli())
+	counts := make([]collectionCount, 0, len(names))
+	for i, name := range names {
+		if i > 0 {
+			w.WriteString(",")
+		}
+		fmt.Fprintf(w, "\n%q:[", name)
+		count, err := exportCollection(ctx, db.Collection(name), w)
+		if err != nil {
+			return fmt.Errorf("exporting %s: %w", name, err)
+		}
+		w.WriteString("]")
+		counts = append(counts, collectionCount{Collection: name, Documents: count})
+	}
+	w.WriteString("\n}}\n")
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if out != os.Stdout {
+		if err := out.Close(); err != nil {
+			return err
+		}
+	}
+
+	return a.emit(map[string]any{"collections": counts}, func(w io.Writer) {
+		for _, c := range counts {
+			fmt.Fprintf(w, "Exported %d documents from %s\n", c.Documents, c.Collection)
+		}
+	})
+}
+
+// exportCollection writes every document in collection to w, separated by
+// commas, and returns how many it wrote.
+func exportCollection(ctx context.Context, collection *mongo.Collection, w *bufio.Writer) (int, error) {
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+	count := 0
+	for cursor.Next(ctx) {
+		doc, err := bson.MarshalExtJSON(cursor.Current, true, false)
+		if err != nil {
+			return count, err
+		}
+		if count > 0 {
+			w.WriteString(",")
+		}
+		w.WriteString("\n")
+		w.Write(doc)
+		count++
+	}
+	return count, cursor.Err()
+}
+
+func runImport(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	replace := flags.Bool("replace", false, "overwrite documents whose _id already exists")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	in := os.Stdin
+	if path := flags.Arg(0); path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+	dec := json.NewDecoder(bufio.NewReader(in))
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	db := a.database()
+	var exportedAt time.Time
+	counts := []collectionCount{}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("reading export: %w", err)
+		}
+		switch key {
+		case "exportedAt":
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return fmt.Errorf("reading export: %w", err)
+			}
+			var header struct {
+				ExportedAt time.Time `bson:"exportedAt"`
+			}
+			if err := bson.UnmarshalExtJSON([]byte(`{"exportedAt":`+string(raw)+`}`), true, &header); err != nil {
+				return fmt.Errorf("reading export: %w", err)
+			}
+			exportedAt = header.ExportedAt
+		case "collections":
+			if err := expectDelim(dec, '{'); err != nil {
+				return err
+			}
+			for dec.More() {
+				token, err := dec.Token()
+				if err != nil {
+					return fmt.Errorf("reading export: %w", err)
+				}
+				name, _ := token.(string)
+				if skippedCollections[name] {
+					if err := dec.Decode(&json.RawMessage{}); err != nil {
+						return fmt.Errorf("reading export: %w", err)
+					}
+					continue
+				}
+				count, err := importCollection(ctx, db.Collection(name), dec, *replace)
+				if err != nil {
+					return fmt.Errorf("importing %s: %w", name, err)
+				}
+				counts = append(counts, count)
+				a.audit(ctx, audit.ActionDataImport, audit.TargetCollection, name, nil, count)
+			}
+			if err := expectDelim(dec, '}'); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("reading export: unexpected field %v", key)
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	return a.emit(map[string]any{"exportedAt": exportedAt, "collections": counts}, func(w io.Writer) {
+		for _, c := range counts {
+			fmt.Fprintf(w, "Imported %s: %d inserted, %d replaced, %d skipped\n", c.Collection, c.Inserted, c.Replaced, c.Skipped)
+		}
+	})
+}
+
+// expectDelim reads the next token from dec, failing unless it is delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("reading export: %w", err)
+	}
+	if token != delim {
+		return fmt.Errorf("reading export: expected %v, found %v", delim, token)
+	}
+	return nil
+}
+
+// importCollection reads a collection's array of documents from dec and
+// writes them to collection in batches. Documents whose _id is taken are
+// overwritten when replace is set and skipped otherwise.
+func importCollection(ctx context.Context, collection *mongo.Collection, dec *json.Decoder, replace bool) (collectionCount, error) {
+	count := collectionCount{Collection: collection.Name()}
+	if err := expectDelim(dec, '['); err != nil {
+		return count, err
+	}
+	batch := make([]bson.Raw, 0, importBatchSize)
+	for {
+		more := dec.More()
+		if more {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return count, fmt.Errorf("reading export: %w", err)
+			}
+			var doc bson.Raw
+			if err := bson.UnmarshalExtJSON(raw, true, &doc); err != nil {
+				return count, fmt.Errorf("reading document %d: %w", count.Documents+1, err)
+			}
+			batch = append(batch, doc)
+			count.Documents++
+		}
+		if len(batch) == importBatchSize || (!more && len(batch) > 0) {
+			if err := importBatch(ctx, collection, batch, replace, &count); err != nil {
+				return count, err
+			}
+			batch = batch[:0]
+		}
+		if !more {
+			break
+		}
+	}
+	return count, expectDelim(dec, ']')
+}
+
+// importBatch writes docs with one bulk write and adds the outcome to count.
+func importBatch(ctx context.Context, collection *mongo.Collection, docs []bson.Raw, replace bool, count *collectionCount) error {
+	writes := make([]mongo.WriteModel, 0, len(docs))
+	for _, doc := range docs {
+		if replace {
+			filter := bson.M{"_id": doc.Lookup("_id")}
+			writes = append(writes, mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(doc).SetUpsert(true))
+		} else {
+			writes = append(writes, mongo.NewInsertOneModel().SetDocument(doc))
+		}
+	}
+
+	result, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	switch {
+	case errors.As(err, &bulkErr) && onlyDuplicateKeys(bulkErr):
+		count.Skipped += len(bulkErr.WriteErrors)
+	case err != nil:
+		return err
+	}
+	if result != nil {
+		count.Inserted += int(result.InsertedCount + result.UpsertedCount)
+		count.Replaced += int(result.MatchedCount)
+	}
+	return nil
+}
+
+// onlyDuplicateKeys reports whether every write in err failed only because
+// its _id, or another unique key, was already taken.
+func onlyDuplicateKeys(err mongo.BulkWriteException) bool {
+	if err.WriteConcernError != nil || len(err.WriteErrors) == 0 {
+		return false
+	}
+	for _, writeErr := range err.WriteErrors {
+		if !writeErr.HasErrorCode(11000) {
+			return false
+		}
+	}
+	return true
+}
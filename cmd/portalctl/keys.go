@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"admission-portal-backend/internal/auth"
+)
+
+var keysCommand = command{
+	usage: "keys list [-dir DIR]\n" +
+		"keys generate [-dir DIR] [-alg EdDSA|RS256] [KID]\n" +
+		"keys retire [-dir DIR] KID",
+	summary: "manage the JWT signing keys in JWT_KEYS_DIR: add a key, or keep only the public half of one being rotated out",
+	run:     runKeys,
+}
+
+// keyView is how keys are printed.
+type keyView struct {
+	ID        string `json:"kid"`
+	Algorithm string `json:"alg"`
+	// Private is false for retired keys, which only verify.
+	Private bool   `json:"private"`
+	Signing bool   `json:"signing"`
+	Path    string `json:"path,omitempty"`
+}
+
+func runKeys(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	flags := flag.NewFlagSet("keys "+args[0], flag.ContinueOnError)
+	defaultDir := os.Getenv("JWT_KEYS_DIR")
+	if defaultDir == "" {
+		defaultDir = "keys"
+	}
+	dir := flags.String("dir", defaultDir, "the key directory")
+
+	switch args[0] {
+	case "list":
+		if err := parseFlags(flags, args[1:]); err != nil || flags.NArg() > 0 {
+			return errUsage
+		}
+		return listKeys(a, *dir)
+	case "generate":
+		alg := flags.String("alg", auth.AlgEdDSA, "EdDSA (Ed25519) or RS256 (RSA 3072)")
+		if err := parseFlags(flags, args[1:]); err != nil || flags.NArg() > 1 {
+			return errUsage
+		}
+		// Date-based IDs sort in the order keys were made
+		kid := time.Now().UTC().Format("20060102-150405")
+		if flags.NArg() == 1 {
+			kid = flags.Arg(0)
+		}
+		return generateKey(a, *dir, kid, *alg)
+	case "retire":
+		if err := parseFlags(flags, args[1:]); err != nil || flags.NArg() != 1 {
+			return errUsage
+		}
+		return retireKey(a, *dir, flags.Arg(0))
+	}
+	return errUsage
+}
+
+func listKeys(a *app, dir string) error {
+	keys, err := auth.LoadKeyDir(dir)
+	if err != nil {
+		return err
+	}
+	signing := os.Getenv("JWT_SIGNING_KID")
+	views := make([]keyView, 0, len(keys))
+	for _, key := range keys {
+		private := key.Private != nil
+		views = append(views, keyView{
+			ID:        key.ID,
+			Algorithm: key.Algorithm,
+			Private:   private,
+			Signing:   key.ID == signing || (signing == "" && private && countPrivate(keys) == 1),
+			Path:      keyPath(dir, key.ID),
+		})
+	}
+	return a.emit(map[string]any{"dir": dir, "keys": views}, func(w io.Writer) {
+		if len(views) == 0 {
+			fmt.Fprintf(w, "No keys in %s\n", dir)
+			return
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "KID\tALG\tUSE")
+		for _, v := range views {
+			use := "verify only"
+			switch {
+			case v.Signing:
+				use = "signing"
+			case v.Private:
+				use = "verify (can sign)"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", v.ID, v.Algorithm, use)
+		}
+		tw.Flush()
+	})
+}
+
+func countPrivate(keys []*auth.Key) int {
+	n := 0
+	for _, key := range keys {
+		if key.Private != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func keyPath(dir, kid string) string {
+	return filepath.Join(dir, kid+".pem")
+}
+
+func generateKey(a *app, dir, kid, alg string) error {
+	if kid == "" || kid != filepath.Base(kid) || filepath.Ext(kid) != "" {
+		return fmt.Errorf("invalid key ID %q, use letters, digits and dashes", kid)
+	}
+	key, err := auth.GenerateKey(kid, alg)
+	if err != nil {
+		return err
+	}
+	data, err := key.PrivatePEM()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	path := keyPath(dir, kid)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("a key with ID %s already exists", kid)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	view := keyView{ID: kid, Algorithm: key.Algorithm, Private: true, Path: path}
+	return a.emit(view, func(w io.Writer) {
+		fmt.Fprintf(w, "Generated %s key %s in %s\n", view.Algorithm, kid, path)
+		fmt.Fprintf(w, "Set JWT_SIGNING_KID=%s and restart every server to sign with it\n", kid)
+	})
+}
+
+func retireKey(a *app, dir, kid string) error {
+	if kid == os.Getenv("JWT_SIGNING_KID") {
+		return fmt.Errorf("%s is the signing key; sign with another key before retiring it", kid)
+	}
+	path := keyPath(dir, kid)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	key, err := auth.ParseKeyPEM(kid, data)
+	if err != nil {
+		return err
+	}
+	public, err := key.PublicPEM()
+	if err != nil {
+		return err
+	}
+	// Replace the file in one step, so the key never disappears from the
+	// keyring part way through
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, public, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	view := keyView{ID: kid, Algorithm: key.Algorithm, Path: path}
+	return a.emit(view, func(w io.Writer) {
+		fmt.Fprintf(w, "Retired %s: %s now holds only its public key, which still verifies tokens it signed\n", kid, path)
+		fmt.Fprintf(w, "Delete the file once those tokens have expired (invites last up to 72 hours)\n")
+	})
+}
@@ -0,0 +1,171 @@
+// Command portalctl runs operational tasks against the portal's database:
+// managing users, migrating, seeding, exporting and importing data, and
+// rotating secrets. It reads the same environment and .env file as the
+// server and talks to MongoDB directly.
+//
+//	portalctl [-json] <command> [arguments]
+//
+// Output is meant for people unless -json is given, in which case each
+// command prints one JSON document. Changes to records are written to the
+// audit log with the operator actor role.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/config"
+	"admission-portal-backend/internal/repositories"
+)
+
+// command is one portalctl subcommand.
+type command struct {
+	// usage shows how to call the command, one form per line.
+	usage   string
+	summary string
+	run     func(ctx context.Context, app *app, args []string) error
+}
+
+var commands = map[string]command{
+	"user":    userCommand,
+	"migrate": migrateCommand,
+	"seed":    seedCommand,
+	"export":  exportCommand,
+	"import":  importCommand,
+	"secrets": secretsCommand,
+	"keys":    keysCommand,
+}
+
+// errUsage is returned by commands called with the wrong arguments.
+var errUsage = errors.New("usage")
+
+// app is what commands share: where output goes and how it is formatted,
+// and the database once connected.
+type app struct {
+	json  bool
+	out   io.Writer
+	db    *mongo.Database
+	store *repositories.Store
+}
+
+// database connects to MongoDB the way the server does, on first use.
+func (a *app) database() *mongo.Database {
+	if a.db == nil {
+		config.ConnectDB()
+		a.db = config.DB
+	}
+	return a.db
+}
+
+// repositories returns the repositories the server uses, over the database.
+func (a *app) repositories() *repositories.Store {
+	if a.store == nil {
+		a.store = repositories.NewMongoStore(a.database())
+	}
+	return a.store
+}
+
+// audit records a change made from the command line. A failure is reported
+// but does not undo the change.
+func (a *app) audit(ctx context.Context, action, targetType, targetID string, before, after any) {
+	entry := audit.Entry{
+		ActorRole:  audit.ActorOperator,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     before,
+		After:      after,
+	}
+	if err := audit.NewRecorder(a.repositories().Audit, a.repositories().AuditQueue).Record(ctx, entry); err != nil {
+		fmt.Fprintf(os.Stderr, "portalctl: recording audit event %s on %s %s: %v\n", action, targetType, targetID, err)
+	}
+}
+
+// emit prints v as JSON with -json, and through human otherwise.
+func (a *app) emit(v any, human func(w io.Writer)) error {
+	if a.json {
+		encoder := json.NewEncoder(a.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+	human(a.out)
+	return nil
+}
+
+func main() {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "portalctl: reading .env:", err)
+	}
+
+	flags := flag.NewFlagSet("portalctl", flag.ExitOnError)
+	jsonOutput := flags.Bool("json", false, "print JSON instead of text")
+	flags.Usage = func() { usage(flags.Output()) }
+	flags.Parse(os.Args[1:])
+	if flags.NArg() == 0 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	name := flags.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "portalctl: unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	a := &app{json: *jsonOutput, out: os.Stdout}
+	err := cmd.run(context.Background(), a, flags.Args()[1:])
+	switch {
+	case errors.Is(err, errUsage):
+		fmt.Fprintln(os.Stderr, "usage:")
+		printUsage(os.Stderr, cmd)
+		os.Exit(2)
+	case err != nil:
+		if a.json {
+			json.NewEncoder(os.Stderr).Encode(map[string]string{"error": err.Error()})
+		} else {
+			fmt.Fprintln(os.Stderr, "portalctl:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: portalctl [-json] <command> [arguments]")
+	fmt.Fprintln(w, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		printUsage(w, commands[name])
+		fmt.Fprintf(w, "      %s\n", commands[name].summary)
+	}
+}
+
+func printUsage(w io.Writer, cmd command) {
+	for _, form := range strings.Split(cmd.usage, "\n") {
+		fmt.Fprintf(w, "  portalctl %s\n", form)
+	}
+}
+
+// parseFlags parses a command's flags, reporting errUsage if they are wrong.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
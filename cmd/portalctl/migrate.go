@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"admission-portal-backend/internal/migrations"
+)
+
+var migrateCommand = command{
+	usage:   "migrate status | up | down [steps]",
+	summary: "show, apply or roll back database migrations",
+	run:     runMigrate,
+}
+
+// migrationView is how applied or rolled back migrations are printed.
+type migrationView struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+}
+
+func runMigrate(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 || len(args) > 2 || (len(args) == 2 && args[0] != "down") {
+		return errUsage
+	}
+	steps := 1
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return errUsage
+		}
+		steps = n
+	}
+
+	switch args[0] {
+	case "status":
+		statuses, err := migrations.NewRunner(a.database()).Status(ctx)
+		if err != nil {
+			return err
+		}
+		return a.emit(map[string]any{"migrations": statuses}, func(w io.Writer) {
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED")
+			for _, s := range statuses {
+				applied := "pending"
+				if s.AppliedAt != nil {
+					applied = s.AppliedAt.Local().Format(time.RFC3339)
+				}
+				if s.Unknown {
+					applied += " (unknown to this build)"
+				}
+				fmt.Fprintf(tw, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+			}
+			tw.Flush()
+		})
+
+	case "up":
+		done, err := migrations.NewRunner(a.database()).Up(ctx)
+		if emitErr := emitMigrations(a, "applied", "Applied", done); emitErr != nil {
+			return emitErr
+		}
+		return err
+
+	case "down":
+		done, err := migrations.NewRunner(a.database()).Down(ctx, steps)
+		if emitErr := emitMigrations(a, "rolledBack", "Rolled back", done); emitErr != nil {
+			return emitErr
+		}
+		return err
+	}
+	return errUsage
+}
+
+// emitMigrations reports the migrations an up or down run got through,
+// including those done before it failed.
+func emitMigrations(a *app, key, verb string, done []migrations.Migration) error {
+	views := make([]migrationView, 0, len(done))
+	for _, m := range done {
+		views = append(views, migrationView{Version: m.Version, Name: m.Name})
+	}
+	return a.emit(map[string]any{key: views}, func(w io.Writer) {
+		if len(views) == 0 {
+			fmt.Fprintln(w, "Nothing to do")
+		}
+		for _, v := range views {
+			fmt.Fprintf(w, "%s %d %s\n", verb, v.Version, v.Name)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+var secretsCommand = command{
+	usage:   "secrets rotate [-env-file FILE] NAME...",
+	summary: "replace secrets in the env file with new random values; restart the server to use them",
+	run:     runSecrets,
+}
+
+// rotatableSecrets are the secrets portalctl can rotate, with what changes
+// for clients once the server restarts with a new value.
+var rotatableSecrets = map[string]string{
+	"PAYMENT_WEBHOOK_SECRET": "update the payment gateway, or its callbacks will be refused",
+}
+
+// rotatedSecret reports one rotated secret, without its value.
+type rotatedSecret struct {
+	Name   string `json:"name"`
+	Effect string `json:"effect"`
+}
+
+func runSecrets(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 || args[0] != "rotate" {
+		return errUsage
+	}
+	flags := flag.NewFlagSet("secrets rotate", flag.ContinueOnError)
+	envFile := flags.String("env-file", ".env", "the env file to update")
+	if err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errUsage
+	}
+	for _, name := range flags.Args() {
+		if _, ok := rotatableSecrets[name]; !ok {
+			return fmt.Errorf("cannot rotate %s, expected PAYMENT_WEBHOOK_SECRET; JWT signing keys are rotated with portalctl keys", name)
+		}
+	}
+
+	values := map[string]string{}
+	for _, name := range flags.Args() {
+		value, err := randomSecret(32)
+		if err != nil {
+			return err
+		}
+		values[name] = value
+	}
+	if err := updateEnvFile(*envFile, values); err != nil {
+		return err
+	}
+
+	rotated := make([]rotatedSecret, 0, len(flags.Args()))
+	for _, name := range flags.Args() {
+		rotated = append(rotated, rotatedSecret{Name: name, Effect: rotatableSecrets[name]})
+	}
+	return a.emit(map[string]any{"envFile": *envFile, "rotated": rotated}, func(w io.Writer) {
+		for _, r := range rotated {
+			fmt.Fprintf(w, "Rotated %s in %s: %s\n", r.Name, *envFile, r.Effect)
+		}
+		fmt.Fprintln(w, "Restart the server to use the new values")
+	})
+}
+
+// updateEnvFile sets values in the env file at path, replacing the lines
+// that assign them and appending the rest. Other lines are kept as they
+// are. The file is created if it does not exist.
+func updateEnvFile(path string, values map[string]string) error {
+	mode := os.FileMode(0o600)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+	case os.IsNotExist(err):
+	default:
+		return err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	written := map[string]bool{}
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		prefix := ""
+		if strings.HasPrefix(trimmed, "export ") {
+			prefix, trimmed = "export ", strings.TrimPrefix(trimmed, "export ")
+		}
+		name, _, ok := strings.Cut(trimmed, "=")
+		name = strings.TrimSpace(name)
+		if value, rotating := values[name]; ok && rotating {
+			lines[i] = prefix + name + "=" + value
+			written[name] = true
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !written[name] {
+			lines = append(lines, name+"="+values[name])
+		}
+	}
+
+	// Write beside the file and rename, so a crash cannot leave it half written
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(lines, "\n")+"\n"), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// randomSecret returns n random bytes, URL-safe base64 encoded.
+func randomSecret(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
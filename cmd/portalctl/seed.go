@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+var seedCommand = command{
+	usage:   "seed courses",
+	summary: "add demo courses, skipping any whose name is already taken",
+	run:     runSeed,
+}
+
+// demoCourses are the courses seed adds, enough to try the application
+// flow end to end.
+var demoCourses = []models.Course{
+	{
+		Name:        "B.Sc. Computer Science",
+		Description: "Programming, algorithms, systems and mathematics for computing.",
+		Duration:    "3 years",
+		Seats:       60,
+		EligibilityCriteria: models.EligibilityCriteria{
+			MinimumPercentage: 60,
+			RequiredSubjects:  []string{"Mathematics"},
+		},
+		Fees: models.Fees{TuitionFee: 90000, AdmissionFee: 1000, OtherFees: 5000},
+	},
+	{
+		Name:        "B.Com.",
+		Description: "Accounting, finance, economics and business law.",
+		Duration:    "3 years",
+		Seats:       120,
+		EligibilityCriteria: models.EligibilityCriteria{
+			MinimumPercentage: 50,
+		},
+		Fees: models.Fees{TuitionFee: 45000, AdmissionFee: 500, OtherFees: 2500},
+	},
+	{
+		Name:        "B.Tech. Mechanical Engineering",
+		Description: "Mechanics, thermodynamics, design and manufacturing.",
+		Duration:    "4 years",
+		Seats:       40,
+		EligibilityCriteria: models.EligibilityCriteria{
+			MinimumPercentage: 70,
+			RequiredSubjects:  []string{"Physics", "Mathematics"},
+			EntranceExam:      true,
+		},
+		Fees: models.Fees{TuitionFee: 150000, AdmissionFee: 1500, OtherFees: 10000},
+	},
+	{
+		Name:        "B.A. English Literature",
+		Description: "Literature in English from its beginnings to today.",
+		Duration:    "3 years",
+		Seats:       50,
+		EligibilityCriteria: models.EligibilityCriteria{
+			MinimumPercentage: 50,
+			RequiredSubjects:  []string{"English"},
+			Enforcement:       "flag",
+		},
+		Fees: models.Fees{TuitionFee: 30000, AdmissionFee: 500},
+	},
+}
+
+// seededCourse reports what seed did with one demo course.
+type seededCourse struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Created bool   `json:"created"`
+}
+
+func runSeed(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 || args[0] != "courses" {
+		return errUsage
+	}
+	courses := a.repositories().Courses
+
+	results := make([]seededCourse, 0, len(demoCourses))
+	for _, demo := range demoCourses {
+		existing, err := courses.List(ctx, query.Options{Limit: 1}.Where("name", query.OpEq, demo.Name))
+		if err != nil {
+			return err
+		}
+		if len(existing.Items) > 0 {
+			results = append(results, seededCourse{ID: existing.Items[0].ID.Hex(), Name: demo.Name})
+			continue
+		}
+
+		course := demo
+		course.CreatedAt = time.Now()
+		course.UpdatedAt = course.CreatedAt
+		if err := courses.Create(ctx, &course); err != nil {
+			return fmt.Errorf("creating course %s: %w", course.Name, err)
+		}
+		a.audit(ctx, audit.ActionCourseCreate, audit.TargetCourse, course.ID.Hex(), nil, course)
+		results = append(results, seededCourse{ID: course.ID.Hex(), Name: course.Name, Created: true})
+	}
+
+	return a.emit(map[string]any{"courses": results}, func(w io.Writer) {
+		for _, r := range results {
+			if r.Created {
+				fmt.Fprintf(w, "Created %s (%s)\n", r.Name, r.ID)
+			} else {
+				fmt.Fprintf(w, "Skipped %s, it already exists (%s)\n", r.Name, r.ID)
+			}
+		}
+	})
+}
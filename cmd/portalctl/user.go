@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/repositories"
+)
+
+var userCommand = command{
+	usage: "user create -email EMAIL -name NAME [-role ROLE] [-password PASSWORD | -password-stdin]\n" +
+		"user promote EMAIL ROLE\n" +
+		"user reset-password EMAIL [-password PASSWORD | -password-stdin]",
+	summary: "create accounts, change their role or set a new password; without a password one is generated and printed",
+	run:     runUser,
+}
+
+// minPasswordLength matches what the API accepts at signup.
+const minPasswordLength = 6
+
+// userView is how accounts are printed. Password is only set when
+// portalctl generated it.
+type userView struct {
+	ID       string `json:"id"`
+	Email    string `json:"email"`
+	Name     string `json:"name,omitempty"`
+	Role     string `json:"role"`
+	Password string `json:"password,omitempty"`
+}
+
+func runUser(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "create":
+		return createUser(ctx, a, args[1:])
+	case "promote":
+		return promoteUser(ctx, a, args[1:])
+	case "reset-password":
+		return resetPassword(ctx, a, args[1:])
+	}
+	return errUsage
+}
+
+// passwordFlags adds the flags for choosing a password to flags. The
+// returned function settles on the password after parsing, generating one
+// when none was given.
+func passwordFlags(flags *flag.FlagSet) func() (password string, generated bool, err error) {
+	given := flags.String("password", "", "the new password")
+	fromStdin := flags.Bool("password-stdin", false, "read the new password from the first line of standard input")
+	return func() (string, bool, error) {
+		password := *given
+		switch {
+		case *fromStdin && password != "":
+			return "", false, errUsage
+		case *fromStdin:
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				return "", false, err
+			}
+			password = strings.TrimRight(line, "\r\n")
+		case password == "":
+			generated, err := randomSecret(12)
+			return generated, true, err
+		}
+		if len(password) < minPasswordLength {
+			return "", false, fmt.Errorf("password must be at least %d characters", minPasswordLength)
+		}
+		return password, false, nil
+	}
+}
+
+func createUser(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("user create", flag.ContinueOnError)
+	email := flags.String("email", "", "the account's email address")
+	name := flags.String("name", "", "the account holder's name")
+	role := flags.String("role", rbac.RoleStudent, "one of "+strings.Join(rbac.Roles(), ", "))
+	password := passwordFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 || *email == "" || *name == "" {
+		return errUsage
+	}
+	if _, err := mail.ParseAddress(*email); err != nil {
+		return fmt.Errorf("invalid email %q", *email)
+	}
+	if !rbac.IsValid(*role) {
+		return fmt.Errorf("unknown role %q, expected one of %s", *role, strings.Join(rbac.Roles(), ", "))
+	}
+	plain, generated, err := password()
+	if err != nil {
+		return err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user := &models.Student{
+		Email:    *email,
+		Password: string(hashed),
+		Name:     *name,
+		Role:     rbac.Normalize(*role),
+		// The operator vouches for the address
+		EmailVerified: true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := a.repositories().Students.Create(ctx, user); err != nil {
+		if errors.Is(err, repositories.ErrDuplicate) {
+			return fmt.Errorf("an account with email %s already exists", *email)
+		}
+		return err
+	}
+	action := audit.ActionUserCreate
+	if rbac.IsStaff(user.Role) {
+		action = audit.ActionAdminCreate
+	}
+	logged := *user
+	logged.Password = ""
+	a.audit(ctx, action, audit.TargetUser, user.ID.Hex(), nil, logged)
+
+	view := userView{ID: user.ID.Hex(), Email: user.Email, Name: user.Name, Role: user.Role}
+	if generated {
+		view.Password = plain
+	}
+	return a.emit(view, func(w io.Writer) {
+		fmt.Fprintf(w, "Created %s %s (%s)\n", view.Role, view.Email, view.ID)
+		if generated {
+			fmt.Fprintf(w, "Password: %s\n", plain)
+		}
+	})
+}
+
+func promoteUser(ctx context.Context, a *app, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	email, role := args[0], args[1]
+	if !rbac.IsValid(role) {
+		return fmt.Errorf("unknown role %q, expected one of %s", role, strings.Join(rbac.Roles(), ", "))
+	}
+	role = rbac.Normalize(role)
+	user, err := findUser(ctx, a, email)
+	if err != nil {
+		return err
+	}
+
+	previous := user.Role
+	if rbac.Normalize(previous) != role {
+		user.Role = role
+		user.UpdatedAt = time.Now()
+		if err := a.repositories().Students.Update(ctx, user); err != nil {
+			return err
+		}
+		// As with the API, the new role applies from the next login
+		if err := a.repositories().Sessions.RevokeAllForUser(ctx, user.ID, "role_changed"); err != nil {
+			log.Printf("Error revoking sessions after role change for %s: %v", user.ID.Hex(), err)
+		}
+		action := audit.ActionRoleAssign
+		if role == rbac.RoleStudent {
+			action = audit.ActionRoleRevoke
+		}
+		a.audit(ctx, action, audit.TargetUser, user.ID.Hex(), map[string]string{"role": previous}, map[string]string{"role": role})
+	}
+
+	view := userView{ID: user.ID.Hex(), Email: user.Email, Name: user.Name, Role: role}
+	return a.emit(view, func(w io.Writer) {
+		if rbac.Normalize(previous) == role {
+			fmt.Fprintf(w, "%s is already %s\n", view.Email, role)
+			return
+		}
+		fmt.Fprintf(w, "%s is now %s (was %s); their sessions were ended\n", view.Email, role, previous)
+	})
+}
+
+func resetPassword(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return errUsage
+	}
+	email := args[0]
+	flags := flag.NewFlagSet("user reset-password", flag.ContinueOnError)
+	password := passwordFlags(flags)
+	if err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return errUsage
+	}
+	user, err := findUser(ctx, a, email)
+	if err != nil {
+		return err
+	}
+	plain, generated, err := password()
+	if err != nil {
+		return err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user.Password = string(hashed)
+	user.UpdatedAt = time.Now()
+	if err := a.repositories().Students.Update(ctx, user); err != nil {
+		return err
+	}
+	// Sign the account out everywhere and void any reset links in flight
+	if err := a.repositories().Sessions.RevokeAllForUser(ctx, user.ID, "password_reset"); err != nil {
+		log.Printf("Error revoking sessions after password reset for %s: %v", user.ID.Hex(), err)
+	}
+	if err := a.repositories().Tokens.InvalidateForUser(ctx, user.ID, models.TokenPurposePasswordReset); err != nil {
+		log.Printf("Error invalidating reset tokens for %s: %v", user.ID.Hex(), err)
+	}
+	a.audit(ctx, audit.ActionPasswordReset, audit.TargetUser, user.ID.Hex(), nil, nil)
+
+	view := userView{ID: user.ID.Hex(), Email: user.Email, Name: user.Name, Role: user.Role}
+	if generated {
+		view.Password = plain
+	}
+	return a.emit(view, func(w io.Writer) {
+		fmt.Fprintf(w, "Reset the password of %s; their sessions were ended\n", view.Email)
+		if generated {
+			fmt.Fprintf(w, "Password: %s\n", plain)
+		}
+	})
+}
+
+// findUser loads the account with email, with an error fit to print when
+// there is none.
+func findUser(ctx context.Context, a *app, email string) (*models.Student, error) {
+	user, err := a.repositories().Students.FindByEmail(ctx, email)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("no account with email %s", email)
+	}
+	return user, err
+}
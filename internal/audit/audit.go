@@ -0,0 +1,301 @@
+// Package audit keeps the tamper-evident log of privileged writes. Each
+// event carries a field-level diff of the record it touched and is chained
+// to the previous event by hash; Verify walks the chain to detect edits,
+// deletions and reordering.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/repositories"
+)
+
+// Actions recorded in the audit log.
+const (
+	ActionCourseCreate        = "course.create"
+	ActionCourseUpdate        = "course.update"
+	ActionCourseDelete        = "course.delete"
+	ActionAdminCreate         = "admin.create"
+	ActionAdmissionTransition = "admission.transition"
+	ActionRoleAssign          = "role.assign"
+	ActionRoleRevoke          = "role.revoke"
+	ActionMeritListPublish    = "merit_list.publish"
+	ActionCycleCreate         = "cycle.create"
+	ActionCycleUpdate         = "cycle.update"
+	ActionPaymentRefund       = "payment.refund"
+	ActionWaitlistPromote     = "waitlist.promote"
+	ActionUserCreate          = "user.create"
+	ActionPasswordReset       = "user.password_reset"
+	ActionDataImport          = "data.import"
+	ActionInviteCreate        = "invite.create"
+	ActionInviteRevoke        = "invite.revoke"
+	ActionInviteAccept        = "invite.accept"
+)
+
+// Target types recorded in the audit log.
+const (
+	TargetCourse    = "course"
+	TargetAdmission = "admission"
+	TargetUser      = "user"
+	TargetMeritList = "merit_list"
+	TargetCycle     = "cycle"
+	TargetPayment   = "payment"
+	TargetInvite    = "invite"
+	// TargetCollection is for changes to a whole collection, named by the
+	// event's target ID.
+	TargetCollection = "collection"
+)
+
+// ActorOperator is the actor role recorded for changes made on the server
+// with portalctl rather than through the API.
+const ActorOperator = "operator"
+
+// ErrContention is returned when an event could not claim the next sequence
+// number because other writers kept taking it first.
+var ErrContention = errors.New("audit log is busy, event not recorded")
+
+// maxAppendAttempts bounds the retries when concurrent writers race for the
+// next place in the chain.
+const maxAppendAttempts = 5
+
+// verifyBatchSize is how many events Verify reads at a time.
+const verifyBatchSize = 500
+
+// flushBatchSize is how many queued events Flush reads at a time.
+const flushBatchSize = 100
+
+// Entry describes a write to record. Before and After are the record's state
+// either side of the write; either may be nil for creations and deletions.
+type Entry struct {
+	ActorID    primitive.ObjectID
+	ActorRole  string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     any
+	After      any
+	IP         string
+	RequestID  string
+}
+
+// Recorder appends events to the log. Events that cannot be appended right
+// away are put on Queue, when set, and appended later by Flush.
+type Recorder struct {
+	Events repositories.AuditRepository
+	Queue  repositories.AuditQueueRepository
+}
+
+func NewRecorder(events repositories.AuditRepository, queue repositories.AuditQueueRepository) *Recorder {
+	return &Recorder{Events: events, Queue: queue}
+}
+
+// Record appends entry to the log as the next link in the chain. If the
+// chain cannot take it, the event is queued instead and Record still
+// succeeds; it only fails when the event could be neither appended nor
+// queued.
+func (r *Recorder) Record(ctx context.Context, entry Entry) error {
+	changes, err := Diff(entry.Before, entry.After)
+	if err != nil {
+		return err
+	}
+	event := models.AuditEvent{
+		ActorID:    entry.ActorID,
+		ActorRole:  entry.ActorRole,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Changes:    changes,
+		IP:         entry.IP,
+		RequestID:  entry.RequestID,
+		// Stored times only keep milliseconds
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	err = r.append(ctx, event)
+	if err == nil || r.Queue == nil {
+		return err
+	}
+	if queueErr := r.Queue.Add(ctx, &models.PendingAuditEvent{Event: event, CreatedAt: time.Now()}); queueErr != nil {
+		return fmt.Errorf("%w; queueing the event failed too: %v", err, queueErr)
+	}
+	log.Printf("Queued audit event %s on %s %s: %v", event.Action, event.TargetType, event.TargetID, err)
+	return nil
+}
+
+// append adds event to the end of the chain, retrying when another writer
+// takes the next sequence number first.
+func (r *Recorder) append(ctx context.Context, event models.AuditEvent) error {
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		event.Sequence, event.PrevHash = 1, ""
+		last, err := r.Events.Last(ctx)
+		switch {
+		case err == nil:
+			event.Sequence = last.Sequence + 1
+			event.PrevHash = last.Hash
+		case !errors.Is(err, repositories.ErrNotFound):
+			return err
+		}
+		event.Hash = Hash(&event)
+
+		err = r.Events.Append(ctx, &event)
+		if !errors.Is(err, repositories.ErrDuplicate) {
+			return err
+		}
+	}
+	return ErrContention
+}
+
+// Flush appends queued events to the chain, oldest first, and returns how
+// many it appended. It stops at the first event that still cannot be
+// appended. An event is removed from the queue only after it is in the
+// log, so one appended just before the process stops may be appended
+// twice, but none is lost.
+func (r *Recorder) Flush(ctx context.Context) (int, error) {
+	if r.Queue == nil {
+		return 0, nil
+	}
+	flushed := 0
+	for {
+		pending, err := r.Queue.Oldest(ctx, flushBatchSize)
+		if err != nil {
+			return flushed, err
+		}
+		for _, p := range pending {
+			if err := r.append(ctx, p.Event); err != nil {
+				return flushed, err
+			}
+			if err := r.Queue.Remove(ctx, p.ID); err != nil {
+				return flushed, err
+			}
+			flushed++
+		}
+		if len(pending) < flushBatchSize {
+			return flushed, nil
+		}
+	}
+}
+
+// RunQueue flushes queued events every interval until ctx is done.
+func (r *Recorder) RunQueue(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := r.Flush(ctx); err != nil {
+				log.Printf("Error appending queued audit events: %v", err)
+			} else if n > 0 {
+				log.Printf("Appended %d queued audit events", n)
+			}
+		}
+	}
+}
+
+// Hash returns the chain hash of event: SHA-256 over its contents and
+// PrevHash. The Hash field itself is ignored.
+func Hash(event *models.AuditEvent) string {
+	actorID := ""
+	if !event.ActorID.IsZero() {
+		actorID = event.ActorID.Hex()
+	}
+	changes := event.Changes
+	if changes == nil {
+		changes = []models.AuditChange{}
+	}
+	// Field order is fixed by the struct, and change values are only ever
+	// strings, float64s, bools or nil, so the encoding is stable across a
+	// round trip through storage.
+	canonical, _ := json.Marshal(struct {
+		Sequence   int64                `json:"sequence"`
+		PrevHash   string               `json:"prevHash"`
+		ActorID    string               `json:"actorId"`
+		ActorRole  string               `json:"actorRole"`
+		Action     string               `json:"action"`
+		TargetType string               `json:"targetType"`
+		TargetID   string               `json:"targetId"`
+		Changes    []models.AuditChange `json:"changes"`
+		IP         string               `json:"ip"`
+		RequestID  string               `json:"requestId"`
+		CreatedAt  int64                `json:"createdAt"`
+	}{
+		Sequence:   event.Sequence,
+		PrevHash:   event.PrevHash,
+		ActorID:    actorID,
+		ActorRole:  event.ActorRole,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Changes:    changes,
+		IP:         event.IP,
+		RequestID:  event.RequestID,
+		CreatedAt:  event.CreatedAt.UnixMilli(),
+	})
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// Verification is the outcome of checking the chain.
+type Verification struct {
+	Valid   bool  `json:"valid"`
+	Checked int64 `json:"checked"`
+	// LastHash is the hash at the head of the chain. Keeping a copy outside
+	// the database makes removal of the newest events detectable too.
+	LastHash string `json:"lastHash,omitempty"`
+	// BrokenAt is the sequence number of the first event that fails.
+	BrokenAt int64  `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Verify walks the whole chain in order, checking that sequence numbers have
+// no gaps, that each event links to the one before it and that each event's
+// hash matches its contents.
+func (r *Recorder) Verify(ctx context.Context) (*Verification, error) {
+	result := &Verification{Valid: true}
+	next := int64(1)
+	for {
+		opts := query.Options{Sort: []query.Sort{{Field: "_id"}}, Limit: verifyBatchSize}
+		page, err := r.Events.List(ctx, opts.Where("_id", query.OpGte, next))
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range page.Items {
+			event := &page.Items[i]
+			reason := ""
+			switch {
+			case event.Sequence != next:
+				reason = "event is missing"
+			case event.PrevHash != result.LastHash:
+				reason = "event does not link to the previous event"
+			case Hash(event) != event.Hash:
+				reason = "event contents do not match its hash"
+			}
+			if reason != "" {
+				result.Valid = false
+				result.BrokenAt = next
+				result.Reason = reason
+				return result, nil
+			}
+			result.Checked++
+			result.LastHash = event.Hash
+			next++
+		}
+
+		if len(page.Items) < verifyBatchSize {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/repositories"
+)
+
+// recordEvents appends n course updates to a fresh in-memory log and
+// returns the stored events in order.
+func recordEvents(t *testing.T, n int) []models.AuditEvent {
+	t.Helper()
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	recorder := NewRecorder(store.Audit, store.AuditQueue)
+	for i := 0; i < n; i++ {
+		err := recorder.Record(ctx, Entry{
+			ActorRole:  "super_admin",
+			Action:     ActionCourseUpdate,
+			TargetType: TargetCourse,
+			TargetID:   "course",
+			Before:     map[string]any{"seats": i},
+			After:      map[string]any{"seats": i + 1},
+		})
+		if err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	page, err := store.Audit.List(ctx, query.Options{Sort: []query.Sort{{Field: "_id"}}})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	return page.Items
+}
+
+// fixedLog serves a set of events as stored, so tests can tamper with them.
+type fixedLog struct {
+	repositories.AuditRepository
+	events []models.AuditEvent
+}
+
+func (l *fixedLog) List(ctx context.Context, opts query.Options) (*query.Page[models.AuditEvent], error) {
+	from := opts.Conditions[0].Value.(int64)
+	items := []models.AuditEvent{}
+	for _, event := range l.events {
+		if event.Sequence >= from {
+			items = append(items, event)
+		}
+	}
+	return query.NewPage(items, int64(len(items)), opts), nil
+}
+
+func TestRecordChainsEvents(t *testing.T) {
+	events := recordEvents(t, 3)
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	for i, event := range events {
+		if event.Sequence != int64(i+1) {
+			t.Errorf("event %d has sequence %d", i, event.Sequence)
+		}
+		if event.Hash != Hash(&event) {
+			t.Errorf("event %d hash does not match its contents after storage", i)
+		}
+		if i > 0 && event.PrevHash != events[i-1].Hash {
+			t.Errorf("event %d does not link to event %d", i, i-1)
+		}
+	}
+	if events[0].PrevHash != "" {
+		t.Errorf("first event links to %q", events[0].PrevHash)
+	}
+}
+
+// busyLog loses every race for the next sequence number while busy is set.
+type busyLog struct {
+	repositories.AuditRepository
+	busy bool
+}
+
+func (l *busyLog) Append(ctx context.Context, event *models.AuditEvent) error {
+	if l.busy {
+		return repositories.ErrDuplicate
+	}
+	return l.AuditRepository.Append(ctx, event)
+}
+
+// brokenQueue cannot store anything.
+type brokenQueue struct {
+	repositories.AuditQueueRepository
+}
+
+func (brokenQueue) Add(ctx context.Context, pending *models.PendingAuditEvent) error {
+	return errors.New("connection reset")
+}
+
+func TestRecordQueuesEventsUnderContention(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	log := &busyLog{AuditRepository: store.Audit, busy: true}
+	recorder := NewRecorder(log, store.AuditQueue)
+	entry := Entry{ActorRole: "super_admin", Action: ActionCourseDelete, TargetType: TargetCourse, TargetID: "course"}
+
+	if err := recorder.Record(ctx, entry); err != nil {
+		t.Fatalf("Record while the log is busy = %v, want the event queued", err)
+	}
+	if _, err := store.Audit.Last(ctx); !errors.Is(err, repositories.ErrNotFound) {
+		t.Fatalf("event appended while the log was busy: %v", err)
+	}
+	if n, err := recorder.Flush(ctx); n != 0 || !errors.Is(err, ErrContention) {
+		t.Errorf("Flush while still busy = %d, %v, want 0 and ErrContention", n, err)
+	}
+
+	log.busy = false
+	if n, err := recorder.Flush(ctx); n != 1 || err != nil {
+		t.Fatalf("Flush = %d, %v, want 1 event appended", n, err)
+	}
+	last, err := store.Audit.Last(ctx)
+	if err != nil || last.Sequence != 1 || last.Action != ActionCourseDelete || last.Hash != Hash(last) {
+		t.Fatalf("appended event = %+v, %v", last, err)
+	}
+	if pending, _ := store.AuditQueue.Oldest(ctx, 10); len(pending) != 0 {
+		t.Errorf("%d events still queued after Flush", len(pending))
+	}
+	if n, err := recorder.Flush(ctx); n != 0 || err != nil {
+		t.Errorf("Flush of an empty queue = %d, %v", n, err)
+	}
+}
+
+func TestRecordFailsWhenEventCannotBeQueued(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	recorder := NewRecorder(&busyLog{AuditRepository: store.Audit, busy: true}, brokenQueue{})
+	err := recorder.Record(context.Background(), Entry{Action: ActionCourseDelete, TargetType: TargetCourse})
+	if !errors.Is(err, ErrContention) {
+		t.Errorf("Record = %v, want ErrContention", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func([]models.AuditEvent) []models.AuditEvent
+		valid    bool
+		brokenAt int64
+		reason   string
+	}{
+		{
+			name:   "untouched",
+			tamper: func(events []models.AuditEvent) []models.AuditEvent { return events },
+			valid:  true,
+		},
+		{
+			name: "edited action",
+			tamper: func(events []models.AuditEvent) []models.AuditEvent {
+				events[1].Action = ActionCourseDelete
+				return events
+			},
+			brokenAt: 2,
+			reason:   "event contents do not match its hash",
+		},
+		{
+			name: "edited change",
+			tamper: func(events []models.AuditEvent) []models.AuditEvent {
+				events[2].Changes[0].After = 99.0
+				return events
+			},
+			brokenAt: 3,
+			reason:   "event contents do not match its hash",
+		},
+		{
+			name: "deleted event",
+			tamper: func(events []models.AuditEvent) []models.AuditEvent {
+				return append(events[:1:1], events[2:]...)
+			},
+			brokenAt: 2,
+			reason:   "event is missing",
+		},
+		{
+			name: "rehashed edit",
+			tamper: func(events []models.AuditEvent) []models.AuditEvent {
+				events[0].TargetID = "other"
+				events[0].Hash = Hash(&events[0])
+				return events
+			},
+			brokenAt: 2,
+			reason:   "event does not link to the previous event",
+		},
+		{
+			name: "reordered",
+			tamper: func(events []models.AuditEvent) []models.AuditEvent {
+				events[1], events[2] = events[2], events[1]
+				events[1].Sequence, events[2].Sequence = 2, 3
+				return events
+			},
+			brokenAt: 2,
+			reason:   "event does not link to the previous event",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := recordEvents(t, 3)
+			recorder := NewRecorder(&fixedLog{events: tt.tamper(events)}, nil)
+			result, err := recorder.Verify(context.Background())
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if result.Valid != tt.valid || result.BrokenAt != tt.brokenAt || result.Reason != tt.reason {
+				t.Errorf("Verify() = %+v, want valid %v, broken at %d (%s)", result, tt.valid, tt.brokenAt, tt.reason)
+			}
+			if tt.valid && (result.Checked != 3 || result.LastHash != events[2].Hash) {
+				t.Errorf("Verify() checked %d events ending at %q", result.Checked, result.LastHash)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	type address struct {
+		City string `bson:"city"`
+	}
+	type record struct {
+		Name     string   `bson:"name"`
+		Password string   `bson:"password"`
+		Seats    int      `bson:"seats"`
+		Address  address  `bson:"address"`
+		Tags     []string `bson:"tags"`
+	}
+	before := record{Name: "CS", Password: "old", Seats: 10, Address: address{City: "Pune"}, Tags: []string{"a"}}
+	after := record{Name: "CS", Password: "new", Seats: 12, Address: address{City: "Delhi"}, Tags: []string{"a", "b"}}
+
+	tests := []struct {
+		name          string
+		before, after any
+		want          []models.AuditChange
+	}{
+		{
+			name:   "update",
+			before: before,
+			after:  after,
+			want: []models.AuditChange{
+				{Field: "address.city", Before: "Pune", After: "Delhi"},
+				{Field: "password", Before: redacted, After: redacted},
+				{Field: "seats", Before: 10.0, After: 12.0},
+				{Field: "tags.1", Before: nil, After: "b"},
+			},
+		},
+		{
+			name:  "creation",
+			after: map[string]any{"name": "CS", "password": "x"},
+			want: []models.AuditChange{
+				{Field: "name", After: "CS"},
+				{Field: "password", After: redacted},
+			},
+		},
+		{
+			name:   "no change",
+			before: before,
+			after:  before,
+			want:   []models.AuditChange{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("Diff: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
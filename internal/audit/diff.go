@@ -0,0 +1,133 @@
+package audit
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+
+	"admission-portal-backend/internal/models"
+)
+
+// redacted replaces the values of sensitive fields. Changes to them are
+// still recorded, just not what they changed from or to.
+const redacted = "[redacted]"
+
+var sensitiveFields = map[string]bool{
+	"password": true,
+}
+
+// Diff compares two records field by field, using their BSON encoding so
+// field names match what is stored. Nested documents and arrays are
+// flattened into dotted paths. Either side may be nil.
+func Diff(before, after any) ([]models.AuditChange, error) {
+	beforeFields, err := flatten(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := flatten(after)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for path := range beforeFields {
+		paths = append(paths, path)
+	}
+	for path := range afterFields {
+		if _, ok := beforeFields[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	changes := []models.AuditChange{}
+	for _, path := range paths {
+		from, to := beforeFields[path], afterFields[path]
+		if from == to {
+			continue
+		}
+		if sensitiveFields[lastSegment(path)] {
+			from, to = redactValue(from), redactValue(to)
+		}
+		changes = append(changes, models.AuditChange{Field: path, Before: from, After: to})
+	}
+	return changes, nil
+}
+
+func flatten(record any) (map[string]any, error) {
+	fields := map[string]any{}
+	if record == nil {
+		return fields, nil
+	}
+	raw, err := bson.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	err = flattenDocument(bson.Raw(raw), "", fields)
+	return fields, err
+}
+
+func flattenDocument(doc bson.Raw, prefix string, fields map[string]any) error {
+	elements, err := doc.Elements()
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		flattenValue(element.Value(), prefix+element.Key(), fields)
+	}
+	return nil
+}
+
+func flattenValue(value bson.RawValue, path string, fields map[string]any) {
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		_ = flattenDocument(value.Document(), path+".", fields)
+	case bsontype.Array:
+		values, _ := value.Array().Values()
+		for i, element := range values {
+			flattenValue(element, path+"."+strconv.Itoa(i), fields)
+		}
+	default:
+		fields[path] = scalar(value)
+	}
+}
+
+// scalar converts a BSON value into a string, float64, bool or nil, the
+// only types that survive a round trip through storage unchanged.
+func scalar(value bson.RawValue) any {
+	switch value.Type {
+	case bsontype.String:
+		return value.StringValue()
+	case bsontype.ObjectID:
+		return value.ObjectID().Hex()
+	case bsontype.DateTime:
+		return value.Time().UTC().Format(time.RFC3339Nano)
+	case bsontype.Double:
+		return value.Double()
+	case bsontype.Int32:
+		return float64(value.Int32())
+	case bsontype.Int64:
+		return float64(value.Int64())
+	case bsontype.Boolean:
+		return value.Boolean()
+	case bsontype.Null, bsontype.Undefined:
+		return nil
+	default:
+		return value.String()
+	}
+}
+
+func lastSegment(path string) string {
+	return path[strings.LastIndex(path, ".")+1:]
+}
+
+func redactValue(value any) any {
+	if value == nil {
+		return nil
+	}
+	return redacted
+}
@@ -0,0 +1,358 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms a Keyring supports, as named in JWT headers and JWKs.
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+const (
+	// DefaultIssuer and DefaultAudience are used for the iss and aud claims
+	// unless JWT_ISSUER and JWT_AUDIENCE say otherwise.
+	DefaultIssuer   = "admission-portal"
+	DefaultAudience = "admission-portal-api"
+	// minRSABits is the smallest RSA key accepted.
+	minRSABits = 2048
+)
+
+// ErrNoSigningKey is returned when issuing a token without a keyring, or
+// with a keyring that can only verify.
+var ErrNoSigningKey = errors.New("no JWT signing key configured")
+
+// Key is one key in a Keyring. Keys without a private half can only verify
+// tokens; they are kept during a rotation so tokens signed before it stay
+// valid until they expire.
+type Key struct {
+	ID        string
+	Algorithm string
+	Public    crypto.PublicKey
+	Private   crypto.Signer
+}
+
+// Keyring holds the keys tokens are signed and verified with, identified by
+// the kid header. One key signs new tokens; every key verifies.
+type Keyring struct {
+	Issuer   string
+	Audience string
+	signing  *Key
+	keys     map[string]*Key
+}
+
+// NewKeyring builds a keyring from keys, signing with the key whose ID is
+// signingID.
+func NewKeyring(keys []*Key, signingID, issuer, audience string) (*Keyring, error) {
+	k := &Keyring{Issuer: issuer, Audience: audience, keys: map[string]*Key{}}
+	for _, key := range keys {
+		if _, ok := k.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate JWT key ID %q", key.ID)
+		}
+		k.keys[key.ID] = key
+	}
+	signing, ok := k.keys[signingID]
+	if !ok {
+		return nil, fmt.Errorf("JWT signing key %q not found", signingID)
+	}
+	if signing.Private == nil {
+		return nil, fmt.Errorf("JWT signing key %q has no private key", signingID)
+	}
+	k.signing = signing
+	return k, nil
+}
+
+// SigningKeyID returns the kid new tokens are signed with.
+func (k *Keyring) SigningKeyID() string {
+	return k.signing.ID
+}
+
+// Keys returns every key, ordered by ID.
+func (k *Keyring) Keys() []*Key {
+	keys := make([]*Key, 0, len(k.keys))
+	for _, key := range k.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
+
+// sign signs claims with the signing key, adding iss and aud.
+func (k *Keyring) sign(claims jwt.MapClaims) (string, error) {
+	claims["iss"] = k.Issuer
+	claims["aud"] = k.Audience
+	token := jwt.NewWithClaims(signingMethod(k.signing.Algorithm), claims)
+	token.Header["kid"] = k.signing.ID
+	return token.SignedString(k.signing.Private)
+}
+
+// parse verifies a token's signature with the key named by its kid, and its
+// iss, aud, exp and nbf claims.
+func (k *Keyring) parse(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := k.keys[kid]
+		// The key decides the algorithm, never the token
+		if !ok || token.Method.Alg() != key.Algorithm {
+			return nil, jwt.ErrTokenUnverifiable
+		}
+		return key.Public, nil
+	},
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer(k.Issuer),
+		jwt.WithAudience(k.Audience),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	// Validation only checks exp when it is present
+	if _, ok := claims["exp"]; !ok {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func signingMethod(alg string) jwt.SigningMethod {
+	if alg == AlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// JWK is a public key in JSON Web Key form.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS returns the public half of every key, for other services to verify
+// tokens with.
+func (k *Keyring) JWKS() []JWK {
+	keys := k.Keys()
+	jwks := make([]JWK, 0, len(keys))
+	for _, key := range keys {
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks = append(jwks, jwk)
+	}
+	return jwks
+}
+
+// ParseKeyPEM reads a key with ID id from PEM: a PKCS#8 (or PKCS#1 RSA)
+// private key, or a PKIX public key for a key that only verifies.
+func ParseKeyPEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("JWT key %q: no PEM block found", id)
+	}
+	key := &Key{ID: id}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", id, err)
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("JWT key %q: unsupported private key type %T", id, parsed)
+		}
+		key.Private = signer
+		key.Public = signer.Public()
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", id, err)
+		}
+		key.Private = parsed
+		key.Public = parsed.Public()
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", id, err)
+		}
+		key.Public = parsed
+	default:
+		return nil, fmt.Errorf("JWT key %q: unsupported PEM block %q", id, block.Type)
+	}
+
+	switch public := key.Public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("JWT key %q: RSA keys must be at least %d bits", id, minRSABits)
+		}
+		key.Algorithm = AlgRS256
+	case ed25519.PublicKey:
+		key.Algorithm = AlgEdDSA
+	default:
+		return nil, fmt.Errorf("JWT key %q: unsupported key type %T, use RSA or Ed25519", id, public)
+	}
+	return key, nil
+}
+
+// LoadKeyDir reads every <kid>.pem file in dir as a key named by its file
+// name.
+func LoadKeyDir(dir string) ([]*Key, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParseKeyPEM(strings.TrimSuffix(filepath.Base(path), ".pem"), data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// KeyringFromEnv builds the keyring from JWT_KEYS_DIR, signing with
+// JWT_SIGNING_KID, or with the directory's only private key when that is
+// unset. JWT_ISSUER and JWT_AUDIENCE set the iss and aud claims.
+// JWT_KEYS_DIR is required, except with STORAGE=memory: data is lost on
+// restart there anyway, so a throwaway Ed25519 key is generated instead.
+func KeyringFromEnv() (*Keyring, error) {
+	issuer := os.Getenv("JWT_ISSUER")
+	if issuer == "" {
+		issuer = DefaultIssuer
+	}
+	audience := os.Getenv("JWT_AUDIENCE")
+	if audience == "" {
+		audience = DefaultAudience
+	}
+
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		if os.Getenv("STORAGE") != "memory" {
+			return nil, errors.New("JWT_KEYS_DIR is not set; create a key with portalctl keys generate")
+		}
+		key, err := GenerateKey("dev", AlgEdDSA)
+		if err != nil {
+			return nil, err
+		}
+		log.Println("JWT_KEYS_DIR is not set; signing tokens with a temporary key that is lost on restart")
+		return NewKeyring([]*Key{key}, key.ID, issuer, audience)
+	}
+
+	keys, err := LoadKeyDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	signingID := os.Getenv("JWT_SIGNING_KID")
+	if signingID == "" {
+		var private []string
+		for _, key := range keys {
+			if key.Private != nil {
+				private = append(private, key.ID)
+			}
+		}
+		if len(private) != 1 {
+			return nil, fmt.Errorf("%s holds %d private keys; set JWT_SIGNING_KID to choose one", dir, len(private))
+		}
+		signingID = private[0]
+	}
+	return NewKeyring(keys, signingID, issuer, audience)
+}
+
+// GenerateKey creates a new key pair with ID id for alg.
+func GenerateKey(id, alg string) (*Key, error) {
+	switch alg {
+	case AlgEdDSA:
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{ID: id, Algorithm: AlgEdDSA, Public: public, Private: private}, nil
+	case AlgRS256:
+		private, err := rsa.GenerateKey(rand.Reader, 3072)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{ID: id, Algorithm: AlgRS256, Public: private.Public(), Private: private}, nil
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q, use %s or %s", alg, AlgEdDSA, AlgRS256)
+}
+
+// PrivatePEM encodes the key's private half as PKCS#8 PEM.
+func (k *Key) PrivatePEM() ([]byte, error) {
+	if k.Private == nil {
+		return nil, fmt.Errorf("JWT key %q has no private key", k.ID)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// PublicPEM encodes the key's public half as PKIX PEM.
+func (k *Key) PublicPEM() ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(k.Public)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+var (
+	keyringMu sync.RWMutex
+	keyring   *Keyring
+)
+
+// SetKeyring makes k the keyring tokens are issued and verified with.
+func SetKeyring(k *Keyring) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	keyring = k
+}
+
+// CurrentKeyring returns the keyring set by SetKeyring, or nil.
+func CurrentKeyring() *Keyring {
+	keyringMu.RLock()
+	defer keyringMu.RUnlock()
+	return keyring
+}
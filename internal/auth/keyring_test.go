@@ -0,0 +1,277 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// rsaKey generates a 2048-bit RSA key, the smallest accepted, to keep the
+// tests fast.
+func rsaKey(t *testing.T, id string) *Key {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, minRSABits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Key{ID: id, Algorithm: AlgRS256, Public: private.Public(), Private: private}
+}
+
+func edKey(t *testing.T, id string) *Key {
+	t.Helper()
+	key, err := GenerateKey(id, AlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// useKeyring makes k current for the rest of the test.
+func useKeyring(t *testing.T, k *Keyring) {
+	t.Helper()
+	previous := CurrentKeyring()
+	SetKeyring(k)
+	t.Cleanup(func() { SetKeyring(previous) })
+}
+
+func newKeyring(t *testing.T, keys []*Key, signingID string) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(keys, signingID, DefaultIssuer, DefaultAudience)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	return k
+}
+
+func TestNewKeyring(t *testing.T) {
+	old, current := edKey(t, "old"), edKey(t, "current")
+	verifyOnly := &Key{ID: "public", Algorithm: AlgEdDSA, Public: old.Public}
+
+	tests := []struct {
+		name      string
+		keys      []*Key
+		signingID string
+		wantErr   string
+	}{
+		{"signs with the named key", []*Key{old, current}, "current", ""},
+		{"unknown signing key", []*Key{old}, "current", "not found"},
+		{"signing key without a private half", []*Key{verifyOnly}, "public", "no private key"},
+		{"duplicate IDs", []*Key{old, old}, "old", "duplicate"},
+	}
+	for _, tt := range tests {
+		k, err := NewKeyring(tt.keys, tt.signingID, DefaultIssuer, DefaultAudience)
+		if tt.wantErr == "" {
+			if err != nil || k.SigningKeyID() != tt.signingID {
+				t.Errorf("%s: NewKeyring = %v, %v", tt.name, k, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: NewKeyring error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	old, current := edKey(t, "old"), rsaKey(t, "current")
+	useKeyring(t, newKeyring(t, []*Key{old}, "old"))
+	issued, _, err := IssueAccessToken(Claims{UserID: "u1", Role: "student", SessionID: "s1"})
+	if err != nil {
+		t.Fatalf("IssueAccessToken: %v", err)
+	}
+
+	// The old key stays, public half only, so its tokens outlive the rotation
+	retired := &Key{ID: old.ID, Algorithm: old.Algorithm, Public: old.Public}
+	useKeyring(t, newKeyring(t, []*Key{retired, current}, "current"))
+	if claims, err := ParseAccessToken(issued); err != nil || claims.UserID != "u1" || claims.SessionID != "s1" {
+		t.Errorf("token signed before the rotation = %+v, %v", claims, err)
+	}
+	fresh, _, err := IssueAccessToken(Claims{UserID: "u2", Role: "student", SessionID: "s2"})
+	if err != nil {
+		t.Fatalf("IssueAccessToken after the rotation: %v", err)
+	}
+	header, _ := base64.RawURLEncoding.DecodeString(strings.Split(fresh, ".")[0])
+	if !strings.Contains(string(header), `"kid":"current"`) || !strings.Contains(string(header), `"alg":"RS256"`) {
+		t.Errorf("new token header = %s, want kid current and RS256", header)
+	}
+
+	// Once the old key is dropped its tokens stop working
+	useKeyring(t, newKeyring(t, []*Key{current}, "current"))
+	if _, err := ParseAccessToken(issued); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token of a dropped key = %v, want ErrInvalidToken", err)
+	}
+	if _, err := ParseAccessToken(fresh); err != nil {
+		t.Errorf("token of the current key = %v", err)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	ed, rs := edKey(t, "ed"), rsaKey(t, "rs")
+	k := newKeyring(t, []*Key{ed, rs}, "ed")
+	useKeyring(t, k)
+	now := time.Now()
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{"typ": accessTokenType, "user_id": "u1", "sid": "s1", "iss": DefaultIssuer, "aud": DefaultAudience, "exp": now.Add(time.Minute).Unix()}
+	}
+	signWith := func(key *Key, kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(signingMethod(key.Algorithm), claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key.Private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	if _, err := ParseAccessToken(signWith(ed, "ed", valid())); err != nil {
+		t.Fatalf("valid token = %v", err)
+	}
+	invite, _ := IssueInviteToken("invite-1", now.Add(time.Hour))
+	stranger := edKey(t, "ed")
+
+	tests := map[string]string{
+		"another purpose":        invite,
+		"signed by an unknown":   signWith(stranger, "ed", valid()),
+		"kid of another key":     signWith(rs, "ed", valid()),
+		"unknown kid":            signWith(ed, "missing", valid()),
+		"expired":                signWith(ed, "ed", jwt.MapClaims{"typ": accessTokenType, "user_id": "u1", "sid": "s1", "iss": DefaultIssuer, "aud": DefaultAudience, "exp": now.Add(-time.Minute).Unix()}),
+		"no expiry":              signWith(ed, "ed", jwt.MapClaims{"typ": accessTokenType, "user_id": "u1", "sid": "s1", "iss": DefaultIssuer, "aud": DefaultAudience}),
+		"other issuer":           signWith(ed, "ed", jwt.MapClaims{"typ": accessTokenType, "user_id": "u1", "sid": "s1", "iss": "elsewhere", "aud": DefaultAudience, "exp": now.Add(time.Minute).Unix()}),
+		"other audience":         signWith(ed, "ed", jwt.MapClaims{"typ": accessTokenType, "user_id": "u1", "sid": "s1", "iss": DefaultIssuer, "aud": "elsewhere", "exp": now.Add(time.Minute).Unix()}),
+		"unsigned":               strings.Join(strings.Split(signWith(ed, "ed", valid()), ".")[:2], ".") + ".",
+		"symmetric with the kid": hmacToken(t, valid()),
+	}
+	for name, token := range tests {
+		if _, err := ParseAccessToken(token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: ParseAccessToken = %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
+
+// hmacToken signs claims with HS256 under the kid of an asymmetric key, as
+// an attacker holding only its public half might.
+func hmacToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = "rs"
+	signed, err := token.SignedString([]byte("public key bytes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestKeyPEMRoundTrip(t *testing.T) {
+	for _, key := range []*Key{edKey(t, "ed"), rsaKey(t, "rs")} {
+		private, err := key.PrivatePEM()
+		if err != nil {
+			t.Fatalf("%s: PrivatePEM: %v", key.ID, err)
+		}
+		parsed, err := ParseKeyPEM(key.ID, private)
+		if err != nil || parsed.Private == nil || parsed.Algorithm != key.Algorithm {
+			t.Errorf("%s: ParseKeyPEM of the private key = %+v, %v", key.ID, parsed, err)
+		}
+
+		public, err := key.PublicPEM()
+		if err != nil {
+			t.Fatalf("%s: PublicPEM: %v", key.ID, err)
+		}
+		parsed, err = ParseKeyPEM(key.ID, public)
+		if err != nil || parsed.Private != nil || parsed.Algorithm != key.Algorithm {
+			t.Errorf("%s: ParseKeyPEM of the public key = %+v, %v", key.ID, parsed, err)
+		}
+		if _, err := parsed.PrivatePEM(); err == nil {
+			t.Errorf("%s: PrivatePEM of a public key succeeded", key.ID)
+		}
+	}
+
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weak, _ := (&Key{ID: "weak", Public: small.Public(), Private: small}).PrivatePEM()
+	if _, err := ParseKeyPEM("weak", weak); err == nil {
+		t.Error("ParseKeyPEM accepted a 1024-bit RSA key")
+	}
+	if _, err := ParseKeyPEM("junk", []byte("not a key")); err == nil {
+		t.Error("ParseKeyPEM accepted data with no PEM block")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	ed, rs := edKey(t, "b-ed"), rsaKey(t, "a-rs")
+	jwks := newKeyring(t, []*Key{ed, rs}, "b-ed").JWKS()
+	if len(jwks) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(jwks))
+	}
+	if got := jwks[0]; got.KeyID != "a-rs" || got.KeyType != "RSA" || got.Algorithm != AlgRS256 || got.N == "" || got.E != "AQAB" || got.X != "" {
+		t.Errorf("RSA JWK = %+v", got)
+	}
+	if got := jwks[1]; got.KeyID != "b-ed" || got.KeyType != "OKP" || got.Curve != "Ed25519" || got.Algorithm != AlgEdDSA || got.X == "" || got.N != "" {
+		t.Errorf("Ed25519 JWK = %+v", got)
+	}
+	for _, jwk := range jwks {
+		if jwk.Use != "sig" {
+			t.Errorf("JWK %s use = %q, want sig", jwk.KeyID, jwk.Use)
+		}
+	}
+}
+
+func TestKeyringFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	write := func(key *Key, private bool) {
+		t.Helper()
+		data, err := key.PublicPEM()
+		if private {
+			data, err = key.PrivatePEM()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, key.ID+".pem"), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(edKey(t, "2025"), false)
+	write(edKey(t, "2026"), true)
+	t.Setenv("JWT_KEYS_DIR", dir)
+	t.Setenv("JWT_SIGNING_KID", "")
+	t.Setenv("JWT_ISSUER", "issuer")
+	t.Setenv("JWT_AUDIENCE", "")
+
+	k, err := KeyringFromEnv()
+	if err != nil {
+		t.Fatalf("KeyringFromEnv: %v", err)
+	}
+	if k.SigningKeyID() != "2026" || len(k.Keys()) != 2 || k.Issuer != "issuer" || k.Audience != DefaultAudience {
+		t.Errorf("keyring signs with %s, has %d keys, iss %q, aud %q", k.SigningKeyID(), len(k.Keys()), k.Issuer, k.Audience)
+	}
+
+	// With two private keys the signing key must be named
+	write(edKey(t, "2027"), true)
+	if _, err := KeyringFromEnv(); err == nil {
+		t.Error("KeyringFromEnv chose between two private keys on its own")
+	}
+	t.Setenv("JWT_SIGNING_KID", "2027")
+	if k, err := KeyringFromEnv(); err != nil || k.SigningKeyID() != "2027" {
+		t.Errorf("KeyringFromEnv with JWT_SIGNING_KID = %v, %v", k, err)
+	}
+
+	t.Setenv("JWT_KEYS_DIR", "")
+	t.Setenv("STORAGE", "")
+	if _, err := KeyringFromEnv(); err == nil {
+		t.Error("KeyringFromEnv without JWT_KEYS_DIR succeeded outside memory storage")
+	}
+	t.Setenv("STORAGE", "memory")
+	if k, err := KeyringFromEnv(); err != nil || k.SigningKeyID() != "dev" {
+		t.Errorf("KeyringFromEnv with memory storage = %v, %v", k, err)
+	}
+}
@@ -0,0 +1,160 @@
+// Package auth issues and verifies the tokens used to authenticate API
+// requests: short-lived JWT access tokens and opaque refresh tokens. JWTs
+// are signed with the current Keyring's signing key and verified with any
+// key in it.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Token types, carried in the typ claim, so a token issued for one purpose
+// cannot be used for another.
+const (
+	accessTokenType = "access"
+	inviteTokenType = "invite"
+)
+
+// Claims are the fields carried by an access token.
+type Claims struct {
+	UserID    string
+	Role      string
+	SessionID string
+}
+
+// issue signs claims of type typ, valid from now until expiresAt.
+func issue(typ string, claims jwt.MapClaims, now, expiresAt time.Time) (string, error) {
+	keys := CurrentKeyring()
+	if keys == nil {
+		return "", ErrNoSigningKey
+	}
+	claims["typ"] = typ
+	claims["iat"] = now.Unix()
+	claims["nbf"] = now.Unix()
+	claims["exp"] = expiresAt.Unix()
+	return keys.sign(claims)
+}
+
+// parse verifies a token and returns its claims if it is of type typ.
+func parse(typ, tokenString string) (jwt.MapClaims, error) {
+	keys := CurrentKeyring()
+	if keys == nil {
+		return nil, ErrInvalidToken
+	}
+	claims, err := keys.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims["typ"] != typ {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// IssueAccessToken signs an access token for the session and returns it with
+// its expiry time.
+func IssueAccessToken(claims Claims) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(AccessTokenTTL)
+	tokenString, err := issue(accessTokenType, jwt.MapClaims{
+		"sub":     claims.UserID,
+		"user_id": claims.UserID,
+		"role":    claims.Role,
+		"sid":     claims.SessionID,
+	}, now, expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return tokenString, expiresAt, nil
+}
+
+// ParseAccessToken verifies the signature, issuer, audience and validity
+// period of an access token and returns its claims.
+func ParseAccessToken(tokenString string) (*Claims, error) {
+	mapClaims, err := parse(accessTokenType, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	claims := &Claims{}
+	claims.UserID, _ = mapClaims["user_id"].(string)
+	claims.Role, _ = mapClaims["role"].(string)
+	claims.SessionID, _ = mapClaims["sid"].(string)
+	if claims.UserID == "" || claims.SessionID == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// IssueInviteToken signs a token that redeems the invite with inviteID
+// until expiresAt.
+func IssueInviteToken(inviteID string, expiresAt time.Time) (string, error) {
+	return issue(inviteTokenType, jwt.MapClaims{"jti": inviteID}, time.Now(), expiresAt)
+}
+
+// ParseInviteToken verifies an invite token and returns the ID of the
+// invite it redeems. Whether the invite is still unused is up to the
+// caller.
+func ParseInviteToken(tokenString string) (string, error) {
+	claims, err := parse(inviteTokenType, tokenString)
+	if err != nil {
+		return "", err
+	}
+	inviteID, _ := claims["jti"].(string)
+	if inviteID == "" {
+		return "", ErrInvalidToken
+	}
+	return inviteID, nil
+}
+
+// NewOpaqueToken returns a random URL-safe token together with the hash that
+// should be stored in its place.
+func NewOpaqueToken() (token string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the SHA-256 hex digest of an opaque token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewRefreshToken returns a refresh token for the session and the hash to
+// store on it. The session ID is embedded so the session can be found
+// without an index on the hash.
+func NewRefreshToken(sessionID string) (token string, hash string, err error) {
+	secret, hash, err := NewOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	return sessionID + "." + secret, hash, nil
+}
+
+// SplitRefreshToken returns the session ID and the hash of the secret part of
+// a refresh token.
+func SplitRefreshToken(token string) (sessionID string, hash string, err error) {
+	sessionID, secret, ok := strings.Cut(token, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return "", "", ErrInvalidToken
+	}
+	return sessionID, HashToken(secret), nil
+}
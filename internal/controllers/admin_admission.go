@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/workflow"
+)
+
+var adminAdmissionListSpec = query.Spec{
+	Filters: append([]query.Filter{
+		{Param: "studentId", Field: "studentId", Type: query.ObjectID, Op: query.OpEq},
+	}, admissionListSpec.Filters...),
+	Sorts:       admissionListSpec.Sorts,
+	DefaultSort: admissionListSpec.DefaultSort,
+	Search: []string{
+		"personalDetails.firstName",
+		"personalDetails.lastName",
+		"personalDetails.email",
+		"personalDetails.phone",
+		"personalDetails.nationality",
+		"personalDetails.address.street",
+		"personalDetails.address.city",
+		"personalDetails.address.state",
+		"personalDetails.address.zipCode",
+		"personalDetails.address.country",
+	},
+}
+
+// AdminListAdmissions lists every student's admissions.
+func (h *Handler) AdminListAdmissions(c *gin.Context) {
+	opts, ok := parseListQuery(c, adminAdmissionListSpec)
+	if !ok {
+		return
+	}
+
+	// Drafts belong to the student until they are submitted
+	admissions, err := h.Admissions.List(c.Request.Context(), opts.Where("status", query.OpNe, workflow.StatusDraft))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching admissions"})
+		return
+	}
+
+	views, err := h.admissionViews(c.Request.Context(), admissions.Items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching admissions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, query.Page[models.AdmissionView]{
+		Items:      views,
+		Total:      admissions.Total,
+		Limit:      admissions.Limit,
+		Page:       admissions.Page,
+		NextCursor: admissions.NextCursor,
+	})
+}
+
+// AdminGetAdmission returns any admission with its student and course.
+func (h *Handler) AdminGetAdmission(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admission ID"})
+		return
+	}
+
+	admission, err := h.Admissions.FindByID(c.Request.Context(), objectID)
+	if errors.Is(err, repositories.ErrNotFound) || (err == nil && workflow.Normalize(admission.Status) == workflow.StatusDraft) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching admission"})
+		return
+	}
+
+	views, err := h.admissionViews(c.Request.Context(), []models.Admission{*admission})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching admission"})
+		return
+	}
+
+	c.JSON(http.StatusOK, views[0])
+}
+
+// admissionViews joins admissions with their students and courses, loading
+// each referenced record once.
+func (h *Handler) admissionViews(ctx context.Context, admissions []models.Admission) ([]models.AdmissionView, error) {
+	studentIDs, courseIDs := []any{}, []any{}
+	seen := map[primitive.ObjectID]bool{}
+	for _, admission := range admissions {
+		if !seen[admission.StudentID] {
+			seen[admission.StudentID] = true
+			studentIDs = append(studentIDs, admission.StudentID)
+		}
+		if !seen[admission.CourseID] {
+			seen[admission.CourseID] = true
+			courseIDs = append(courseIDs, admission.CourseID)
+		}
+	}
+
+	students := map[primitive.ObjectID]*models.StudentSummary{}
+	if len(studentIDs) > 0 {
+		page, err := h.Students.List(ctx, query.Options{}.Where("_id", query.OpIn, studentIDs))
+		if err != nil {
+			return nil, err
+		}
+		for _, student := range page.Items {
+			students[student.ID] = &models.StudentSummary{
+				ID:    student.ID,
+				Name:  student.Name,
+				Email: student.Email,
+				Phone: student.Phone,
+			}
+		}
+	}
+
+	courses := map[primitive.ObjectID]*models.CourseSummary{}
+	if len(courseIDs) > 0 {
+		page, err := h.Courses.List(ctx, query.Options{}.Where("_id", query.OpIn, courseIDs))
+		if err != nil {
+			return nil, err
+		}
+		for _, course := range page.Items {
+			courses[course.ID] = &models.CourseSummary{
+				ID:       course.ID,
+				Name:     course.Name,
+				Duration: course.Duration,
+			}
+		}
+	}
+
+	views := make([]models.AdmissionView, 0, len(admissions))
+	for _, admission := range admissions {
+		views = append(views, models.AdmissionView{
+			Admission: admission,
+			Student:   students[admission.StudentID],
+			Course:    courses[admission.CourseID],
+		})
+	}
+	return views, nil
+}
@@ -1,21 +1,48 @@
 package controllers
 
 import (
-	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
 
-	"admission-portal-backend/internal/config"
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/eligibility"
 	"admission-portal-backend/internal/models"
-	"log"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/quota"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/services"
+	"admission-portal-backend/internal/workflow"
 )
 
-func ApplyAdmission(c *gin.Context) {
+// maxPreferences caps how many courses one application may rank.
+const maxPreferences = 10
+
+// applicationRequest is the body of a new application or draft.
+type applicationRequest struct {
+	CourseID string `json:"courseId"`
+	// Preferences ranks several course IDs, most wanted first, in place
+	// of a single CourseID.
+	Preferences     []string               `json:"preferences"`
+	PersonalDetails models.PersonalDetails `json:"personalDetails"`
+	AcademicDetails models.AcademicDetails `json:"academicDetails"`
+	Documents       models.Documents       `json:"documents"`
+	Category        string                 `json:"category"`
+	// Cycle is the code of the intake applied to. It may be left out
+	// while only one cycle is taking applications for the course.
+	Cycle string `json:"cycle"`
+}
+
+// ApplyAdmission makes and submits an application in one request. It is held
+// to the same rules as submitting a draft: every required detail and
+// document must be there.
+func (h *Handler) ApplyAdmission(c *gin.Context) {
 	// Extract userID from JWT (set by middleware)
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -28,46 +55,332 @@ func ApplyAdmission(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		CourseID        string                 `json:"courseId"`
-		PersonalDetails models.PersonalDetails `json:"personalDetails"`
-		AcademicDetails models.AcademicDetails `json:"academicDetails"`
-		Documents       models.Documents       `json:"documents"`
-	}
+	var req applicationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	courseID, err := primitive.ObjectIDFromHex(req.CourseID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+	courseIDs, ok := parseCourseChoices(c, req.CourseID, req.Preferences)
+	if !ok {
+		return
+	}
+	if !h.requireVerifiedStudent(c, studentID) {
 		return
 	}
 
+	now := time.Now()
 	admission := models.Admission{
 		StudentID:       studentID,
-		CourseID:        courseID,
 		PersonalDetails: req.PersonalDetails,
 		AcademicDetails: req.AcademicDetails,
 		Documents:       req.Documents,
-		Status:          "pending",
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
+		Category:        req.Category,
+		Cycle:           strings.TrimSpace(req.Cycle),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	errs := fieldErrors{}
+	validatePersonalDetails(admission.PersonalDetails, true, errs)
+	validateAcademicDetails(admission.AcademicDetails, true, errs)
+	validateDocuments(admission.Documents, true, errs)
+	if !writeFieldErrors(c, "The application is incomplete", errs) {
+		return
+	}
+	if !h.finalizeApplication(c, &admission, courseIDs, len(req.Preferences) > 0) {
+		return
 	}
 
-	// Insert admission into DB...
-	collection := config.GetCollection("admissions")
-	result, err := collection.InsertOne(context.Background(), admission)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while applying for admission"})
+	// Applications with a fee wait as drafts until it is paid
+	initial := workflow.StatusSubmitted
+	if admission.ApplicationFee+admission.LateFee > 0 {
+		initial = workflow.StatusDraft
+	}
+	admission.Status = initial
+	admission.StatusHistory = []models.StatusChange{{
+		To:        initial,
+		ChangedBy: studentID,
+		Role:      workflow.RoleStudent,
+		ChangedAt: now,
+	}}
+
+	if !h.createAdmission(c, &admission, "Error while applying for admission") {
 		return
 	}
-	admission.ID = result.InsertedID.(primitive.ObjectID)
+
 	c.JSON(http.StatusCreated, admission)
 }
 
-func GetAdmissions(c *gin.Context) {
+// writeDuplicateApplication answers 409 for an application the student has
+// already made to the same course in the same cycle, pointing at the
+// existing one.
+func (h *Handler) writeDuplicateApplication(c *gin.Context, admission *models.Admission) {
+	response := gin.H{"error": "You have already applied to this course in this admission cycle"}
+	opts := query.Options{Limit: 1}.
+		Where("studentId", query.OpEq, admission.StudentID).
+		Where("courseId", query.OpEq, admission.CourseID)
+	if admission.Cycle != "" {
+		opts = opts.Where("cycle", query.OpEq, admission.Cycle)
+	}
+	if existing, err := h.Admissions.List(c.Request.Context(), opts); err == nil && len(existing.Items) > 0 {
+		response["admissionId"] = existing.Items[0].ID
+	}
+	c.JSON(http.StatusConflict, response)
+}
+
+// parseCourseChoices reads the courses an application is for from either a
+// single courseId or a ranked preference list, answering the request itself
+// and returning false when they are malformed.
+func parseCourseChoices(c *gin.Context, courseID string, preferences []string) ([]primitive.ObjectID, bool) {
+	rawIDs := preferences
+	switch {
+	case len(rawIDs) == 0:
+		rawIDs = []string{courseID}
+	case courseID != "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Send either courseId or preferences, not both"})
+		return nil, false
+	case len(rawIDs) > maxPreferences:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d course preferences are allowed", maxPreferences)})
+		return nil, false
+	}
+	courseIDs := make([]primitive.ObjectID, 0, len(rawIDs))
+	seen := map[primitive.ObjectID]bool{}
+	for _, raw := range rawIDs {
+		courseID, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+			return nil, false
+		}
+		if seen[courseID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Course listed more than once in preferences: " + raw})
+			return nil, false
+		}
+		seen[courseID] = true
+		courseIDs = append(courseIDs, courseID)
+	}
+	return courseIDs, true
+}
+
+// requireVerifiedStudent answers the request and returns false unless the
+// student exists and has verified their email address.
+func (h *Handler) requireVerifiedStudent(c *gin.Context, studentID primitive.ObjectID) bool {
+	student, err := h.Students.FindByID(c.Request.Context(), studentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return false
+	}
+	if !student.EmailVerified {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address before applying"})
+		return false
+	}
+	return true
+}
+
+// finalizeApplication runs every check an application must pass to be
+// submitted to courseIDs in admission.Cycle: category and certificate,
+// cycle window, eligibility and documents. On success it fills in the
+// course the application is considered for, its eligibility, preferences
+// when ranked, and the fees due, and stamps SubmittedAt. Otherwise it
+// answers the request itself and returns false.
+func (h *Handler) finalizeApplication(c *gin.Context, admission *models.Admission, courseIDs []primitive.ObjectID, ranked bool) bool {
+	category := quota.Normalize(admission.Category)
+	if !quota.IsValid(category) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown category: " + admission.Category, "categories": quota.Categories})
+		return false
+	}
+	if category != quota.General && admission.Documents.CategoryCertificate == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "documents.categoryCertificate is required for the " + category + " category"})
+		return false
+	}
+
+	cycle, ok := h.applicationCycle(c, admission.Cycle, courseIDs)
+	if !ok {
+		return false
+	}
+
+	preferences, ok := h.evaluatePreferences(c, courseIDs, admission.AcademicDetails)
+	if !ok {
+		return false
+	}
+	// The application is considered for its best eligible course first
+	first := preferences[0]
+	for _, preference := range preferences {
+		if preference.Status == models.PreferencePending {
+			first = preference
+			break
+		}
+	}
+
+	course, err := h.Courses.FindByID(c.Request.Context(), first.CourseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while applying for admission"})
+		return false
+	}
+
+	now := time.Now()
+	status := cycle.Status(now)
+	switch status {
+	case models.CycleUpcoming:
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Applications for " + cycle.Name + " are not open yet",
+			"opensAt": cycle.OpensAt,
+		})
+		return false
+	case models.CycleClosed:
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":    "Applications for " + cycle.Name + " are closed",
+			"closesAt": cycle.ClosesAt,
+		})
+		return false
+	}
+
+	var lateFee float64
+	if status == models.CycleLate {
+		lateFee = cycle.LateSubmission.Fee
+	}
+
+	// Documents must be uploaded first and referenced by ID
+	if !h.checkApplicationDocuments(c, admission) {
+		return false
+	}
+
+	admission.CourseID = first.CourseID
+	admission.Category = category
+	admission.Cycle = cycle.Code
+	admission.Eligibility = first.Eligibility
+	admission.Late = status == models.CycleLate
+	admission.ApplicationFee = course.Fees.AdmissionFee
+	admission.LateFee = lateFee
+	admission.Preferences = nil
+	if ranked {
+		admission.Preferences = preferences
+	}
+	admission.SubmittedAt = &now
+	return true
+}
+
+// checkApplicationDocuments checks that every document an application
+// references was uploaded by its student for no other application, and that
+// the category certificate was uploaded as one. It answers the request
+// itself and returns false when a check fails.
+func (h *Handler) checkApplicationDocuments(c *gin.Context, admission *models.Admission) bool {
+	if ref, err := h.checkDocumentRefs(c.Request.Context(), admission.StudentID, admission.ID, admissionDocumentRefs(admission)); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown document: " + ref})
+			return false
+		case errors.Is(err, repositories.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Document " + ref + " is already attached to another application"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while applying for admission"})
+		return false
+	}
+	if ref := admission.Documents.CategoryCertificate; ref != "" && !h.hasDocumentKind(c.Request.Context(), ref, models.DocumentKindCategoryCertificate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "documents.categoryCertificate must be a document uploaded as " + models.DocumentKindCategoryCertificate})
+		return false
+	}
+	return true
+}
+
+// applicationCycle finds the cycle an application is made in and checks
+// that it offers every course applied to, answering the request itself and
+// returning false when it cannot. Without a code, the one cycle currently
+// taking applications for the first course is used.
+func (h *Handler) applicationCycle(c *gin.Context, code string, courseIDs []primitive.ObjectID) (*models.AdmissionCycle, bool) {
+	var cycle *models.AdmissionCycle
+	if code != "" {
+		found, ok := h.findCycle(c, c.Request.Context(), strings.ToLower(code))
+		if !ok {
+			return nil, false
+		}
+		cycle = found
+	} else {
+		cycles, err := h.Cycles.List(c.Request.Context(), query.Options{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while applying for admission"})
+			return nil, false
+		}
+		now := time.Now()
+		for i := range cycles.Items {
+			candidate := &cycles.Items[i]
+			if _, offered := candidate.Course(courseIDs[0]); !offered {
+				continue
+			}
+			if status := candidate.Status(now); status != models.CycleOpen && status != models.CycleLate {
+				continue
+			}
+			if cycle != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "More than one admission cycle is open for this course, please specify cycle"})
+				return nil, false
+			}
+			cycle = candidate
+		}
+		if cycle == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "No admission cycle is accepting applications for this course"})
+			return nil, false
+		}
+	}
+
+	for _, courseID := range courseIDs {
+		if _, offered := cycle.Course(courseID); !offered {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Course " + courseID.Hex() + " is not offered in " + cycle.Name})
+			return nil, false
+		}
+	}
+	return cycle, true
+}
+
+// evaluatePreferences loads each course in order and checks the applicant
+// against its rules. Courses that reject them are marked ineligible; if that
+// leaves none, the request is answered with 422 and ok is false.
+func (h *Handler) evaluatePreferences(c *gin.Context, courseIDs []primitive.ObjectID, details models.AcademicDetails) ([]models.Preference, bool) {
+	preferences := make([]models.Preference, 0, len(courseIDs))
+	eligible := false
+	for i, courseID := range courseIDs {
+		course, err := h.Courses.FindByID(c.Request.Context(), courseID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+				return nil, false
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while applying for admission"})
+			return nil, false
+		}
+
+		result := eligibility.Evaluate(course.EligibilityCriteria, details)
+		status := models.PreferencePending
+		if !result.Eligible && eligibility.Mode(course.EligibilityCriteria) == eligibility.ModeReject {
+			status = models.PreferenceIneligible
+		} else {
+			eligible = true
+		}
+		preferences = append(preferences, models.Preference{
+			Rank:        i + 1,
+			CourseID:    courseID,
+			Status:      status,
+			Eligibility: &result,
+		})
+	}
+	if eligible {
+		return preferences, true
+	}
+
+	if len(preferences) == 1 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":       "You do not meet the eligibility criteria for this course",
+			"eligibility": preferences[0].Eligibility,
+		})
+	} else {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":       "You do not meet the eligibility criteria for any of your preferred courses",
+			"preferences": preferences,
+		})
+	}
+	return nil, false
+}
+
+func (h *Handler) GetAdmissions(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	studentID, err := primitive.ObjectIDFromHex(userID.(string))
 	if err != nil {
@@ -75,26 +388,21 @@ func GetAdmissions(c *gin.Context) {
 		return
 	}
 
-	collection := config.GetCollection("admissions")
-	findOptions := options.Find()
-
-	cursor, err := collection.Find(context.Background(), bson.M{"studentId": studentID}, findOptions)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching admissions"})
+	opts, ok := parseListQuery(c, admissionListSpec)
+	if !ok {
 		return
 	}
-	defer cursor.Close(context.Background())
 
-	var admissions []models.Admission
-	if err = cursor.All(context.Background(), &admissions); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while decoding admissions"})
+	admissions, err := h.Admissions.List(c.Request.Context(), opts.Where("studentId", query.OpEq, studentID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching admissions"})
 		return
 	}
 
 	c.JSON(http.StatusOK, admissions)
 }
 
-func GetAdmission(c *gin.Context) {
+func (h *Handler) GetAdmission(c *gin.Context) {
 	id := c.Param("id")
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -109,13 +417,8 @@ func GetAdmission(c *gin.Context) {
 		return
 	}
 
-	collection := config.GetCollection("admissions")
-	var admission models.Admission
-	err = collection.FindOne(context.Background(), bson.M{
-		"_id":       objectID,
-		"studentId": studentID,
-	}).Decode(&admission)
-	if err != nil {
+	admission, err := h.Admissions.FindByID(c.Request.Context(), objectID)
+	if err != nil || admission.StudentID != studentID {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
 		return
 	}
@@ -123,7 +426,7 @@ func GetAdmission(c *gin.Context) {
 	c.JSON(http.StatusOK, admission)
 }
 
-func UpdateAdmissionStatus(c *gin.Context) {
+func (h *Handler) UpdateAdmissionStatus(c *gin.Context) {
 	id := c.Param("id")
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -131,8 +434,17 @@ func UpdateAdmissionStatus(c *gin.Context) {
 		return
 	}
 
+	userID, _ := c.Get("userID")
+	actorID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	role, _ := c.Get("role")
+	actorRole, _ := role.(string)
+
 	var updateData struct {
-		Status   string `json:"status" binding:"required,oneof=pending approved rejected"`
+		Status   string `json:"status" binding:"required"`
 		Comments string `json:"comments"`
 	}
 
@@ -140,34 +452,146 @@ func UpdateAdmissionStatus(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !workflow.IsValid(updateData.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown admission status"})
+		return
+	}
+
+	admission, err := h.Admissions.FindByID(c.Request.Context(), objectID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while updating admission"})
+		return
+	}
+	// Students may only act on their own applications, and staff never see drafts
+	if !canSeeAdmission(admission, actorID.Hex(), actorRole) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
+		return
+	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"status":     updateData.Status,
-			"comments":   updateData.Comments,
-			"updated_at": time.Now(),
-		},
+	actor := services.Actor{ID: actorID, Role: actorRole}
+	status, err := h.AdmissionService.Transition(c.Request.Context(), admission, updateData.Status, actor, updateData.Comments)
+	if err != nil {
+		writeTransitionError(c, err, admission.Status, updateData.Status)
+		return
 	}
 
-	collection := config.GetCollection("admissions")
-	result, err := collection.UpdateOne(
-		context.Background(),
-		bson.M{"_id": objectID},
-		update,
-	)
+	// Diff the whole record so the new history entry is captured as well
+	var before, after any = gin.H{"status": admission.Status}, gin.H{"status": status}
+	if updated, err := h.Admissions.FindByID(c.Request.Context(), objectID); err == nil {
+		before, after = admission, updated
+	}
+	if !h.audit(c, audit.ActionAdmissionTransition, audit.TargetAdmission, objectID, before, after) {
+		return
+	}
+
+	// Log notification
+	log.Printf("Notification: Admission status updated. AdmissionID: %s, NewStatus: %s, Comments: %s", id, status, updateData.Comments)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Admission status updated successfully", "status": status})
+}
 
+// WithdrawAdmission lets a student withdraw one of their applications.
+func (h *Handler) WithdrawAdmission(c *gin.Context) {
+	h.answerAdmission(c, workflow.StatusWithdrawn, "Application withdrawn")
+}
+
+// AcceptOffer lets a student accept the offer on one of their applications
+// before it expires.
+func (h *Handler) AcceptOffer(c *gin.Context) {
+	h.answerAdmission(c, workflow.StatusAccepted, "Offer accepted")
+}
+
+// DeclineOffer lets a student turn down an offer, giving its seat to the
+// waitlist.
+func (h *Handler) DeclineOffer(c *gin.Context) {
+	h.answerAdmission(c, workflow.StatusDeclined, "Offer declined")
+}
+
+// answerAdmission moves the caller's own admission to status to, with an
+// optional reason in the body as the change's comments.
+func (h *Handler) answerAdmission(c *gin.Context, to, message string) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while updating admission"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admission ID"})
 		return
 	}
+	actorID, err := primitive.ObjectIDFromHex(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
-	if result.MatchedCount == 0 {
+	admission, err := h.Admissions.FindByID(c.Request.Context(), objectID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while updating admission"})
+		return
+	}
+	if err != nil || admission.StudentID != actorID {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
 		return
 	}
 
-	// Log notification
-	log.Printf("Notification: Admission status updated. AdmissionID: %s, NewStatus: %s, Comments: %s", id, updateData.Status, updateData.Comments)
+	actor := services.Actor{ID: actorID, Role: c.GetString("role")}
+	status, err := h.AdmissionService.Transition(c.Request.Context(), admission, to, actor, strings.TrimSpace(req.Reason))
+	if err != nil {
+		writeTransitionError(c, err, admission.Status, to)
+		return
+	}
+
+	updated, err := h.Admissions.FindByID(c.Request.Context(), objectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching admission"})
+		return
+	}
+	if !h.audit(c, audit.ActionAdmissionTransition, audit.TargetAdmission, objectID, admission, updated) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message, "status": status, "admission": updated})
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Admission status updated successfully"})
+// writeTransitionError maps a failed status change onto an HTTP response.
+func writeTransitionError(c *gin.Context, err error, from, to string) {
+	switch {
+	case errors.Is(err, workflow.ErrIllegalTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot move admission from " + from + " to " + to})
+	case errors.Is(err, workflow.ErrRoleNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to move this admission to " + to})
+	case errors.Is(err, workflow.ErrUnknownStatus):
+		c.JSON(http.StatusConflict, gin.H{"error": "Admission has an unknown status: " + from})
+	case errors.Is(err, repositories.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "Admission status was changed by another request, please retry"})
+	case errors.Is(err, repositories.ErrNoSeats):
+		c.JSON(http.StatusConflict, gin.H{"error": "No seats remaining for this course"})
+	case errors.Is(err, services.ErrCourseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+	case errors.Is(err, services.ErrCycleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admission cycle not found"})
+	case errors.Is(err, repositories.ErrDuplicate):
+		c.JSON(http.StatusConflict, gin.H{"error": "The applicant already has an application for that course in this admission cycle"})
+	case errors.Is(err, services.ErrOfferExpired):
+		c.JSON(http.StatusConflict, gin.H{"error": "The offer has expired and its seat has been released"})
+	case errors.Is(err, services.ErrNotSubmitted):
+		c.JSON(http.StatusConflict, gin.H{"error": "Drafts are submitted through POST /api/admissions/:id/submit"})
+	case errors.Is(err, services.ErrPaymentRequired):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": "The fee for this step has not been paid"})
+	case errors.Is(err, repositories.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while updating admission"})
+	}
 }
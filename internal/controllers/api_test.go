@@ -0,0 +1,227 @@
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+
+	"admission-portal-backend/internal/auth"
+	"admission-portal-backend/internal/controllers"
+	"admission-portal-backend/internal/mailer"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/payments"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/routes"
+	"admission-portal-backend/internal/storage"
+)
+
+const testPassword = "secret1"
+
+// testAPI serves the routes over the in-memory store, recording the mail
+// it sends instead of delivering it.
+type testAPI struct {
+	router  *gin.Engine
+	store   *repositories.Store
+	handler *controllers.Handler
+	mail    *recordingMailer
+}
+
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// last returns the most recent message sent to, or nil.
+func (m *recordingMailer) last(to string) *mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.sent) - 1; i >= 0; i-- {
+		if m.sent[i].To == to {
+			msg := m.sent[i]
+			return &msg
+		}
+	}
+	return nil
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	key, err := auth.GenerateKey("test", auth.AlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := auth.NewKeyring([]*auth.Key{key}, key.ID, auth.DefaultIssuer, auth.DefaultAudience)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth.SetKeyring(keyring)
+	blobs, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := repositories.NewMemoryStore()
+	mail := &recordingMailer{}
+	handler := controllers.NewHandler(store, mail, blobs, payments.NewFakeGateway([]byte("webhook-secret")))
+	router := gin.New()
+	routes.SetupRoutes(router, handler)
+	return &testAPI{router: router, store: store, handler: handler, mail: mail}
+}
+
+// do sends a request with an optional bearer token and JSON body, and
+// decodes a JSON response into out when it is not nil.
+func (a *testAPI) do(t *testing.T, method, path, token string, body any, out any) *httptest.ResponseRecorder {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, req)
+	if out != nil && rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec
+}
+
+// addUser stores a verified account with role.
+func (a *testAPI) addUser(t *testing.T, email, role string) *models.Student {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	user := &models.Student{
+		Name:          "Test User",
+		Email:         email,
+		Password:      string(hash),
+		Role:          role,
+		EmailVerified: true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := a.store.Students.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+type tokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (a *testAPI) login(t *testing.T, email string) tokens {
+	t.Helper()
+	var out tokens
+	rec := a.do(t, http.MethodPost, "/api/students/login", "", gin.H{"email": email, "password": testPassword}, &out)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login as %s = %d %s", email, rec.Code, rec.Body)
+	}
+	return out
+}
+
+// addCourse stores a course with no fees or entry requirements and offers
+// it in an open cycle.
+func (a *testAPI) addCourse(t *testing.T, seats int) *models.Course {
+	t.Helper()
+	ctx := context.Background()
+	course := &models.Course{Name: "Physics", Seats: seats}
+	if err := a.store.Courses.Create(ctx, course); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cycle := &models.AdmissionCycle{
+		Code:     "cycle-" + course.ID.Hex(),
+		Name:     "Test intake",
+		OpensAt:  now.Add(-time.Hour),
+		ClosesAt: now.Add(time.Hour),
+		Courses:  []models.CycleCourse{{CourseID: course.ID}},
+	}
+	if err := a.store.Cycles.Create(ctx, cycle); err != nil {
+		t.Fatal(err)
+	}
+	return course
+}
+
+// upload stores a small PDF of kind for the caller and returns its ID.
+func (a *testAPI) upload(t *testing.T, token, kind string) string {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("kind", kind)
+	file, _ := form.CreateFormFile("file", kind+".pdf")
+	file.Write([]byte("%PDF-1.4\n%%EOF\n"))
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/documents", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, req)
+	var document models.Document
+	if rec.Code != http.StatusCreated || json.Unmarshal(rec.Body.Bytes(), &document) != nil {
+		t.Fatalf("upload = %d %s", rec.Code, rec.Body)
+	}
+	return document.ID.Hex()
+}
+
+// application is a complete application to course with the given photo
+// and ID proof.
+func application(course *models.Course, photo, idProof string) gin.H {
+	return gin.H{
+		"courseId": course.ID.Hex(),
+		"personalDetails": gin.H{
+			"firstName":   "Test",
+			"lastName":    "Student",
+			"email":       "student@example.com",
+			"phone":       "+91 98765 43210",
+			"dateOfBirth": "2005-01-02",
+		},
+		"academicDetails": gin.H{
+			"highestQualification": "Higher Secondary",
+			"institution":          "Test School",
+			"yearOfCompletion":     2023,
+			"percentage":           80,
+		},
+		"documents": gin.H{"photo": photo, "idProof": idProof},
+	}
+}
+
+func (a *testAPI) document(t *testing.T, id string) *models.Document {
+	t.Helper()
+	objectID, _ := primitive.ObjectIDFromHex(id)
+	document, err := a.store.Documents.FindByID(context.Background(), objectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return document
+}
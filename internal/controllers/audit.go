@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/query"
+)
+
+var auditListSpec = query.Spec{
+	Filters: []query.Filter{
+		{Param: "actorId", Field: "actorId", Type: query.ObjectID, Op: query.OpEq},
+		{Param: "action", Field: "action", Type: query.String, Op: query.OpIn},
+		{Param: "targetType", Field: "targetType", Type: query.String, Op: query.OpEq},
+		{Param: "targetId", Field: "targetId", Type: query.String, Op: query.OpEq},
+		{Param: "requestId", Field: "requestId", Type: query.String, Op: query.OpEq},
+		{Param: "from", Field: "createdAt", Type: query.Time, Op: query.OpGte},
+		{Param: "to", Field: "createdAt", Type: query.Time, Op: query.OpLte},
+	},
+	Sorts: map[string]string{
+		"sequence":  "_id",
+		"createdAt": "createdAt",
+	},
+	DefaultSort: "-sequence",
+}
+
+// audit records a privileged write made by the caller. The write itself
+// has already happened, but one that cannot be recorded must not look like
+// a success, so on failure audit answers 500 and returns false.
+func (h *Handler) audit(c *gin.Context, action, targetType string, targetID primitive.ObjectID, before, after any) bool {
+	actorID, _ := primitive.ObjectIDFromHex(c.GetString("userID"))
+	entry := audit.Entry{
+		ActorID:    actorID,
+		ActorRole:  c.GetString("role"),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID.Hex(),
+		Before:     before,
+		After:      after,
+		IP:         c.ClientIP(),
+		RequestID:  c.GetString("requestID"),
+	}
+	if err := h.Audit.Record(c.Request.Context(), entry); err != nil {
+		log.Printf("Error recording audit event %s on %s %s: %v", action, targetType, entry.TargetID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "The change was saved but could not be recorded in the audit log"})
+		return false
+	}
+	return true
+}
+
+func (h *Handler) ListAuditEvents(c *gin.Context) {
+	opts, ok := parseListQuery(c, auditListSpec)
+	if !ok {
+		return
+	}
+
+	events, err := h.Audit.Events.List(c.Request.Context(), opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching audit events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// VerifyAuditLog checks the audit hash chain from the first event onwards.
+func (h *Handler) VerifyAuditLog(c *gin.Context) {
+	result, err := h.Audit.Verify(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while verifying audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
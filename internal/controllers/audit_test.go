@@ -0,0 +1,74 @@
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/repositories"
+)
+
+func TestPrivilegedWritesAreAudited(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.addUser(t, "admin@example.com", rbac.RoleSuperAdmin)
+	session := api.login(t, "admin@example.com")
+
+	var course models.Course
+	if rec := api.do(t, http.MethodPost, "/api/courses", session.Token, gin.H{"name": "Physics", "seats": 30}, &course); rec.Code != http.StatusCreated {
+		t.Fatalf("creating a course = %d %s", rec.Code, rec.Body)
+	}
+	var events query.Page[models.AuditEvent]
+	if rec := api.do(t, http.MethodGet, "/api/admin/audit?targetId="+course.ID.Hex(), session.Token, nil, &events); rec.Code != http.StatusOK {
+		t.Fatalf("listing audit events = %d %s", rec.Code, rec.Body)
+	}
+	if len(events.Items) != 1 {
+		t.Fatalf("%d audit events for the course, want 1", len(events.Items))
+	}
+	if event := events.Items[0]; event.Action != audit.ActionCourseCreate || event.ActorID != admin.ID || event.ActorRole != rbac.RoleSuperAdmin {
+		t.Errorf("audit event = %+v", event)
+	}
+}
+
+// unavailableLog refuses every event, as does unavailableQueue.
+type unavailableLog struct {
+	repositories.AuditRepository
+}
+
+func (unavailableLog) Last(ctx context.Context) (*models.AuditEvent, error) {
+	return nil, errors.New("connection reset")
+}
+
+type unavailableQueue struct {
+	repositories.AuditQueueRepository
+}
+
+func (unavailableQueue) Add(ctx context.Context, pending *models.PendingAuditEvent) error {
+	return errors.New("connection reset")
+}
+
+func TestUnrecordedWriteIsNotReportedAsSuccess(t *testing.T) {
+	api := newTestAPI(t)
+	api.addUser(t, "admin@example.com", rbac.RoleSuperAdmin)
+	session := api.login(t, "admin@example.com")
+
+	// The log being down is not a failure on its own; the event waits in the queue
+	api.handler.Audit = audit.NewRecorder(unavailableLog{api.store.Audit}, api.store.AuditQueue)
+	if rec := api.do(t, http.MethodPost, "/api/courses", session.Token, gin.H{"name": "Physics", "seats": 30}, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("creating a course with the log down = %d %s, want 201", rec.Code, rec.Body)
+	}
+	if pending, _ := api.store.AuditQueue.Oldest(context.Background(), 10); len(pending) != 1 {
+		t.Fatalf("%d events queued, want 1", len(pending))
+	}
+
+	api.handler.Audit = audit.NewRecorder(unavailableLog{api.store.Audit}, unavailableQueue{})
+	if rec := api.do(t, http.MethodPost, "/api/courses", session.Token, gin.H{"name": "Chemistry", "seats": 30}, nil); rec.Code != http.StatusInternalServerError {
+		t.Errorf("creating a course that cannot be audited = %d %s, want 500", rec.Code, rec.Body)
+	}
+}
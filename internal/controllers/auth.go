@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/auth"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/repositories"
+)
+
+// startSession creates a session for the student and returns the token pair
+// to send back to the client.
+func (h *Handler) startSession(c *gin.Context, student *models.Student) (gin.H, error) {
+	now := time.Now()
+	session := models.Session{
+		ID:                  primitive.NewObjectID(),
+		UserID:              student.ID,
+		PreviousTokenHashes: []string{},
+		UserAgent:           c.Request.UserAgent(),
+		IP:                  c.ClientIP(),
+		CreatedAt:           now,
+		LastUsedAt:          now,
+		ExpiresAt:           now.Add(auth.RefreshTokenTTL),
+	}
+	refreshToken, hash, err := auth.NewRefreshToken(session.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	session.RefreshTokenHash = hash
+
+	if err := h.Sessions.Create(c.Request.Context(), &session); err != nil {
+		return nil, err
+	}
+	return tokenResponse(student, session.ID.Hex(), refreshToken)
+}
+
+func tokenResponse(student *models.Student, sessionID, refreshToken string) (gin.H, error) {
+	accessToken, expiresAt, err := auth.IssueAccessToken(auth.Claims{
+		UserID:    student.ID.Hex(),
+		Role:      student.Role,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"token":        accessToken,
+		"expiresAt":    expiresAt,
+		"refreshToken": refreshToken,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. Each refresh token can be used once; presenting one that has
+// already been rotated away is treated as theft and revokes the session.
+func (h *Handler) Refresh(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invalid := func() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+	}
+
+	sessionHex, hash, err := auth.SplitRefreshToken(req.RefreshToken)
+	if err != nil {
+		invalid()
+		return
+	}
+	sessionID, err := primitive.ObjectIDFromHex(sessionHex)
+	if err != nil {
+		invalid()
+		return
+	}
+
+	ctx := c.Request.Context()
+	session, err := h.Sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		invalid()
+		return
+	}
+	now := time.Now()
+	if !session.Active(now) {
+		invalid()
+		return
+	}
+	for _, previous := range session.PreviousTokenHashes {
+		if previous == hash {
+			h.revokeForReuse(c, session)
+			invalid()
+			return
+		}
+	}
+	if session.RefreshTokenHash != hash {
+		invalid()
+		return
+	}
+
+	student, err := h.Students.FindByID(ctx, session.UserID)
+	if err != nil {
+		invalid()
+		return
+	}
+
+	refreshToken, newHash, err := auth.NewRefreshToken(session.ID.Hex())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while generating token"})
+		return
+	}
+	if err := h.Sessions.Rotate(ctx, session.ID, hash, newHash, now); err != nil {
+		if errors.Is(err, repositories.ErrConflict) {
+			// Another request rotated this token first
+			h.revokeForReuse(c, session)
+			invalid()
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while refreshing session"})
+		return
+	}
+
+	response, err := tokenResponse(student, session.ID.Hex(), refreshToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while generating token"})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) revokeForReuse(c *gin.Context, session *models.Session) {
+	log.Printf("Security: refresh token reuse detected. SessionID: %s, UserID: %s, IP: %s", session.ID.Hex(), session.UserID.Hex(), c.ClientIP())
+	if err := h.Sessions.Revoke(c.Request.Context(), session.ID, "refresh_token_reuse"); err != nil {
+		log.Printf("Error revoking session %s: %v", session.ID.Hex(), err)
+	}
+}
+
+// Logout revokes the session the request was made with.
+func (h *Handler) Logout(c *gin.Context) {
+	sessionHex, _ := c.Get("sessionID")
+	sessionID, err := primitive.ObjectIDFromHex(sessionHex.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	if err := h.Sessions.Revoke(c.Request.Context(), sessionID, "logout"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while logging out"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// LogoutAll revokes every session belonging to the caller, signing them out
+// on all devices.
+func (h *Handler) LogoutAll(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	objectID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.Sessions.RevokeAllForUser(c.Request.Context(), objectID, "logout_all"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while logging out"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out from all devices"})
+}
+
+// JWKS publishes the public keys tokens are signed with, so other services
+// can verify them. Keys kept to verify older tokens during a rotation are
+// listed until they leave the keyring.
+func (h *Handler) JWKS(c *gin.Context) {
+	keys := auth.CurrentKeyring()
+	if keys == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "No signing keys configured"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": keys.JWKS()})
+}
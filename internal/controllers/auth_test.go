@@ -0,0 +1,67 @@
+package controllers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"admission-portal-backend/internal/rbac"
+)
+
+func TestRefreshRotatesAndDetectsReuse(t *testing.T) {
+	api := newTestAPI(t)
+	api.addUser(t, "student@example.com", rbac.RoleStudent)
+	first := api.login(t, "student@example.com")
+
+	var second tokens
+	if rec := api.do(t, http.MethodPost, "/api/auth/refresh", "", gin.H{"refreshToken": first.RefreshToken}, &second); rec.Code != http.StatusOK {
+		t.Fatalf("refresh = %d %s", rec.Code, rec.Body)
+	}
+	if second.RefreshToken == first.RefreshToken || second.Token == "" {
+		t.Fatal("refresh did not issue new tokens")
+	}
+	if rec := api.do(t, http.MethodGet, "/api/students/me", second.Token, nil, nil); rec.Code != http.StatusOK {
+		t.Fatalf("profile with the refreshed token = %d", rec.Code)
+	}
+
+	// Replaying the spent token means it was copied: the session ends
+	if rec := api.do(t, http.MethodPost, "/api/auth/refresh", "", gin.H{"refreshToken": first.RefreshToken}, nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed refresh = %d, want 401", rec.Code)
+	}
+	if rec := api.do(t, http.MethodPost, "/api/auth/refresh", "", gin.H{"refreshToken": second.RefreshToken}, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after reuse = %d, want 401", rec.Code)
+	}
+	if rec := api.do(t, http.MethodGet, "/api/students/me", second.Token, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("access token of a revoked session = %d, want 401", rec.Code)
+	}
+}
+
+func TestRefreshRejectsMalformedTokens(t *testing.T) {
+	api := newTestAPI(t)
+	for _, token := range []string{"", "garbage", "0123456789abcdef01234567.nothing"} {
+		rec := api.do(t, http.MethodPost, "/api/auth/refresh", "", gin.H{"refreshToken": token}, nil)
+		if rec.Code != http.StatusUnauthorized && rec.Code != http.StatusBadRequest {
+			t.Errorf("refresh with %q = %d", token, rec.Code)
+		}
+	}
+}
+
+func TestLogoutAllEndsEverySession(t *testing.T) {
+	api := newTestAPI(t)
+	api.addUser(t, "student@example.com", rbac.RoleStudent)
+	phone := api.login(t, "student@example.com")
+	laptop := api.login(t, "student@example.com")
+
+	if rec := api.do(t, http.MethodPost, "/api/auth/logout-all", phone.Token, nil, nil); rec.Code != http.StatusOK {
+		t.Fatalf("logout-all = %d %s", rec.Code, rec.Body)
+	}
+	for _, session := range []tokens{phone, laptop} {
+		if rec := api.do(t, http.MethodGet, "/api/students/me", session.Token, nil, nil); rec.Code != http.StatusUnauthorized {
+			t.Errorf("access token after logout-all = %d, want 401", rec.Code)
+		}
+		if rec := api.do(t, http.MethodPost, "/api/auth/refresh", "", gin.H{"refreshToken": session.RefreshToken}, nil); rec.Code != http.StatusUnauthorized {
+			t.Errorf("refresh after logout-all = %d, want 401", rec.Code)
+		}
+	}
+}
@@ -2,62 +2,86 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
 
-	"admission-portal-backend/internal/config"
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/eligibility"
 	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/quota"
+	"admission-portal-backend/internal/repositories"
 )
 
-func CreateCourse(c *gin.Context) {
+func (h *Handler) CreateCourse(c *gin.Context) {
 	var course models.Course
 	if err := c.ShouldBindJSON(&course); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Seats are only taken through admission offers
+	course.SeatsFilled = 0
+
 	// Set timestamps
 	course.CreatedAt = time.Now()
 	course.UpdatedAt = time.Now()
 
-	// Insert into database
-	collection := config.GetCollection("courses")
-	result, err := collection.InsertOne(context.Background(), course)
-	if err != nil {
+	if err := h.Courses.Create(c.Request.Context(), &course); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating course"})
 		return
 	}
+	if !h.audit(c, audit.ActionCourseCreate, audit.TargetCourse, course.ID, nil, course) {
+		return
+	}
 
-	course.ID = result.InsertedID.(primitive.ObjectID)
 	c.JSON(http.StatusCreated, course)
 }
 
-func GetCourses(c *gin.Context) {
-	collection := config.GetCollection("courses")
-	findOptions := options.Find()
+func (h *Handler) GetCourses(c *gin.Context) {
+	opts, ok := parseListQuery(c, courseListSpec)
+	if !ok {
+		return
+	}
 
-	cursor, err := collection.Find(context.Background(), bson.M{}, findOptions)
+	// ?cycle= lists only the courses offered in that cycle, with their seats
+	// and seat matrix in it
+	var cycle *models.AdmissionCycle
+	if code := c.Query("cycle"); code != "" {
+		if cycle, ok = h.findCycle(c, c.Request.Context(), code); !ok {
+			return
+		}
+		courseIDs := []any{}
+		for _, entry := range cycle.Courses {
+			courseIDs = append(courseIDs, entry.CourseID)
+		}
+		opts = opts.Where("_id", query.OpIn, courseIDs)
+	}
+
+	courses, err := h.Courses.List(c.Request.Context(), opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching courses"})
 		return
 	}
-	defer cursor.Close(context.Background())
-
-	var courses []models.Course
-	if err = cursor.All(context.Background(), &courses); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while decoding courses"})
-		return
+	for i := range courses.Items {
+		course := &courses.Items[i]
+		if cycle != nil {
+			if err := h.applyCycleSeats(c.Request.Context(), cycle, course); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching courses"})
+				return
+			}
+		}
+		course.SeatsRemaining = course.RemainingSeats()
 	}
 
 	c.JSON(http.StatusOK, courses)
 }
 
-func GetCourse(c *gin.Context) {
+func (h *Handler) GetCourse(c *gin.Context) {
 	id := c.Param("id")
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -65,18 +89,46 @@ func GetCourse(c *gin.Context) {
 		return
 	}
 
-	collection := config.GetCollection("courses")
-	var course models.Course
-	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&course)
+	course, err := h.Courses.FindByID(c.Request.Context(), objectID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
 		return
 	}
+	if code := c.Query("cycle"); code != "" {
+		cycle, ok := h.findCycle(c, c.Request.Context(), code)
+		if !ok {
+			return
+		}
+		if _, offered := cycle.Course(objectID); !offered {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course is not offered in " + cycle.Name})
+			return
+		}
+		if err := h.applyCycleSeats(c.Request.Context(), cycle, course); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching course"})
+			return
+		}
+	}
+	course.SeatsRemaining = course.RemainingSeats()
 
 	c.JSON(http.StatusOK, course)
 }
 
-func UpdateCourse(c *gin.Context) {
+// applyCycleSeats replaces course's seat counts with those of cycle and
+// attaches its seat matrix there.
+func (h *Handler) applyCycleSeats(ctx context.Context, cycle *models.AdmissionCycle, course *models.Course) error {
+	filled, err := h.CycleSeats.Filled(ctx, cycle.Code, course.ID)
+	if err != nil {
+		return err
+	}
+	course.SeatMatrix = quota.Matrix(cycle, course, filled, time.Now())
+	course.Seats, course.SeatsFilled = cycle.SeatsFor(course), 0
+	for _, row := range course.SeatMatrix {
+		course.SeatsFilled += row.Filled
+	}
+	return nil
+}
+
+func (h *Handler) UpdateCourse(c *gin.Context) {
 	id := c.Param("id")
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -89,40 +141,39 @@ func UpdateCourse(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	course.ID = objectID
+	course.UpdatedAt = time.Now()
 
-	update := bson.M{
-		"$set": bson.M{
-			"name":                course.Name,
-			"description":         course.Description,
-			"duration":            course.Duration,
-			"seats":               course.Seats,
-			"eligibilityCriteria": course.EligibilityCriteria,
-			"fees":                course.Fees,
-			"updated_at":          time.Now(),
-		},
-	}
-
-	collection := config.GetCollection("courses")
-	result, err := collection.UpdateOne(
-		context.Background(),
-		bson.M{"_id": objectID},
-		update,
-	)
-
+	before, err := h.Courses.FindByID(c.Request.Context(), objectID)
 	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while updating course"})
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+	if err := h.Courses.Update(c.Request.Context(), &course); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while updating course"})
+		return
+	}
+	after, err := h.Courses.FindByID(c.Request.Context(), objectID)
+	if err != nil {
+		after = &course
+	}
+	if !h.audit(c, audit.ActionCourseUpdate, audit.TargetCourse, objectID, before, after) {
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Course updated successfully"})
 }
 
-func DeleteCourse(c *gin.Context) {
+func (h *Handler) DeleteCourse(c *gin.Context) {
 	id := c.Param("id")
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -130,17 +181,52 @@ func DeleteCourse(c *gin.Context) {
 		return
 	}
 
-	collection := config.GetCollection("courses")
-	result, err := collection.DeleteOne(context.Background(), bson.M{"_id": objectID})
+	before, err := h.Courses.FindByID(c.Request.Context(), objectID)
 	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting course"})
 		return
 	}
 
-	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+	if err := h.Courses.Delete(c.Request.Context(), objectID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while deleting course"})
+		return
+	}
+	if !h.audit(c, audit.ActionCourseDelete, audit.TargetCourse, objectID, before, nil) {
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Course deleted successfully"})
 }
+
+// CheckEligibility is a dry run of the eligibility rules for a course, so
+// students can see what they are missing before applying.
+func (h *Handler) CheckEligibility(c *gin.Context) {
+	id := c.Param("id")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	var details models.AcademicDetails
+	if err := c.ShouldBindJSON(&details); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	course, err := h.Courses.FindByID(c.Request.Context(), objectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, eligibility.Evaluate(course.EligibilityCriteria, details))
+}
@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/quota"
+	"admission-portal-backend/internal/repositories"
+)
+
+// cycleCodePattern keeps cycle codes usable in URLs and query strings.
+var cycleCodePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// cycleRequest is the editable part of an admission cycle.
+type cycleRequest struct {
+	Name           string                  `json:"name" binding:"required"`
+	OpensAt        time.Time               `json:"opensAt" binding:"required"`
+	ClosesAt       time.Time               `json:"closesAt" binding:"required"`
+	LateSubmission *models.LateSubmission  `json:"lateSubmission"`
+	Courses        []models.CycleCourse    `json:"courses" binding:"dive"`
+	Conversions    []models.SeatConversion `json:"conversions" binding:"dive"`
+}
+
+// validateCycleRequest checks the dates, courses and seat quotas of a cycle,
+// answering 400 or 500 itself when they are unusable. Quota categories are
+// normalized in place.
+func (h *Handler) validateCycleRequest(c *gin.Context, req *cycleRequest) bool {
+	if !req.ClosesAt.After(req.OpensAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "closesAt must be after opensAt"})
+		return false
+	}
+	if req.LateSubmission != nil && !req.LateSubmission.Until.After(req.ClosesAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lateSubmission.until must be after closesAt"})
+		return false
+	}
+
+	if err := quota.ValidateConversions(req.Conversions); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	for i := range req.Conversions {
+		req.Conversions[i].From = quota.Normalize(req.Conversions[i].From)
+		req.Conversions[i].To = quota.Normalize(req.Conversions[i].To)
+	}
+
+	seen := map[string]bool{}
+	for i := range req.Courses {
+		entry := &req.Courses[i]
+		if seen[entry.CourseID.Hex()] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Course listed more than once: " + entry.CourseID.Hex()})
+			return false
+		}
+		seen[entry.CourseID.Hex()] = true
+
+		course, err := h.Courses.FindByID(c.Request.Context(), entry.CourseID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown course: " + entry.CourseID.Hex()})
+				return false
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while saving admission cycle"})
+			return false
+		}
+		seats := course.Seats
+		if entry.Seats != nil {
+			seats = *entry.Seats
+		}
+		if err := quota.ValidateQuotas(seats, entry.Quotas); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Course " + entry.CourseID.Hex() + ": " + err.Error()})
+			return false
+		}
+		for j := range entry.Quotas {
+			entry.Quotas[j].Category = quota.Normalize(entry.Quotas[j].Category)
+		}
+	}
+	return true
+}
+
+// findCycle loads the cycle with the given code, answering 404 or 500
+// itself when it cannot.
+func (h *Handler) findCycle(c *gin.Context, ctx context.Context, code string) (*models.AdmissionCycle, bool) {
+	cycle, err := h.Cycles.FindByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Admission cycle not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching admission cycle"})
+		return nil, false
+	}
+	cycle.CurrentStatus = cycle.Status(time.Now())
+	return cycle, true
+}
+
+func (h *Handler) CreateCycle(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+		cycleRequest
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	if !cycleCodePattern.MatchString(code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cycle code may only contain lowercase letters, digits and hyphens"})
+		return
+	}
+	if !h.validateCycleRequest(c, &req.cycleRequest) {
+		return
+	}
+
+	now := time.Now()
+	cycle := models.AdmissionCycle{
+		Code:           code,
+		Name:           req.Name,
+		OpensAt:        req.OpensAt,
+		ClosesAt:       req.ClosesAt,
+		LateSubmission: req.LateSubmission,
+		Courses:        req.Courses,
+		Conversions:    req.Conversions,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if cycle.Courses == nil {
+		cycle.Courses = []models.CycleCourse{}
+	}
+
+	if err := h.Cycles.Create(c.Request.Context(), &cycle); err != nil {
+		if errors.Is(err, repositories.ErrDuplicate) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An admission cycle with this code already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating admission cycle"})
+		return
+	}
+	if !h.audit(c, audit.ActionCycleCreate, audit.TargetCycle, cycle.ID, nil, cycle) {
+		return
+	}
+	cycle.CurrentStatus = cycle.Status(now)
+
+	c.JSON(http.StatusCreated, cycle)
+}
+
+func (h *Handler) UpdateCycle(c *gin.Context) {
+	var req cycleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	before, ok := h.findCycle(c, c.Request.Context(), c.Param("code"))
+	if !ok {
+		return
+	}
+	if !h.validateCycleRequest(c, &req) {
+		return
+	}
+
+	cycle := *before
+	cycle.Name = req.Name
+	cycle.OpensAt = req.OpensAt
+	cycle.ClosesAt = req.ClosesAt
+	cycle.LateSubmission = req.LateSubmission
+	cycle.Courses = req.Courses
+	cycle.Conversions = req.Conversions
+	if cycle.Courses == nil {
+		cycle.Courses = []models.CycleCourse{}
+	}
+	cycle.UpdatedAt = time.Now()
+
+	if err := h.Cycles.Update(c.Request.Context(), &cycle); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Admission cycle not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while updating admission cycle"})
+		return
+	}
+	if !h.audit(c, audit.ActionCycleUpdate, audit.TargetCycle, cycle.ID, before, cycle) {
+		return
+	}
+	cycle.CurrentStatus = cycle.Status(cycle.UpdatedAt)
+
+	c.JSON(http.StatusOK, cycle)
+}
+
+func (h *Handler) GetCycles(c *gin.Context) {
+	opts, ok := parseListQuery(c, cycleListSpec)
+	if !ok {
+		return
+	}
+
+	cycles, err := h.Cycles.List(c.Request.Context(), opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching admission cycles"})
+		return
+	}
+	now := time.Now()
+	for i := range cycles.Items {
+		cycles.Items[i].CurrentStatus = cycles.Items[i].Status(now)
+	}
+
+	c.JSON(http.StatusOK, cycles)
+}
+
+func (h *Handler) GetCycle(c *gin.Context) {
+	cycle, ok := h.findCycle(c, c.Request.Context(), c.Param("code"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, cycle)
+}
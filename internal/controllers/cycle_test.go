@@ -0,0 +1,86 @@
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/rbac"
+)
+
+func TestCreateCycle(t *testing.T) {
+	api := newTestAPI(t)
+	api.addUser(t, "officer@example.com", rbac.RoleAdmissionsOfficer)
+	session := api.login(t, "officer@example.com")
+	course := &models.Course{Name: "Physics", Seats: 10}
+	if err := api.store.Courses.Create(context.Background(), course); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	cycle := func(code string, opens, closes time.Time) gin.H {
+		return gin.H{
+			"code":     code,
+			"name":     "Fall intake",
+			"opensAt":  opens,
+			"closesAt": closes,
+			"courses":  []gin.H{{"courseId": course.ID.Hex()}},
+		}
+	}
+
+	var created models.AdmissionCycle
+	if rec := api.do(t, http.MethodPost, "/api/admin/cycles", session.Token, cycle(" Fall-2025 ", now.Add(time.Hour), now.Add(48*time.Hour)), &created); rec.Code != http.StatusCreated {
+		t.Fatalf("creating a cycle = %d %s", rec.Code, rec.Body)
+	}
+	if created.Code != "fall-2025" || created.CurrentStatus != models.CycleUpcoming {
+		t.Errorf("created cycle %q with status %s, want fall-2025, upcoming", created.Code, created.CurrentStatus)
+	}
+
+	tests := []struct {
+		name string
+		body gin.H
+		want int
+	}{
+		{"same code", cycle("fall-2025", now, now.Add(time.Hour)), http.StatusConflict},
+		{"closes before it opens", cycle("spring-2026", now, now.Add(-time.Hour)), http.StatusBadRequest},
+		{"unusable code", cycle("spring 2026", now, now.Add(time.Hour)), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := api.do(t, http.MethodPost, "/api/admin/cycles", session.Token, tt.body, nil); rec.Code != tt.want {
+			t.Errorf("%s: = %d %s, want %d", tt.name, rec.Code, rec.Body, tt.want)
+		}
+	}
+}
+
+func TestApplicationsOnlyWhileCycleIsOpen(t *testing.T) {
+	api := newTestAPI(t)
+	api.addUser(t, "student@example.com", rbac.RoleStudent)
+	session := api.login(t, "student@example.com")
+	course := &models.Course{Name: "Physics", Seats: 10}
+	if err := api.store.Courses.Create(context.Background(), course); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	upcoming := &models.AdmissionCycle{
+		Code:     "spring-2026",
+		Name:     "Spring 2026",
+		OpensAt:  now.Add(time.Hour),
+		ClosesAt: now.Add(48 * time.Hour),
+		Courses:  []models.CycleCourse{{CourseID: course.ID}},
+	}
+	if err := api.store.Cycles.Create(context.Background(), upcoming); err != nil {
+		t.Fatal(err)
+	}
+
+	body := application(course, api.upload(t, session.Token, models.DocumentKindPhoto), api.upload(t, session.Token, models.DocumentKindIDProof))
+	if rec := api.do(t, http.MethodPost, "/api/admissions", session.Token, body, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("applying with no open cycle = %d %s, want 403", rec.Code, rec.Body)
+	}
+	body["cycle"] = upcoming.Code
+	if rec := api.do(t, http.MethodPost, "/api/admissions", session.Token, body, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("applying before the cycle opens = %d %s, want 403", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,372 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/repositories"
+)
+
+const defaultMaxUploadBytes = 5 << 20
+
+// allowedDocumentTypes are the MIME types accepted for uploads, as detected
+// from the file contents rather than the name or the client's header.
+var allowedDocumentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+var documentKinds = map[string]bool{
+	models.DocumentKindPhoto:                    true,
+	models.DocumentKindIDProof:                  true,
+	models.DocumentKindAddressProof:             true,
+	models.DocumentKindQualificationCertificate: true,
+	models.DocumentKindAcademicRecord:           true,
+	models.DocumentKindCategoryCertificate:      true,
+}
+
+func maxUploadBytes() int64 {
+	if n, err := strconv.ParseInt(os.Getenv("MAX_UPLOAD_BYTES"), 10, 64); err == nil && n > 0 {
+		return n
+	}
+	return defaultMaxUploadBytes
+}
+
+// MaxRequestBytes is the largest request body the API accepts: an upload of
+// MAX_UPLOAD_BYTES with some room for the multipart framing around it.
+func MaxRequestBytes() int64 {
+	return maxUploadBytes() + 1<<20
+}
+
+func (h *Handler) UploadDocument(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	ownerID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	limit := maxUploadBytes()
+	tooLarge := gin.H{"error": fmt.Sprintf("File is larger than the %d byte limit", limit)}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxRequestBytes())
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, tooLarge)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required in the 'file' field"})
+		return
+	}
+	if fileHeader.Size > limit {
+		c.JSON(http.StatusRequestEntityTooLarge, tooLarge)
+		return
+	}
+
+	kind := c.PostForm("kind")
+	if !documentKinds[kind] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document kind"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var admissionID *primitive.ObjectID
+	if raw := c.PostForm("admissionId"); raw != "" {
+		id, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admission ID"})
+			return
+		}
+		admission, err := h.Admissions.FindByID(ctx, id)
+		if err != nil || admission.StudentID != ownerID {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
+			return
+		}
+		admissionID = &id
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Error while reading file"})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Error while reading file"})
+		return
+	}
+	if int64(len(data)) > limit {
+		c.JSON(http.StatusRequestEntityTooLarge, tooLarge)
+		return
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if !allowedDocumentTypes[contentType] {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Only PDF, JPEG and PNG files are accepted"})
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	document := models.Document{
+		ID:          primitive.NewObjectID(),
+		OwnerID:     ownerID,
+		AdmissionID: admissionID,
+		Kind:        kind,
+		FileName:    filepath.Base(fileHeader.Filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Checksum:    hex.EncodeToString(sum[:]),
+		CreatedAt:   time.Now(),
+	}
+	document.StorageKey = "documents/" + ownerID.Hex() + "/" + document.ID.Hex()
+
+	if err := h.Blobs.Put(ctx, document.StorageKey, bytes.NewReader(data), document.Size, contentType); err != nil {
+		log.Printf("Error storing document %s: %v", document.ID.Hex(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while storing document"})
+		return
+	}
+	if err := h.Documents.Create(ctx, &document); err != nil {
+		_ = h.Blobs.Delete(ctx, document.StorageKey)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while storing document"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, document)
+}
+
+// ListDocuments returns the caller's documents, or the documents of one
+// admission when admissionId is given. Staff who can read admissions may list any admission.
+func (h *Handler) ListDocuments(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	callerID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	role := c.GetString("role")
+
+	ctx := c.Request.Context()
+	var documents []models.Document
+	if raw := c.Query("admissionId"); raw != "" {
+		admissionID, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admission ID"})
+			return
+		}
+		admission, err := h.Admissions.FindByID(ctx, admissionID)
+		if err != nil || !canSeeAdmission(admission, callerID.Hex(), role) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
+			return
+		}
+		documents, err = h.Documents.ListByAdmission(ctx, admissionID)
+	} else {
+		documents, err = h.Documents.ListByOwner(ctx, callerID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching documents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, documents)
+}
+
+// findAccessibleDocument loads a document the caller may see: their own, or
+// for staff who can read admissions, one attached to an application that
+// has been submitted. Drafts and unattached uploads stay private.
+func (h *Handler) findAccessibleDocument(c *gin.Context) (*models.Document, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return nil, false
+	}
+	ctx := c.Request.Context()
+
+	document, err := h.Documents.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching document"})
+		return nil, false
+	}
+	if document.OwnerID.Hex() == c.GetString("userID") {
+		return document, true
+	}
+	if !rbac.Can(c.GetString("role"), rbac.AdmissionsRead) || document.AdmissionID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return nil, false
+	}
+	admission, err := h.Admissions.FindByID(ctx, *document.AdmissionID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching document"})
+		return nil, false
+	}
+	if err != nil || !canSeeAdmission(admission, c.GetString("userID"), c.GetString("role")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return nil, false
+	}
+	return document, true
+}
+
+func (h *Handler) GetDocument(c *gin.Context) {
+	document, ok := h.findAccessibleDocument(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, document)
+}
+
+func (h *Handler) DownloadDocument(c *gin.Context) {
+	document, ok := h.findAccessibleDocument(c)
+	if !ok {
+		return
+	}
+
+	blob, err := h.Blobs.Get(c.Request.Context(), document.StorageKey)
+	if err != nil {
+		log.Printf("Error reading document %s: %v", document.ID.Hex(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while reading document"})
+		return
+	}
+	defer blob.Close()
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": document.FileName}))
+	c.Header("X-Checksum-Sha256", document.Checksum)
+	c.DataFromReader(http.StatusOK, document.Size, document.ContentType, blob, nil)
+}
+
+// admissionDocumentRefs returns every document reference on an application.
+func admissionDocumentRefs(admission *models.Admission) []string {
+	refs := []string{}
+	for _, ref := range []string{admission.Documents.Photo, admission.Documents.IDProof, admission.Documents.AddressProof, admission.Documents.CategoryCertificate} {
+		if ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	refs = append(refs, admission.Documents.QualificationCertificates...)
+	refs = append(refs, admission.AcademicDetails.Documents...)
+	return refs
+}
+
+// hasDocumentKind reports whether ref is the ID of a document of the given
+// kind. Ownership is checked by checkDocumentRefs.
+func (h *Handler) hasDocumentKind(ctx context.Context, ref, kind string) bool {
+	id, err := primitive.ObjectIDFromHex(ref)
+	if err != nil {
+		return false
+	}
+	document, err := h.Documents.FindByID(ctx, id)
+	return err == nil && document.Kind == kind
+}
+
+// checkDocumentRefs verifies that every reference is the ID of a document
+// uploaded by the student and not linked to an admission other than
+// admissionID. It returns the offending reference on failure, with
+// ErrNotFound or ErrConflict.
+func (h *Handler) checkDocumentRefs(ctx context.Context, studentID, admissionID primitive.ObjectID, refs []string) (string, error) {
+	for _, ref := range refs {
+		id, err := primitive.ObjectIDFromHex(ref)
+		if err != nil {
+			return ref, repositories.ErrNotFound
+		}
+		document, err := h.Documents.FindByID(ctx, id)
+		if err != nil {
+			return ref, err
+		}
+		if document.OwnerID != studentID {
+			return ref, repositories.ErrNotFound
+		}
+		if document.AdmissionID != nil && *document.AdmissionID != admissionID {
+			return ref, repositories.ErrConflict
+		}
+	}
+	return "", nil
+}
+
+// attachDocuments links the referenced documents to the admission. Linking
+// a document twice is harmless, so a request that failed here can be
+// retried. It answers the request itself and returns false on failure.
+func (h *Handler) attachDocuments(c *gin.Context, admission *models.Admission) bool {
+	for _, ref := range admissionDocumentRefs(admission) {
+		id, err := primitive.ObjectIDFromHex(ref)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown document: " + ref})
+			return false
+		}
+		err = h.Documents.AttachToAdmission(c.Request.Context(), id, admission.StudentID, admission.ID)
+		switch {
+		case err == nil:
+		case errors.Is(err, repositories.ErrNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown document: " + ref})
+			return false
+		case errors.Is(err, repositories.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Document " + ref + " is already attached to another application"})
+			return false
+		default:
+			log.Printf("Error linking document %s to admission %s: %v", ref, admission.ID.Hex(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while attaching documents"})
+			return false
+		}
+	}
+	return true
+}
+
+// detachDocuments releases the documents attachDocuments linked to an
+// admission that was then never stored.
+func (h *Handler) detachDocuments(ctx context.Context, admission *models.Admission) {
+	for _, ref := range admissionDocumentRefs(admission) {
+		id, err := primitive.ObjectIDFromHex(ref)
+		if err != nil {
+			continue
+		}
+		if err := h.Documents.DetachFromAdmission(ctx, id, admission.ID); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+			log.Printf("Error unlinking document %s from admission %s: %v", ref, admission.ID.Hex(), err)
+		}
+	}
+}
+
+// createAdmission stores a new admission along with the links from its
+// documents. The documents are linked first, under an ID chosen up front,
+// and released again if the admission cannot be stored. failure is the
+// message for unexpected errors. It answers the request itself and returns
+// false on failure.
+func (h *Handler) createAdmission(c *gin.Context, admission *models.Admission, failure string) bool {
+	ctx := c.Request.Context()
+	admission.ID = primitive.NewObjectID()
+	if !h.attachDocuments(c, admission) {
+		h.detachDocuments(ctx, admission)
+		return false
+	}
+	if err := h.Admissions.Create(ctx, admission); err != nil {
+		h.detachDocuments(ctx, admission)
+		if errors.Is(err, repositories.ErrDuplicate) {
+			h.writeDuplicateApplication(c, admission)
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+		return false
+	}
+	return true
+}
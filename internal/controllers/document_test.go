@@ -0,0 +1,134 @@
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/workflow"
+)
+
+func TestApplicationLinksItsDocuments(t *testing.T) {
+	api := newTestAPI(t)
+	api.addUser(t, "student@example.com", rbac.RoleStudent)
+	session := api.login(t, "student@example.com")
+	physics, chemistry := api.addCourse(t, 10), api.addCourse(t, 10)
+	photo, idProof := api.upload(t, session.Token, models.DocumentKindPhoto), api.upload(t, session.Token, models.DocumentKindIDProof)
+
+	var admission models.Admission
+	if rec := api.do(t, http.MethodPost, "/api/admissions", session.Token, application(physics, photo, idProof), &admission); rec.Code != http.StatusCreated {
+		t.Fatalf("apply = %d %s", rec.Code, rec.Body)
+	}
+	for _, id := range []string{photo, idProof} {
+		if document := api.document(t, id); document.AdmissionID == nil || *document.AdmissionID != admission.ID {
+			t.Errorf("document %s linked to %v, want admission %s", id, document.AdmissionID, admission.ID.Hex())
+		}
+	}
+
+	// A document belongs to one application only
+	rec := api.do(t, http.MethodPost, "/api/admissions", session.Token, application(chemistry, photo, api.upload(t, session.Token, models.DocumentKindIDProof)), nil)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("reusing a document = %d %s, want 409", rec.Code, rec.Body)
+	}
+}
+
+// failingDocuments refuses to link documents with an unexpected error.
+type failingDocuments struct {
+	repositories.DocumentRepository
+}
+
+func (failingDocuments) AttachToAdmission(ctx context.Context, id, ownerID, admissionID primitive.ObjectID) error {
+	return errors.New("connection reset")
+}
+
+func TestApplicationFailsWhenDocumentsCannotBeLinked(t *testing.T) {
+	api := newTestAPI(t)
+	api.addUser(t, "student@example.com", rbac.RoleStudent)
+	session := api.login(t, "student@example.com")
+	course := api.addCourse(t, 10)
+	photo, idProof := api.upload(t, session.Token, models.DocumentKindPhoto), api.upload(t, session.Token, models.DocumentKindIDProof)
+
+	documents := api.handler.Documents
+	api.handler.Documents = failingDocuments{documents}
+	if rec := api.do(t, http.MethodPost, "/api/admissions", session.Token, application(course, photo, idProof), nil); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("apply while linking fails = %d %s, want 500", rec.Code, rec.Body)
+	}
+	page, err := api.store.Admissions.List(context.Background(), query.Options{})
+	if err != nil || page.Total != 0 {
+		t.Fatalf("%d admissions stored by the failed application, want 0", page.Total)
+	}
+
+	// Nothing was kept, so the application can simply be sent again
+	api.handler.Documents = documents
+	if rec := api.do(t, http.MethodPost, "/api/admissions", session.Token, application(course, photo, idProof), nil); rec.Code != http.StatusCreated {
+		t.Errorf("retried application = %d %s, want 201", rec.Code, rec.Body)
+	}
+}
+
+func TestFailedApplicationReleasesDocuments(t *testing.T) {
+	api := newTestAPI(t)
+	api.addUser(t, "student@example.com", rbac.RoleStudent)
+	session := api.login(t, "student@example.com")
+	course := api.addCourse(t, 10)
+	photo, idProof := api.upload(t, session.Token, models.DocumentKindPhoto), api.upload(t, session.Token, models.DocumentKindIDProof)
+	if rec := api.do(t, http.MethodPost, "/api/admissions", session.Token, application(course, photo, idProof), nil); rec.Code != http.StatusCreated {
+		t.Fatalf("apply = %d %s", rec.Code, rec.Body)
+	}
+
+	newPhoto, newIDProof := api.upload(t, session.Token, models.DocumentKindPhoto), api.upload(t, session.Token, models.DocumentKindIDProof)
+	if rec := api.do(t, http.MethodPost, "/api/admissions", session.Token, application(course, newPhoto, newIDProof), nil); rec.Code != http.StatusConflict {
+		t.Fatalf("applying twice = %d %s, want 409", rec.Code, rec.Body)
+	}
+	for _, id := range []string{newPhoto, newIDProof} {
+		if document := api.document(t, id); document.AdmissionID != nil {
+			t.Errorf("document %s still linked to %s after the application was refused", id, document.AdmissionID.Hex())
+		}
+	}
+}
+
+func TestStaffSeeDocumentsOnceSubmitted(t *testing.T) {
+	api := newTestAPI(t)
+	api.addUser(t, "student@example.com", rbac.RoleStudent)
+	api.addUser(t, "reviewer@example.com", rbac.RoleReviewer)
+	session, staff := api.login(t, "student@example.com"), api.login(t, "reviewer@example.com")
+	course := api.addCourse(t, 10)
+	photo, idProof := api.upload(t, session.Token, models.DocumentKindPhoto), api.upload(t, session.Token, models.DocumentKindIDProof)
+	loose := api.upload(t, session.Token, models.DocumentKindAddressProof)
+
+	var draft models.Admission
+	if rec := api.do(t, http.MethodPost, "/api/admissions/drafts", session.Token, application(course, photo, idProof), &draft); rec.Code != http.StatusCreated {
+		t.Fatalf("create draft = %d %s", rec.Code, rec.Body)
+	}
+	read := func(token, id string) int {
+		return api.do(t, http.MethodGet, "/api/documents/"+id, token, nil, nil).Code
+	}
+	for _, id := range []string{photo, loose} {
+		if code := read(staff.Token, id); code != http.StatusNotFound {
+			t.Errorf("staff reading document %s before submission = %d, want 404", id, code)
+		}
+		if code := read(session.Token, id); code != http.StatusOK {
+			t.Errorf("owner reading document %s = %d, want 200", id, code)
+		}
+	}
+
+	var submitted models.Admission
+	if rec := api.do(t, http.MethodPost, "/api/admissions/"+draft.ID.Hex()+"/submit", session.Token, nil, &submitted); rec.Code != http.StatusOK {
+		t.Fatalf("submit = %d %s", rec.Code, rec.Body)
+	}
+	if submitted.Status != workflow.StatusSubmitted || submitted.SubmittedAt == nil {
+		t.Errorf("submitted admission = status %s, submittedAt %v", submitted.Status, submitted.SubmittedAt)
+	}
+	if code := read(staff.Token, photo); code != http.StatusOK {
+		t.Errorf("staff reading a submitted document = %d, want 200", code)
+	}
+	if code := read(staff.Token, loose); code != http.StatusNotFound {
+		t.Errorf("staff reading an unattached document = %d, want 404", code)
+	}
+}
@@ -0,0 +1,383 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/quota"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/services"
+	"admission-portal-backend/internal/workflow"
+)
+
+// Application sections a draft is edited by.
+const (
+	sectionPersonal  = "personalDetails"
+	sectionAcademic  = "academicDetails"
+	sectionDocuments = "documents"
+)
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{5,18}[0-9]$`)
+
+// minCompletionYear is the earliest yearOfCompletion accepted.
+const minCompletionYear = 1950
+
+// fieldErrors maps a field, as "section.field", to what is wrong with it.
+type fieldErrors map[string]string
+
+// validatePersonalDetails checks the format of the personal details that are
+// filled in. With complete set, it also requires the fields every submitted
+// application needs.
+func validatePersonalDetails(details models.PersonalDetails, complete bool, errs fieldErrors) {
+	field := func(name string) string { return sectionPersonal + "." + name }
+	if details.Email != "" {
+		if address, err := mail.ParseAddress(details.Email); err != nil || address.Address != details.Email {
+			errs[field("email")] = "must be a valid email address"
+		}
+	}
+	if details.Phone != "" && !phonePattern.MatchString(details.Phone) {
+		errs[field("phone")] = "must be a phone number"
+	}
+	if details.DateOfBirth != "" {
+		if born, err := time.Parse("2006-01-02", details.DateOfBirth); err != nil {
+			errs[field("dateOfBirth")] = "must be a date in the form YYYY-MM-DD"
+		} else if !born.Before(time.Now()) {
+			errs[field("dateOfBirth")] = "must be in the past"
+		}
+	}
+	if !complete {
+		return
+	}
+	required := map[string]string{
+		"firstName":   details.FirstName,
+		"lastName":    details.LastName,
+		"email":       details.Email,
+		"phone":       details.Phone,
+		"dateOfBirth": details.DateOfBirth,
+	}
+	for name, value := range required {
+		if strings.TrimSpace(value) == "" {
+			errs[field(name)] = "is required"
+		}
+	}
+}
+
+// validateAcademicDetails checks the format of the academic details that are
+// filled in, and with complete set that the required ones are present.
+func validateAcademicDetails(details models.AcademicDetails, complete bool, errs fieldErrors) {
+	field := func(name string) string { return sectionAcademic + "." + name }
+	if details.Percentage < 0 || details.Percentage > 100 {
+		errs[field("percentage")] = "must be between 0 and 100"
+	}
+	if year := details.YearOfCompletion; year != 0 && (year < minCompletionYear || year > time.Now().Year()+1) {
+		errs[field("yearOfCompletion")] = "is not a plausible year"
+	}
+	if score := details.EntranceExamScore; score != nil && *score < 0 {
+		errs[field("entranceExamScore")] = "cannot be negative"
+	}
+	if !complete {
+		return
+	}
+	if strings.TrimSpace(details.HighestQualification) == "" {
+		errs[field("highestQualification")] = "is required"
+	}
+	if strings.TrimSpace(details.Institution) == "" {
+		errs[field("institution")] = "is required"
+	}
+	if details.YearOfCompletion == 0 {
+		errs[field("yearOfCompletion")] = "is required"
+	}
+}
+
+// validateDocuments requires the documents every submitted application
+// needs. Whether the references are valid is checked against the document
+// store by checkApplicationDocuments.
+func validateDocuments(documents models.Documents, complete bool, errs fieldErrors) {
+	if !complete {
+		return
+	}
+	if documents.Photo == "" {
+		errs[sectionDocuments+".photo"] = "is required"
+	}
+	if documents.IDProof == "" {
+		errs[sectionDocuments+".idProof"] = "is required"
+	}
+}
+
+// writeFieldErrors answers the request with 422 and returns false when errs
+// has any entries.
+func writeFieldErrors(c *gin.Context, message string, errs fieldErrors) bool {
+	if len(errs) == 0 {
+		return true
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{"error": message, "fields": errs})
+	return false
+}
+
+// canSeeAdmission reports whether the caller may see admission: students
+// their own applications, and staff who can read admissions any application
+// that is no longer a draft.
+func canSeeAdmission(admission *models.Admission, userID, role string) bool {
+	if admission.StudentID.Hex() == userID {
+		return true
+	}
+	return rbac.Can(role, rbac.AdmissionsRead) && workflow.Normalize(admission.Status) != workflow.StatusDraft
+}
+
+// findOwnDraft loads the admission in the id parameter if it belongs to the
+// caller and can still be edited, answering the request itself otherwise.
+func (h *Handler) findOwnDraft(c *gin.Context) (*models.Admission, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admission ID"})
+		return nil, false
+	}
+	admission, err := h.Admissions.FindByID(c.Request.Context(), id)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching admission"})
+		return nil, false
+	}
+	if err != nil || admission.StudentID.Hex() != c.GetString("userID") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
+		return nil, false
+	}
+	if workflow.Normalize(admission.Status) != workflow.StatusDraft || admission.SubmittedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "This application has been submitted and can no longer be edited"})
+		return nil, false
+	}
+	return admission, true
+}
+
+// saveDraft stores the draft's contents, answering the request itself and
+// returning false when that fails.
+func (h *Handler) saveDraft(c *gin.Context, admission *models.Admission) bool {
+	err := h.Admissions.UpdateDraft(c.Request.Context(), admission)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, repositories.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "This application has been submitted and can no longer be edited"})
+	case errors.Is(err, repositories.ErrDuplicate):
+		h.writeDuplicateApplication(c, admission)
+	case errors.Is(err, repositories.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while saving draft"})
+	}
+	return false
+}
+
+// CreateDraft starts an application the student fills in over several
+// requests. Only its courses and cycle are fixed up front; the sections may
+// be partly filled in and are checked for format only.
+func (h *Handler) CreateDraft(c *gin.Context) {
+	studentID, err := primitive.ObjectIDFromHex(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req applicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	courseIDs, ok := parseCourseChoices(c, req.CourseID, req.Preferences)
+	if !ok {
+		return
+	}
+	if !h.requireVerifiedStudent(c, studentID) {
+		return
+	}
+	category := quota.Normalize(req.Category)
+	if !quota.IsValid(category) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown category: " + req.Category, "categories": quota.Categories})
+		return
+	}
+
+	// Drafts may be started before a cycle opens, but not once it has closed
+	cycle, ok := h.applicationCycle(c, strings.TrimSpace(req.Cycle), courseIDs)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	if cycle.Status(now) == models.CycleClosed {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":    "Applications for " + cycle.Name + " are closed",
+			"closesAt": cycle.ClosesAt,
+		})
+		return
+	}
+
+	errs := fieldErrors{}
+	validatePersonalDetails(req.PersonalDetails, false, errs)
+	validateAcademicDetails(req.AcademicDetails, false, errs)
+	if !writeFieldErrors(c, "Some fields are invalid", errs) {
+		return
+	}
+
+	admission := models.Admission{
+		StudentID:       studentID,
+		CourseID:        courseIDs[0],
+		PersonalDetails: req.PersonalDetails,
+		AcademicDetails: req.AcademicDetails,
+		Documents:       req.Documents,
+		Category:        category,
+		Cycle:           cycle.Code,
+		Status:          workflow.StatusDraft,
+		StatusHistory: []models.StatusChange{{
+			To:        workflow.StatusDraft,
+			ChangedBy: studentID,
+			Role:      workflow.RoleStudent,
+			ChangedAt: now,
+		}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if len(req.Preferences) > 0 {
+		for i, courseID := range courseIDs {
+			admission.Preferences = append(admission.Preferences, models.Preference{
+				Rank:     i + 1,
+				CourseID: courseID,
+				Status:   models.PreferencePending,
+			})
+		}
+	}
+	if !h.checkApplicationDocuments(c, &admission) {
+		return
+	}
+
+	if !h.createAdmission(c, &admission, "Error while saving draft") {
+		return
+	}
+
+	c.JSON(http.StatusCreated, admission)
+}
+
+// UpdateDraft saves the sections sent, replacing each one whole. Sections
+// left out of the request are kept as they are.
+func (h *Handler) UpdateDraft(c *gin.Context) {
+	var req struct {
+		PersonalDetails *models.PersonalDetails `json:"personalDetails"`
+		AcademicDetails *models.AcademicDetails `json:"academicDetails"`
+		Documents       *models.Documents       `json:"documents"`
+		Category        *string                 `json:"category"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.PersonalDetails == nil && req.AcademicDetails == nil && req.Documents == nil && req.Category == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Send at least one of personalDetails, academicDetails, documents or category"})
+		return
+	}
+
+	admission, ok := h.findOwnDraft(c)
+	if !ok {
+		return
+	}
+
+	errs := fieldErrors{}
+	if req.PersonalDetails != nil {
+		validatePersonalDetails(*req.PersonalDetails, false, errs)
+		admission.PersonalDetails = *req.PersonalDetails
+	}
+	if req.AcademicDetails != nil {
+		validateAcademicDetails(*req.AcademicDetails, false, errs)
+		admission.AcademicDetails = *req.AcademicDetails
+	}
+	if !writeFieldErrors(c, "Some fields are invalid", errs) {
+		return
+	}
+	if req.Category != nil {
+		category := quota.Normalize(*req.Category)
+		if !quota.IsValid(category) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown category: " + *req.Category, "categories": quota.Categories})
+			return
+		}
+		admission.Category = category
+	}
+	if req.Documents != nil {
+		admission.Documents = *req.Documents
+		if !h.checkApplicationDocuments(c, admission) {
+			return
+		}
+	}
+
+	admission.UpdatedAt = time.Now()
+	if !h.saveDraft(c, admission) {
+		return
+	}
+	if req.Documents != nil && !h.attachDocuments(c, admission) {
+		return
+	}
+
+	c.JSON(http.StatusOK, admission)
+}
+
+// SubmitAdmission checks that a draft is complete and passes every rule an
+// application must, then locks it. Drafts with no fee due move straight to
+// submitted; the others stay drafts until the application fee is paid.
+func (h *Handler) SubmitAdmission(c *gin.Context) {
+	admission, ok := h.findOwnDraft(c)
+	if !ok {
+		return
+	}
+	if !h.requireVerifiedStudent(c, admission.StudentID) {
+		return
+	}
+
+	errs := fieldErrors{}
+	validatePersonalDetails(admission.PersonalDetails, true, errs)
+	validateAcademicDetails(admission.AcademicDetails, true, errs)
+	validateDocuments(admission.Documents, true, errs)
+	if !writeFieldErrors(c, "The application is incomplete", errs) {
+		return
+	}
+
+	courseIDs := []primitive.ObjectID{admission.CourseID}
+	if len(admission.Preferences) > 0 {
+		courseIDs = courseIDs[:0]
+		for _, preference := range admission.Preferences {
+			courseIDs = append(courseIDs, preference.CourseID)
+		}
+	}
+	if !h.finalizeApplication(c, admission, courseIDs, len(admission.Preferences) > 0) {
+		return
+	}
+	if !h.attachDocuments(c, admission) {
+		return
+	}
+	admission.UpdatedAt = *admission.SubmittedAt
+	actor := services.Actor{ID: admission.StudentID, Role: rbac.RoleStudent}
+	err := h.AdmissionService.Submit(c.Request.Context(), admission, actor)
+	switch {
+	case err == nil:
+	case errors.Is(err, repositories.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "This application has been submitted and can no longer be edited"})
+		return
+	case errors.Is(err, repositories.ErrDuplicate):
+		h.writeDuplicateApplication(c, admission)
+		return
+	default:
+		writeTransitionError(c, err, admission.Status, workflow.StatusSubmitted)
+		return
+	}
+
+	updated, err := h.Admissions.FindByID(c.Request.Context(), admission.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching admission"})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/mailer"
+	"admission-portal-backend/internal/payments"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/services"
+	"admission-portal-backend/internal/storage"
+)
+
+// Handler holds the dependencies shared by the HTTP handlers. Build one with
+// NewHandler and register its methods in routes.SetupRoutes.
+type Handler struct {
+	Students   repositories.StudentRepository
+	Courses    repositories.CourseRepository
+	Admissions repositories.AdmissionRepository
+	Sessions   repositories.SessionRepository
+	Tokens     repositories.ActionTokenRepository
+	Invites    repositories.InviteRepository
+	Documents  repositories.DocumentRepository
+	MeritLists repositories.MeritListRepository
+	Cycles     repositories.CycleRepository
+	CycleSeats repositories.CycleSeatRepository
+	Payments   repositories.PaymentRepository
+
+	IdempotencyKeys repositories.IdempotencyRepository
+
+	AdmissionService *services.AdmissionService
+	MeritService     *services.MeritService
+	PaymentService   *services.PaymentService
+	Audit            *audit.Recorder
+	Mailer           mailer.Mailer
+	Blobs            storage.BlobStore
+}
+
+func NewHandler(store *repositories.Store, mail mailer.Mailer, blobs storage.BlobStore, gateway payments.PaymentGateway) *Handler {
+	recorder := audit.NewRecorder(store.Audit, store.AuditQueue)
+	admissionService := services.NewAdmissionService(store)
+	admissionService.Mailer = mail
+	admissionService.Audit = recorder
+	return &Handler{
+		Students:   store.Students,
+		Courses:    store.Courses,
+		Admissions: store.Admissions,
+		Sessions:   store.Sessions,
+		Tokens:     store.Tokens,
+		Invites:    store.Invites,
+		Documents:  store.Documents,
+		MeritLists: store.MeritLists,
+		Cycles:     store.Cycles,
+		CycleSeats: store.CycleSeats,
+		Payments:   store.Payments,
+
+		IdempotencyKeys: store.IdempotencyKeys,
+
+		AdmissionService: admissionService,
+		MeritService:     services.NewMeritService(store),
+		PaymentService:   services.NewPaymentService(store, gateway, admissionService),
+		Audit:            recorder,
+		Mailer:           mail,
+		Blobs:            blobs,
+	}
+}
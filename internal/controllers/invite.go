@@ -0,0 +1,374 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/auth"
+	"admission-portal-backend/internal/mailer"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/repositories"
+)
+
+const (
+	// inviteTTL is how long an emailed staff invite can be accepted.
+	inviteTTL = 72 * time.Hour
+	// bootstrapInviteTTL is how long the first-run invite lasts. A restart
+	// issues a new one while there is still no super admin.
+	bootstrapInviteTTL = 24 * time.Hour
+)
+
+var inviteListSpec = query.Spec{
+	Filters: []query.Filter{
+		{Param: "email", Field: "email", Type: query.String, Op: query.OpEq},
+		{Param: "role", Field: "role", Type: query.String, Op: query.OpIn},
+		{Param: "createdFrom", Field: "createdAt", Type: query.Time, Op: query.OpGte},
+		{Param: "createdTo", Field: "createdAt", Type: query.Time, Op: query.OpLte},
+	},
+	Sorts: map[string]string{
+		"createdAt": "createdAt",
+		"expiresAt": "expiresAt",
+		"email":     "email",
+	},
+	DefaultSort: "-createdAt",
+}
+
+// CreateInvite invites someone by email to join the staff with a role. The
+// invite link is emailed to them and works once, until it expires.
+func (h *Handler) CreateInvite(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+		Role  string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role := rbac.Normalize(req.Role)
+	if !rbac.IsStaff(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invites are for staff roles: " + strings.Join(rbac.StaffRoles(), ", ")})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.Students.FindByEmail(ctx, req.Email); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists; change its role instead"})
+		return
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating invite"})
+		return
+	}
+	now := time.Now()
+	existing, err := h.Invites.List(ctx, query.Options{}.Where("email", query.OpEq, req.Email))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating invite"})
+		return
+	}
+	for _, invite := range existing.Items {
+		if invite.CurrentStatus(now) == models.InvitePending {
+			c.JSON(http.StatusConflict, gin.H{"error": "This email already has a pending invite; revoke it to send a new one", "inviteId": invite.ID})
+			return
+		}
+	}
+
+	inviterID, _ := primitive.ObjectIDFromHex(c.GetString("userID"))
+	invite := models.Invite{
+		Email:     req.Email,
+		Role:      role,
+		InvitedBy: inviterID,
+		ExpiresAt: now.Add(inviteTTL),
+		CreatedAt: now,
+	}
+	token, err := h.issueInvite(ctx, &invite)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating invite"})
+		return
+	}
+
+	msg := mailer.Message{
+		To:      invite.Email,
+		Subject: "You have been invited to the admission portal",
+		Body: fmt.Sprintf("Hello,\n\nYou have been invited to join the admission portal staff as %s. Use the link below to set up your account. It expires in %d hours and can only be used once.\n\n%s\n\nIf you were not expecting this invitation you can ignore this email.\n",
+			invite.Role, int(inviteTTL.Hours()), appLink("/accept-invite", url.Values{"token": {token}})),
+	}
+	if err := h.Mailer.Send(ctx, msg); err != nil {
+		log.Printf("Error sending invite to %s: %v", invite.Email, err)
+	}
+	if !h.audit(c, audit.ActionInviteCreate, audit.TargetInvite, invite.ID, nil, invite) {
+		return
+	}
+
+	invite.Status = invite.CurrentStatus(now)
+	c.JSON(http.StatusCreated, invite)
+}
+
+// issueInvite stores invite and returns the signed token that redeems it.
+func (h *Handler) issueInvite(ctx context.Context, invite *models.Invite) (string, error) {
+	if err := h.Invites.Create(ctx, invite); err != nil {
+		return "", err
+	}
+	return auth.IssueInviteToken(invite.ID.Hex(), invite.ExpiresAt)
+}
+
+// ListInvites lists staff invites with their current status.
+func (h *Handler) ListInvites(c *gin.Context) {
+	opts, ok := parseListQuery(c, inviteListSpec)
+	if !ok {
+		return
+	}
+	invites, err := h.Invites.List(c.Request.Context(), opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching invites"})
+		return
+	}
+	now := time.Now()
+	for i := range invites.Items {
+		invites.Items[i].Status = invites.Items[i].CurrentStatus(now)
+	}
+	c.JSON(http.StatusOK, invites)
+}
+
+// RevokeInvite cancels a pending invite so its link stops working.
+func (h *Handler) RevokeInvite(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	before, err := h.Invites.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while revoking invite"})
+		return
+	}
+	now := time.Now()
+	if err := h.Invites.Revoke(ctx, id, now); err != nil {
+		if errors.Is(err, repositories.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Only pending invites can be revoked", "status": before.CurrentStatus(now)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while revoking invite"})
+		return
+	}
+	after, err := h.Invites.FindByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while revoking invite"})
+		return
+	}
+	if !h.audit(c, audit.ActionInviteRevoke, audit.TargetInvite, id, before, after) {
+		return
+	}
+
+	after.Status = after.CurrentStatus(now)
+	c.JSON(http.StatusOK, after)
+}
+
+// loadInvite finds the invite a token redeems, answering itself when the
+// token is bad or the invite can no longer be used.
+func (h *Handler) loadInvite(c *gin.Context, token string) (*models.Invite, bool) {
+	inviteID, err := auth.ParseInviteToken(token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invite"})
+		return nil, false
+	}
+	id, err := primitive.ObjectIDFromHex(inviteID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invite"})
+		return nil, false
+	}
+	invite, err := h.Invites.FindByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invite"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while loading invite"})
+		return nil, false
+	}
+	invite.Status = invite.CurrentStatus(time.Now())
+	if invite.Status != models.InvitePending {
+		c.JSON(http.StatusConflict, gin.H{"error": "This invite can no longer be used", "status": invite.Status})
+		return nil, false
+	}
+	return invite, true
+}
+
+// GetInvite shows what an invite link is for, so the invitee can see the
+// email and role before accepting.
+func (h *Handler) GetInvite(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invite token is required"})
+		return
+	}
+	invite, ok := h.loadInvite(c, token)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"email":     invite.Email,
+		"role":      invite.Role,
+		"bootstrap": invite.Bootstrap,
+		"expiresAt": invite.ExpiresAt,
+	})
+}
+
+// AcceptInvite redeems an invite, creating the invitee's staff account with
+// the password they choose. The bootstrap invite also takes the email to
+// use, and only works while the portal has no super admin.
+func (h *Handler) AcceptInvite(c *gin.Context) {
+	var req struct {
+		Token    string `json:"token" binding:"required"`
+		Email    string `json:"email" binding:"omitempty,email"`
+		Name     string `json:"name" binding:"required"`
+		Password string `json:"password" binding:"required,min=6"`
+		Phone    string `json:"phone"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	invite, ok := h.loadInvite(c, req.Token)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	email := invite.Email
+	if invite.Bootstrap {
+		if req.Email == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Email is required to set up the first super admin"})
+			return
+		}
+		email = req.Email
+		exists, err := h.superAdminExists(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while accepting invite"})
+			return
+		}
+		if exists {
+			c.JSON(http.StatusConflict, gin.H{"error": "The portal has already been set up"})
+			return
+		}
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while hashing password"})
+		return
+	}
+	now := time.Now()
+	user := models.Student{
+		ID:       primitive.NewObjectID(),
+		Email:    email,
+		Password: string(hashedPassword),
+		Name:     req.Name,
+		Phone:    req.Phone,
+		Role:     invite.Role,
+		// The invite reached the address, or was issued to the server's
+		// operator for the bootstrap
+		EmailVerified: true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	// Use up the invite first, so two requests racing with it cannot both
+	// create an account
+	if err := h.Invites.Accept(ctx, invite.ID, user.ID, now); err != nil {
+		if errors.Is(err, repositories.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "This invite can no longer be used"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while accepting invite"})
+		return
+	}
+	if err := h.Students.Create(ctx, &user); err != nil {
+		if reopenErr := h.Invites.Reopen(ctx, invite.ID); reopenErr != nil {
+			log.Printf("Error reopening invite %s: %v", invite.ID.Hex(), reopenErr)
+		}
+		if errors.Is(err, repositories.ErrDuplicate) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating account"})
+		return
+	}
+
+	// The new account is the actor
+	c.Set("userID", user.ID.Hex())
+	c.Set("role", user.Role)
+	user.Password = ""
+	if !h.audit(c, audit.ActionInviteAccept, audit.TargetUser, user.ID, nil, user) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Account created, please log in", "user": user})
+}
+
+// superAdminExists reports whether anyone holds the super admin role,
+// including accounts still stored with the legacy admin role.
+func (h *Handler) superAdminExists(ctx context.Context) (bool, error) {
+	roles := []any{rbac.RoleSuperAdmin, rbac.RoleLegacyAdmin}
+	admins, err := h.Students.List(ctx, query.Options{Limit: 1}.Where("role", query.OpIn, roles))
+	if err != nil {
+		return false, err
+	}
+	return len(admins.Items) > 0, nil
+}
+
+// Bootstrap prepares a fresh portal for its first super admin. While no
+// super admin exists it issues a bootstrap invite, replacing any earlier
+// one, and logs its token for the operator to redeem with AcceptInvite.
+// Once a super admin exists it does nothing.
+func (h *Handler) Bootstrap(ctx context.Context) error {
+	exists, err := h.superAdminExists(ctx)
+	if err != nil || exists {
+		return err
+	}
+
+	now := time.Now()
+	earlier, err := h.Invites.List(ctx, query.Options{}.Where("bootstrap", query.OpEq, true))
+	if err != nil {
+		return err
+	}
+	for _, invite := range earlier.Items {
+		if invite.CurrentStatus(now) != models.InvitePending {
+			continue
+		}
+		if err := h.Invites.Revoke(ctx, invite.ID, now); err != nil && !errors.Is(err, repositories.ErrConflict) {
+			return err
+		}
+	}
+
+	invite := models.Invite{
+		Role:      rbac.RoleSuperAdmin,
+		Bootstrap: true,
+		ExpiresAt: now.Add(bootstrapInviteTTL),
+		CreatedAt: now,
+	}
+	token, err := h.issueInvite(ctx, &invite)
+	if err != nil {
+		return err
+	}
+	log.Printf("No super admin exists yet. Create the first one within %d hours by sending this token, with an email, name and password, to POST /api/invites/accept:\n\n    %s\n",
+		int(bootstrapInviteTTL.Hours()), token)
+	return nil
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/merit"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/services"
+)
+
+var meritListSpec = query.Spec{
+	Filters: []query.Filter{
+		{Param: "courseId", Field: "courseId", Type: query.ObjectID, Op: query.OpEq},
+		{Param: "cycle", Field: "cycle", Type: query.String, Op: query.OpEq},
+	},
+	Sorts: map[string]string{
+		"version":     "version",
+		"publishedAt": "publishedAt",
+	},
+	DefaultSort: "-publishedAt",
+}
+
+type meritListRequest struct {
+	CourseID string               `json:"courseId" binding:"required"`
+	Cycle    string               `json:"cycle" binding:"required"`
+	Weights  *models.MeritWeights `json:"weights"`
+}
+
+// bindMeritListRequest reads the course, cycle and weights of a merit list
+// request, answering 400 itself when they are malformed.
+func bindMeritListRequest(c *gin.Context) (primitive.ObjectID, string, models.MeritWeights, bool) {
+	var req meritListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return primitive.NilObjectID, "", models.MeritWeights{}, false
+	}
+	courseID, err := primitive.ObjectIDFromHex(req.CourseID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return primitive.NilObjectID, "", models.MeritWeights{}, false
+	}
+	weights := merit.DefaultWeights
+	if req.Weights != nil {
+		weights = *req.Weights
+	}
+	return courseID, strings.TrimSpace(req.Cycle), weights, true
+}
+
+func writeMeritError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, merit.ErrNegativeWeight), errors.Is(err, merit.ErrNoWeights):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCourseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+	case errors.Is(err, services.ErrCycleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admission cycle not found"})
+	case errors.Is(err, repositories.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "Another merit list was published at the same time, please retry"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while building merit list"})
+	}
+}
+
+// PreviewMeritList ranks the current applicants without publishing.
+func (h *Handler) PreviewMeritList(c *gin.Context) {
+	courseID, cycle, weights, ok := bindMeritListRequest(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.MeritService.Build(c.Request.Context(), courseID, cycle, weights)
+	if err != nil {
+		writeMeritError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// PublishMeritList ranks the current applicants and stores the result as the
+// next version of the course's list for the cycle.
+func (h *Handler) PublishMeritList(c *gin.Context) {
+	courseID, cycle, weights, ok := bindMeritListRequest(c)
+	if !ok {
+		return
+	}
+	actorID, _ := primitive.ObjectIDFromHex(c.GetString("userID"))
+
+	list, err := h.MeritService.Publish(c.Request.Context(), courseID, cycle, weights, services.Actor{ID: actorID, Role: c.GetString("role")})
+	if err != nil {
+		writeMeritError(c, err)
+		return
+	}
+	published := gin.H{
+		"courseId": list.CourseID,
+		"cycle":    list.Cycle,
+		"version":  list.Version,
+		"weights":  list.Weights,
+		"entries":  len(list.Entries),
+	}
+	if !h.audit(c, audit.ActionMeritListPublish, audit.TargetMeritList, list.ID, nil, published) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, list)
+}
+
+func (h *Handler) ListMeritLists(c *gin.Context) {
+	opts, ok := parseListQuery(c, meritListSpec)
+	if !ok {
+		return
+	}
+
+	lists, err := h.MeritLists.List(c.Request.Context(), opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching merit lists"})
+		return
+	}
+
+	c.JSON(http.StatusOK, lists)
+}
+
+func (h *Handler) GetMeritList(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid merit list ID"})
+		return
+	}
+
+	list, err := h.MeritLists.FindByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Merit list not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching merit list"})
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// GetAdmissionMerit reports where an admission stands on the newest
+// published merit list of each course it competes for in its cycle.
+func (h *Handler) GetAdmissionMerit(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admission ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	admission, err := h.Admissions.FindByID(ctx, id)
+	if err != nil || !canSeeAdmission(admission, c.GetString("userID"), c.GetString("role")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
+		return
+	}
+
+	courseIDs := []primitive.ObjectID{admission.CourseID}
+	for _, preference := range admission.Preferences {
+		if preference.CourseID != admission.CourseID {
+			courseIDs = append(courseIDs, preference.CourseID)
+		}
+	}
+
+	standings := []gin.H{}
+	for _, courseID := range courseIDs {
+		opts := query.Options{Sort: []query.Sort{{Field: "publishedAt", Desc: true}}, Limit: 1}
+		opts = opts.Where("courseId", query.OpEq, courseID)
+		if admission.Cycle != "" {
+			opts = opts.Where("cycle", query.OpEq, admission.Cycle)
+		}
+		page, err := h.MeritLists.List(ctx, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching merit lists"})
+			return
+		}
+		if len(page.Items) == 0 {
+			continue
+		}
+		list := page.Items[0]
+		for _, entry := range list.Entries {
+			if entry.AdmissionID == admission.ID {
+				standings = append(standings, gin.H{
+					"meritListId":  list.ID,
+					"courseId":     list.CourseID,
+					"cycle":        list.Cycle,
+					"version":      list.Version,
+					"publishedAt":  list.PublishedAt,
+					"rank":         entry.Rank,
+					"total":        len(list.Entries),
+					"seats":        list.Seats,
+					"score":        entry.Score,
+					"withinCutoff": entry.WithinCutoff,
+				})
+				break
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, standings)
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+
+	"admission-portal-backend/internal/auth"
+	"admission-portal-backend/internal/mailer"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/repositories"
+)
+
+const passwordResetTTL = time.Hour
+
+// appLink builds a link to a frontend page from APP_BASE_URL. Emailed links
+// always open the frontend, which passes their token on to the API.
+func appLink(path string, query url.Values) string {
+	base := os.Getenv("APP_BASE_URL")
+	if base == "" {
+		base = "http://localhost:8080"
+	}
+	return strings.TrimRight(base, "/") + path + "?" + query.Encode()
+}
+
+// ForgotPassword emails a password reset link. It answers the same way
+// whether or not the email belongs to an account, so it cannot be used to
+// discover registered addresses.
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "If an account exists for this email, a password reset link has been sent"}
+
+	ctx := c.Request.Context()
+	student, err := h.Students.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			log.Printf("Error looking up account for password reset: %v", err)
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Only the newest link should work
+	if err := h.Tokens.InvalidateForUser(ctx, student.ID, models.TokenPurposePasswordReset); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating reset token"})
+		return
+	}
+
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating reset token"})
+		return
+	}
+	now := time.Now()
+	record := models.ActionToken{
+		UserID:    student.ID,
+		Purpose:   models.TokenPurposePasswordReset,
+		TokenHash: hash,
+		ExpiresAt: now.Add(passwordResetTTL),
+		CreatedAt: now,
+	}
+	if err := h.Tokens.Create(ctx, &record); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating reset token"})
+		return
+	}
+
+	msg := mailer.Message{
+		To:      student.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nUse the link below to choose a new password. It expires in %d minutes and can only be used once.\n\n%s\n\nIf you did not ask for a password reset you can ignore this email.\n",
+			student.Name, int(passwordResetTTL.Minutes()), appLink("/reset-password", url.Values{"token": {token}})),
+	}
+	if err := h.Mailer.Send(ctx, msg); err != nil {
+		log.Printf("Error sending password reset email to %s: %v", student.Email, err)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword and
+// signs the account out everywhere.
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,min=6"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	token, err := h.Tokens.Consume(ctx, models.TokenPurposePasswordReset, auth.HashToken(req.Token), time.Now())
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while resetting password"})
+		return
+	}
+
+	student, err := h.Students.FindByID(ctx, token.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while hashing password"})
+		return
+	}
+	student.Password = string(hashedPassword)
+	student.UpdatedAt = time.Now()
+	if err := h.Students.Update(ctx, student); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while resetting password"})
+		return
+	}
+
+	if err := h.Sessions.RevokeAllForUser(ctx, student.ID, "password_reset"); err != nil {
+		log.Printf("Error revoking sessions after password reset for %s: %v", student.ID.Hex(), err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in again"})
+}
@@ -0,0 +1,88 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"admission-portal-backend/internal/rbac"
+)
+
+var linkPattern = regexp.MustCompile(`https?://\S+`)
+
+// mailedLink returns the link in the last message sent to, with its token.
+func (a *testAPI) mailedLink(t *testing.T, to string) (*url.URL, string) {
+	t.Helper()
+	msg := a.mail.last(to)
+	if msg == nil {
+		t.Fatalf("no mail sent to %s", to)
+	}
+	link, err := url.Parse(linkPattern.FindString(msg.Body))
+	if err != nil || link.Query().Get("token") == "" {
+		t.Fatalf("no link with a token in %q", msg.Body)
+	}
+	return link, link.Query().Get("token")
+}
+
+func TestPasswordReset(t *testing.T) {
+	api := newTestAPI(t)
+	api.addUser(t, "student@example.com", rbac.RoleStudent)
+	session := api.login(t, "student@example.com")
+
+	if rec := api.do(t, http.MethodPost, "/api/auth/forgot-password", "", gin.H{"email": "student@example.com"}, nil); rec.Code != http.StatusOK {
+		t.Fatalf("forgot-password = %d %s", rec.Code, rec.Body)
+	}
+	link, token := api.mailedLink(t, "student@example.com")
+	if link.Path != "/reset-password" {
+		t.Errorf("reset link points at %s", link.Path)
+	}
+
+	reset := gin.H{"token": token, "password": "n3w-passw0rd"}
+	if rec := api.do(t, http.MethodPost, "/api/auth/reset-password", "", reset, nil); rec.Code != http.StatusOK {
+		t.Fatalf("reset-password = %d %s", rec.Code, rec.Body)
+	}
+	if rec := api.do(t, http.MethodPost, "/api/auth/reset-password", "", reset, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("reusing a reset token = %d, want 400", rec.Code)
+	}
+	if rec := api.do(t, http.MethodGet, "/api/students/me", session.Token, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("session from before the reset = %d, want 401", rec.Code)
+	}
+	login := gin.H{"email": "student@example.com", "password": "n3w-passw0rd"}
+	if rec := api.do(t, http.MethodPost, "/api/students/login", "", login, nil); rec.Code != http.StatusOK {
+		t.Errorf("login with the new password = %d", rec.Code)
+	}
+}
+
+func TestForgotPasswordOnlyNewestLinkWorks(t *testing.T) {
+	api := newTestAPI(t)
+	api.addUser(t, "student@example.com", rbac.RoleStudent)
+	forgot := gin.H{"email": "student@example.com"}
+	api.do(t, http.MethodPost, "/api/auth/forgot-password", "", forgot, nil)
+	_, first := api.mailedLink(t, "student@example.com")
+	api.do(t, http.MethodPost, "/api/auth/forgot-password", "", forgot, nil)
+	_, second := api.mailedLink(t, "student@example.com")
+
+	if rec := api.do(t, http.MethodPost, "/api/auth/reset-password", "", gin.H{"token": first, "password": "n3w-passw0rd"}, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("superseded reset token = %d, want 400", rec.Code)
+	}
+	if rec := api.do(t, http.MethodPost, "/api/auth/reset-password", "", gin.H{"token": second, "password": "n3w-passw0rd"}, nil); rec.Code != http.StatusOK {
+		t.Errorf("newest reset token = %d, want 200", rec.Code)
+	}
+}
+
+func TestForgotPasswordHidesUnknownEmails(t *testing.T) {
+	api := newTestAPI(t)
+	api.addUser(t, "student@example.com", rbac.RoleStudent)
+	known := api.do(t, http.MethodPost, "/api/auth/forgot-password", "", gin.H{"email": "student@example.com"}, nil)
+	unknown := api.do(t, http.MethodPost, "/api/auth/forgot-password", "", gin.H{"email": "nobody@example.com"}, nil)
+	if known.Code != unknown.Code || strings.TrimSpace(known.Body.String()) != strings.TrimSpace(unknown.Body.String()) {
+		t.Errorf("answers differ: %d %s and %d %s", known.Code, known.Body, unknown.Code, unknown.Body)
+	}
+	if api.mail.last("nobody@example.com") != nil {
+		t.Error("mail sent to an unknown address")
+	}
+}
@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/payments"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/services"
+)
+
+// maxWebhookBytes bounds the size of a gateway callback.
+const maxWebhookBytes = 64 << 10
+
+var paymentListSpec = query.Spec{
+	Filters: []query.Filter{
+		{Param: "status", Field: "status", Type: query.String, Op: query.OpIn},
+		{Param: "purpose", Field: "purpose", Type: query.String, Op: query.OpIn},
+		{Param: "admissionId", Field: "admissionId", Type: query.ObjectID, Op: query.OpEq},
+		{Param: "studentId", Field: "studentId", Type: query.ObjectID, Op: query.OpEq},
+		{Param: "createdFrom", Field: "createdAt", Type: query.Time, Op: query.OpGte},
+		{Param: "createdTo", Field: "createdAt", Type: query.Time, Op: query.OpLte},
+	},
+	Sorts: map[string]string{
+		"createdAt": "createdAt",
+		"amount":    "amount",
+	},
+	DefaultSort: "-createdAt",
+}
+
+func writePaymentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPaymentNotDue):
+		c.JSON(http.StatusConflict, gin.H{"error": "This fee is not due at the admission's current stage"})
+	case errors.Is(err, services.ErrNotSubmitted):
+		c.JSON(http.StatusConflict, gin.H{"error": "Submit the application before paying its fee"})
+	case errors.Is(err, services.ErrAlreadyPaid):
+		c.JSON(http.StatusConflict, gin.H{"error": "This fee has already been paid"})
+	case errors.Is(err, services.ErrNothingDue):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "There is nothing to pay"})
+	case errors.Is(err, services.ErrNotRefundable):
+		c.JSON(http.StatusConflict, gin.H{"error": "Only captured payments can be refunded"})
+	case errors.Is(err, services.ErrRefundPending):
+		c.JSON(http.StatusBadGateway, gin.H{"error": "The payment gateway did not confirm the refund; repeat the request with the same Idempotency-Key"})
+	case errors.Is(err, payments.ErrRefundTooLarge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Refund amount must be positive and no more than what is left of the payment"})
+	case errors.Is(err, payments.ErrInvalidSignature):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook signature"})
+	case errors.Is(err, services.ErrCourseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+	case errors.Is(err, repositories.ErrNotFound), errors.Is(err, payments.ErrIntentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while processing payment"})
+	}
+}
+
+// findOwnedPayment loads the payment in the id parameter if the caller paid
+// it or may read fees, answering 404 itself otherwise.
+func (h *Handler) findOwnedPayment(c *gin.Context) (*models.Payment, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
+		return nil, false
+	}
+	payment, err := h.Payments.FindByID(c.Request.Context(), id)
+	if err != nil || (!rbac.Can(c.GetString("role"), rbac.FeesRead) && payment.StudentID.Hex() != c.GetString("userID")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		return nil, false
+	}
+	return payment, true
+}
+
+// CreatePayment starts paying a fee on the caller's admission. The response
+// carries the gateway's client secret for the checkout.
+func (h *Handler) CreatePayment(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admission ID"})
+		return
+	}
+	var req struct {
+		Purpose string `json:"purpose" binding:"required,oneof=application_fee tuition"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admission, err := h.Admissions.FindByID(c.Request.Context(), id)
+	if err != nil || admission.StudentID.Hex() != c.GetString("userID") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
+		return
+	}
+
+	payment, created, err := h.PaymentService.CreateIntent(c.Request.Context(), admission, req.Purpose)
+	if err != nil {
+		writePaymentError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, payment)
+}
+
+func (h *Handler) ListAdmissionPayments(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admission ID"})
+		return
+	}
+	admission, err := h.Admissions.FindByID(c.Request.Context(), id)
+	if err != nil || (!rbac.Can(c.GetString("role"), rbac.FeesRead) && admission.StudentID.Hex() != c.GetString("userID")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
+		return
+	}
+
+	opts, ok := parseListQuery(c, paymentListSpec)
+	if !ok {
+		return
+	}
+	page, err := h.Payments.List(c.Request.Context(), opts.Where("admissionId", query.OpEq, id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching payments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *Handler) GetPayment(c *gin.Context) {
+	payment, ok := h.findOwnedPayment(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+func (h *Handler) GetPaymentReceipt(c *gin.Context) {
+	payment, ok := h.findOwnedPayment(c)
+	if !ok {
+		return
+	}
+	if payment.Receipt == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No receipt has been issued for this payment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, payment.Receipt)
+}
+
+// PaymentWebhook receives the gateway's signed callbacks.
+func (h *Handler) PaymentWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unreadable webhook body"})
+		return
+	}
+
+	payment, err := h.PaymentService.HandleWebhook(c.Request.Context(), payload, c.Request.Header)
+	if err != nil {
+		writePaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true, "status": payment.Status})
+}
+
+// CompleteFakeCheckout plays the payer's part when the fake gateway is in
+// use: it settles the caller's payment and delivers the gateway's signed
+// callback to the webhook handler.
+func (h *Handler) CompleteFakeCheckout(c *gin.Context) {
+	simulator, ok := h.PaymentService.Gateway.(payments.Simulator)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Checkout simulation is only available with the fake payment gateway"})
+		return
+	}
+	var req struct {
+		Outcome string `json:"outcome" binding:"required,oneof=succeeded failed"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payment, ok := h.findOwnedPayment(c)
+	if !ok {
+		return
+	}
+	if payment.StudentID.Hex() != c.GetString("userID") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the payer can complete a checkout"})
+		return
+	}
+
+	payload, header, err := simulator.Complete(payment.IntentID, req.Outcome == "succeeded")
+	if err != nil {
+		writePaymentError(c, err)
+		return
+	}
+	payment, err = h.PaymentService.HandleWebhook(c.Request.Context(), payload, header)
+	if err != nil {
+		writePaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+func (h *Handler) ListPayments(c *gin.Context) {
+	opts, ok := parseListQuery(c, paymentListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.Payments.List(c.Request.Context(), opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching payments"})
+		return
+	}
+	// Client secrets are for the payer's checkout only
+	for i := range page.Items {
+		page.Items[i].ClientSecret = ""
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// RefundPayment pays back part or all of a captured payment. The
+// Idempotency-Key header is required so a retried request never refunds
+// twice.
+func (h *Handler) RefundPayment(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The Idempotency-Key header is required"})
+		return
+	}
+	var req struct {
+		Amount int64  `json:"amount" binding:"required"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payment, ok := h.findOwnedPayment(c)
+	if !ok {
+		return
+	}
+	actorID, _ := primitive.ObjectIDFromHex(c.GetString("userID"))
+
+	refund, replayed, err := h.PaymentService.Refund(c.Request.Context(), payment, req.Amount, req.Reason, key, services.Actor{ID: actorID, Role: c.GetString("role")})
+	if err != nil {
+		writePaymentError(c, err)
+		return
+	}
+	if replayed {
+		c.JSON(http.StatusOK, refund)
+		return
+	}
+	after := gin.H{
+		"refunded": payment.Refunded + refund.Amount,
+		"amount":   refund.Amount,
+		"reason":   refund.Reason,
+	}
+	if !h.audit(c, audit.ActionPaymentRefund, audit.TargetPayment, payment.ID, gin.H{"refunded": payment.Refunded}, after) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, refund)
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"admission-portal-backend/internal/query"
+)
+
+var courseListSpec = query.Spec{
+	Filters: []query.Filter{
+		{Param: "createdFrom", Field: "created_at", Type: query.Time, Op: query.OpGte},
+		{Param: "createdTo", Field: "created_at", Type: query.Time, Op: query.OpLte},
+	},
+	Sorts: map[string]string{
+		"name":      "name",
+		"seats":     "seats",
+		"createdAt": "created_at",
+	},
+	DefaultSort: "name",
+}
+
+var admissionListSpec = query.Spec{
+	Filters: []query.Filter{
+		{Param: "status", Field: "status", Type: query.String, Op: query.OpIn},
+		{Param: "courseId", Field: "courseId", Type: query.ObjectID, Op: query.OpEq},
+		{Param: "cycle", Field: "cycle", Type: query.String, Op: query.OpEq},
+		{Param: "category", Field: "category", Type: query.String, Op: query.OpIn},
+		{Param: "createdFrom", Field: "createdAt", Type: query.Time, Op: query.OpGte},
+		{Param: "createdTo", Field: "createdAt", Type: query.Time, Op: query.OpLte},
+	},
+	Sorts: map[string]string{
+		"createdAt": "createdAt",
+		"updatedAt": "updatedAt",
+		"status":    "status",
+	},
+	DefaultSort: "-createdAt",
+}
+
+var adminListSpec = query.Spec{
+	Filters: []query.Filter{
+		{Param: "role", Field: "role", Type: query.String, Op: query.OpIn},
+		{Param: "createdFrom", Field: "created_at", Type: query.Time, Op: query.OpGte},
+		{Param: "createdTo", Field: "created_at", Type: query.Time, Op: query.OpLte},
+	},
+	Sorts: map[string]string{
+		"name":      "name",
+		"email":     "email",
+		"role":      "role",
+		"createdAt": "created_at",
+	},
+	DefaultSort: "name",
+}
+
+var cycleListSpec = query.Spec{
+	Filters: []query.Filter{
+		{Param: "opensFrom", Field: "opensAt", Type: query.Time, Op: query.OpGte},
+		{Param: "opensTo", Field: "opensAt", Type: query.Time, Op: query.OpLte},
+	},
+	Sorts: map[string]string{
+		"code":     "code",
+		"opensAt":  "opensAt",
+		"closesAt": "closesAt",
+	},
+	DefaultSort: "-opensAt",
+}
+
+// parseListQuery reads pagination, sort and filter parameters for a list
+// endpoint, answering 400 itself when they are malformed.
+func parseListQuery(c *gin.Context, spec query.Spec) (query.Options, bool) {
+	opts, err := query.Parse(c.Request.URL.Query(), spec)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return opts, false
+	}
+	return opts, true
+}
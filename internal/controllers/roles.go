@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/repositories"
+)
+
+// ListRoles describes every role and the permissions it grants.
+func (h *Handler) ListRoles(c *gin.Context) {
+	roles := []gin.H{}
+	for _, role := range rbac.Roles() {
+		roles = append(roles, gin.H{"role": role, "permissions": rbac.Permissions(role)})
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// AssignRole gives a user a new role.
+func (h *Handler) AssignRole(c *gin.Context) {
+	var input struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !rbac.IsValid(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role: " + input.Role})
+		return
+	}
+	h.setRole(c, audit.ActionRoleAssign, rbac.Normalize(input.Role))
+}
+
+// RevokeRole takes a user's staff role away, leaving them a student.
+func (h *Handler) RevokeRole(c *gin.Context) {
+	h.setRole(c, audit.ActionRoleRevoke, rbac.RoleStudent)
+}
+
+// setRole stores role on the user named in the path and ends their sessions,
+// so the change applies from their next login rather than when their current
+// access token expires.
+func (h *Handler) setRole(c *gin.Context, action, role string) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	// Keeps super admins from locking themselves, and possibly everyone, out
+	if c.GetString("userID") == objectID.Hex() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot change your own role"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.Students.FindByID(ctx, objectID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while updating role"})
+		return
+	}
+
+	if previous := user.Role; rbac.Normalize(previous) != role {
+		user.Role = role
+		user.UpdatedAt = time.Now()
+		if err := h.Students.Update(ctx, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while updating role"})
+			return
+		}
+		if err := h.Sessions.RevokeAllForUser(ctx, user.ID, "role_changed"); err != nil {
+			log.Printf("Error revoking sessions after role change for %s: %v", user.ID.Hex(), err)
+		}
+		if !h.audit(c, action, audit.TargetUser, user.ID, gin.H{"role": previous}, gin.H{"role": role}) {
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Role updated successfully",
+		"id":          user.ID,
+		"role":        role,
+		"permissions": rbac.Permissions(role),
+	})
+}
@@ -0,0 +1,62 @@
+package controllers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"admission-portal-backend/internal/rbac"
+)
+
+func TestRoutesCheckPermissions(t *testing.T) {
+	api := newTestAPI(t)
+	for _, role := range []string{rbac.RoleSuperAdmin, rbac.RoleLegacyAdmin, rbac.RoleReviewer, rbac.RoleFinance, rbac.RoleStudent} {
+		api.addUser(t, role+"@example.com", role)
+	}
+	tests := []struct {
+		role, method, path string
+		want               int
+	}{
+		{rbac.RoleSuperAdmin, http.MethodGet, "/api/admin/roles", http.StatusOK},
+		{rbac.RoleLegacyAdmin, http.MethodGet, "/api/admin/roles", http.StatusOK},
+		{rbac.RoleReviewer, http.MethodGet, "/api/admin/admissions", http.StatusOK},
+		{rbac.RoleReviewer, http.MethodGet, "/api/admin/payments", http.StatusForbidden},
+		{rbac.RoleFinance, http.MethodGet, "/api/admin/payments", http.StatusOK},
+		{rbac.RoleFinance, http.MethodGet, "/api/admin/audit", http.StatusForbidden},
+		{rbac.RoleStudent, http.MethodGet, "/api/admin/admissions", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		session := api.login(t, tt.role+"@example.com")
+		if rec := api.do(t, tt.method, tt.path, session.Token, nil, nil); rec.Code != tt.want {
+			t.Errorf("%s %s as %s = %d, want %d", tt.method, tt.path, tt.role, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestAssignRoleEndsSessions(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.addUser(t, "admin@example.com", rbac.RoleSuperAdmin)
+	user := api.addUser(t, "user@example.com", rbac.RoleStudent)
+	adminSession, userSession := api.login(t, "admin@example.com"), api.login(t, "user@example.com")
+
+	path := "/api/admin/users/" + user.ID.Hex() + "/role"
+	if rec := api.do(t, http.MethodPut, path, adminSession.Token, gin.H{"role": "janitor"}, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("assigning an unknown role = %d, want 400", rec.Code)
+	}
+	if rec := api.do(t, http.MethodPut, "/api/admin/users/"+admin.ID.Hex()+"/role", adminSession.Token, gin.H{"role": rbac.RoleReviewer}, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("changing your own role = %d, want 403", rec.Code)
+	}
+	if rec := api.do(t, http.MethodPut, path, adminSession.Token, gin.H{"role": rbac.RoleReviewer}, nil); rec.Code != http.StatusOK {
+		t.Fatalf("assigning reviewer = %d %s", rec.Code, rec.Body)
+	}
+
+	// The old session cannot be refreshed into one with the old role
+	if rec := api.do(t, http.MethodPost, "/api/auth/refresh", "", gin.H{"refreshToken": userSession.RefreshToken}, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refreshing after a role change = %d, want 401", rec.Code)
+	}
+	reviewer := api.login(t, "user@example.com")
+	if rec := api.do(t, http.MethodGet, "/api/admin/admissions", reviewer.Token, nil, nil); rec.Code != http.StatusOK {
+		t.Errorf("listing admissions as the new reviewer = %d, want 200", rec.Code)
+	}
+}
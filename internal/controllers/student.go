@@ -1,22 +1,22 @@
 package controllers
 
 import (
-	"context"
+	"errors"
+	"log"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 
-	"admission-portal-backend/internal/config"
 	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/repositories"
 )
 
-func Signup(c *gin.Context) {
+func (h *Handler) Signup(c *gin.Context) {
 	var student models.Student
 	if err := c.ShouldBindJSON(&student); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -25,6 +25,7 @@ func Signup(c *gin.Context) {
 
 	// Always set role to 'student' for public signup
 	student.Role = "student"
+	student.EmailVerified = false
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(student.Password), bcrypt.DefaultCost)
@@ -38,21 +39,25 @@ func Signup(c *gin.Context) {
 	student.CreatedAt = time.Now()
 	student.UpdatedAt = time.Now()
 
-	// Insert into database
-	collection := config.GetCollection("students")
-	result, err := collection.InsertOne(context.Background(), student)
-	if err != nil {
+	if err := h.Students.Create(c.Request.Context(), &student); err != nil {
+		if errors.Is(err, repositories.ErrDuplicate) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating student"})
 		return
 	}
 
-	student.ID = result.InsertedID.(primitive.ObjectID)
+	if err := h.sendVerificationEmail(c.Request.Context(), &student); err != nil {
+		log.Printf("Error sending verification email to %s: %v", student.Email, err)
+	}
+
 	student.Password = "" // Don't send password back
 
 	c.JSON(http.StatusCreated, student)
 }
 
-func Login(c *gin.Context) {
+func (h *Handler) Login(c *gin.Context) {
 	var loginData struct {
 		Email    string `json:"email" binding:"required,email"`
 		Password string `json:"password" binding:"required"`
@@ -64,9 +69,7 @@ func Login(c *gin.Context) {
 	}
 
 	// Find student
-	collection := config.GetCollection("students")
-	var student models.Student
-	err := collection.FindOne(context.Background(), bson.M{"email": loginData.Email}).Decode(&student)
+	student, err := h.Students.FindByEmail(c.Request.Context(), loginData.Email)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
@@ -79,23 +82,17 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": student.ID.Hex(),
-		"role":    student.Role,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
-	})
-
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	// Start a session and issue its tokens
+	response, err := h.startSession(c, student)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while generating token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": tokenString})
+	c.JSON(http.StatusOK, response)
 }
 
-func GetProfile(c *gin.Context) {
+func (h *Handler) GetProfile(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	objectID, err := primitive.ObjectIDFromHex(userID.(string))
 	if err != nil {
@@ -103,9 +100,7 @@ func GetProfile(c *gin.Context) {
 		return
 	}
 
-	collection := config.GetCollection("students")
-	var student models.Student
-	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&student)
+	student, err := h.Students.FindByID(c.Request.Context(), objectID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
 		return
@@ -115,7 +110,7 @@ func GetProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, student)
 }
 
-func UpdateProfile(c *gin.Context) {
+func (h *Handler) UpdateProfile(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	objectID, err := primitive.ObjectIDFromHex(userID.(string))
 	if err != nil {
@@ -137,26 +132,31 @@ func UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"updated_at": time.Now(),
-		},
+	student, err := h.Students.FindByID(c.Request.Context(), objectID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while updating profile"})
+		return
 	}
 
+	student.UpdatedAt = time.Now()
 	if updateData.Name != "" {
-		update["$set"].(bson.M)["name"] = updateData.Name
+		student.Name = updateData.Name
 	}
 	if updateData.Phone != "" {
-		update["$set"].(bson.M)["phone"] = updateData.Phone
+		student.Phone = updateData.Phone
 	}
 	if updateData.DateOfBirth != "" {
-		update["$set"].(bson.M)["dateOfBirth"] = updateData.DateOfBirth
+		student.DateOfBirth = updateData.DateOfBirth
 	}
 	if updateData.Gender != "" {
-		update["$set"].(bson.M)["gender"] = updateData.Gender
+		student.Gender = updateData.Gender
 	}
 	if (updateData.Address != models.Address{}) {
-		update["$set"].(bson.M)["address"] = updateData.Address
+		student.Address = updateData.Address
 	}
 	if updateData.Password != "" {
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(updateData.Password), bcrypt.DefaultCost)
@@ -164,85 +164,38 @@ func UpdateProfile(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while hashing password"})
 			return
 		}
-		update["$set"].(bson.M)["password"] = string(hashedPassword)
+		student.Password = string(hashedPassword)
 	}
 
-	collection := config.GetCollection("students")
-	result, err := collection.UpdateOne(
-		context.Background(),
-		bson.M{"_id": objectID},
-		update,
-	)
-
-	if err != nil {
+	if err := h.Students.Update(c.Request.Context(), student); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while updating profile"})
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
-		return
-	}
-
 	c.JSON(http.StatusOK, gin.H{"message": "Profile updated successfully"})
 }
 
-func CreateAdmin(c *gin.Context) {
-	// Check for a secret key in the header
-	secret := c.GetHeader("X-Admin-Secret")
-	if secret != os.Getenv("ADMIN_SECRET") {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+func (h *Handler) ListAdmins(c *gin.Context) {
+	opts, ok := parseListQuery(c, adminListSpec)
+	if !ok {
 		return
 	}
 
-	var student models.Student
-	if err := c.ShouldBindJSON(&student); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Set role to admin
-	student.Role = "admin"
-
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(student.Password), bcrypt.DefaultCost)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while hashing password"})
-		return
-	}
-	student.Password = string(hashedPassword)
-
-	student.CreatedAt = time.Now()
-	student.UpdatedAt = time.Now()
-
-	collection := config.GetCollection("students")
-	result, err := collection.InsertOne(context.Background(), student)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating admin"})
-		return
+	staffRoles := []any{}
+	for _, role := range rbac.StaffRoles() {
+		staffRoles = append(staffRoles, role)
 	}
-
-	student.ID = result.InsertedID.(primitive.ObjectID)
-	student.Password = ""
-	c.JSON(http.StatusCreated, student)
-}
-
-func ListAdmins(c *gin.Context) {
-	collection := config.GetCollection("students")
-	cursor, err := collection.Find(context.Background(), bson.M{"role": "admin"})
+	admins, err := h.Students.List(c.Request.Context(), opts.Where("role", query.OpIn, staffRoles))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching admins"})
 		return
 	}
-	defer cursor.Close(context.Background())
-
-	var admins []models.Student
-	if err = cursor.All(context.Background(), &admins); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error decoding admins"})
-		return
-	}
-	for i := range admins {
-		admins[i].Password = "" // Don't expose password hashes
+	for i := range admins.Items {
+		admins.Items[i].Password = "" // Don't expose password hashes
 	}
 	c.JSON(http.StatusOK, admins)
 }
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/auth"
+	"admission-portal-backend/internal/mailer"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/repositories"
+)
+
+const emailVerificationTTL = 48 * time.Hour
+
+// sendVerificationEmail issues a fresh verification token for the student,
+// cancelling any earlier ones, and emails it.
+func (h *Handler) sendVerificationEmail(ctx context.Context, student *models.Student) error {
+	if err := h.Tokens.InvalidateForUser(ctx, student.ID, models.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	record := models.ActionToken{
+		UserID:    student.ID,
+		Purpose:   models.TokenPurposeEmailVerification,
+		TokenHash: hash,
+		ExpiresAt: now.Add(emailVerificationTTL),
+		CreatedAt: now,
+	}
+	if err := h.Tokens.Create(ctx, &record); err != nil {
+		return err
+	}
+
+	return h.Mailer.Send(ctx, mailer.Message{
+		To:      student.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nPlease confirm your email address by opening the link below. It expires in %d hours.\n\n%s\n",
+			student.Name, int(emailVerificationTTL.Hours()), appLink("/verify-email", url.Values{"token": {token}})),
+	})
+}
+
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	tokenString := c.Query("token")
+	if tokenString == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Verification token is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	token, err := h.Tokens.Consume(ctx, models.TokenPurposeEmailVerification, auth.HashToken(tokenString), time.Now())
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while verifying email"})
+		return
+	}
+
+	student, err := h.Students.FindByID(ctx, token.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+		return
+	}
+	student.EmailVerified = true
+	student.UpdatedAt = time.Now()
+	if err := h.Students.Update(ctx, student); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while verifying email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+func (h *Handler) ResendVerification(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	objectID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	student, err := h.Students.FindByID(c.Request.Context(), objectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return
+	}
+	if student.EmailVerified {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already verified"})
+		return
+	}
+
+	if err := h.sendVerificationEmail(c.Request.Context(), student); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while sending verification email"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
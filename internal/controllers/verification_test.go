@@ -0,0 +1,56 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSignupVerifiesEmailBeforeApplying(t *testing.T) {
+	api := newTestAPI(t)
+	signup := gin.H{"name": "Test Student", "email": "student@example.com", "password": testPassword}
+	if rec := api.do(t, http.MethodPost, "/api/students/signup", "", signup, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("signup = %d %s", rec.Code, rec.Body)
+	}
+	session := api.login(t, "student@example.com")
+	if rec := api.do(t, http.MethodPost, "/api/admissions/drafts", session.Token, gin.H{"courseId": primitive.NewObjectID().Hex()}, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("draft before verifying = %d, want 403", rec.Code)
+	}
+
+	link, token := api.mailedLink(t, "student@example.com")
+	if link.Path != "/verify-email" {
+		t.Errorf("verification link points at %s", link.Path)
+	}
+	verify := "/api/auth/verify?" + url.Values{"token": {token}}.Encode()
+	if rec := api.do(t, http.MethodGet, verify, "", nil, nil); rec.Code != http.StatusOK {
+		t.Fatalf("verify = %d %s", rec.Code, rec.Body)
+	}
+	if rec := api.do(t, http.MethodGet, verify, "", nil, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("verifying twice = %d, want 400", rec.Code)
+	}
+	if rec := api.do(t, http.MethodPost, "/api/auth/resend-verification", session.Token, nil, nil); rec.Code != http.StatusConflict {
+		t.Errorf("resend after verifying = %d, want 409", rec.Code)
+	}
+}
+
+func TestResendVerificationReplacesLink(t *testing.T) {
+	api := newTestAPI(t)
+	signup := gin.H{"name": "Test Student", "email": "student@example.com", "password": testPassword}
+	api.do(t, http.MethodPost, "/api/students/signup", "", signup, nil)
+	_, first := api.mailedLink(t, "student@example.com")
+	session := api.login(t, "student@example.com")
+	if rec := api.do(t, http.MethodPost, "/api/auth/resend-verification", session.Token, nil, nil); rec.Code != http.StatusOK {
+		t.Fatalf("resend = %d %s", rec.Code, rec.Body)
+	}
+	_, second := api.mailedLink(t, "student@example.com")
+
+	if rec := api.do(t, http.MethodGet, "/api/auth/verify?token="+url.QueryEscape(first), "", nil, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("superseded link = %d, want 400", rec.Code)
+	}
+	if rec := api.do(t, http.MethodGet, "/api/auth/verify?token="+url.QueryEscape(second), "", nil, nil); rec.Code != http.StatusOK {
+		t.Errorf("newest link = %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/services"
+)
+
+// GetWaitlistPosition shows a student where their waitlisted application
+// stands in line for its course.
+func (h *Handler) GetWaitlistPosition(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admission ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	admission, err := h.Admissions.FindByID(ctx, id)
+	if err != nil || !canSeeAdmission(admission, c.GetString("userID"), c.GetString("role")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admission not found"})
+		return
+	}
+
+	position, total, err := h.AdmissionService.WaitlistPosition(ctx, admission)
+	if errors.Is(err, services.ErrNotWaitlisted) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This application is not on a waitlist", "status": admission.Status})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"admissionId":  admission.ID,
+		"courseId":     admission.CourseID,
+		"cycle":        admission.Cycle,
+		"position":     position,
+		"total":        total,
+		"waitlistedAt": admission.WaitlistedAt,
+	})
+}
+
+// GetCourseWaitlist lists a course's waitlist in a cycle in the order seats
+// will be offered.
+func (h *Handler) GetCourseWaitlist(c *gin.Context) {
+	courseID, err := primitive.ObjectIDFromHex(c.Param("courseId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+	cycle := strings.ToLower(strings.TrimSpace(c.Query("cycle")))
+	ctx := c.Request.Context()
+	if cycle != "" {
+		if _, ok := h.findCycle(c, ctx, cycle); !ok {
+			return
+		}
+	}
+
+	waitlist, err := h.AdmissionService.Waitlist(ctx, cycle, courseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching waitlist"})
+		return
+	}
+	views, err := h.admissionViews(ctx, waitlist)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching waitlist"})
+		return
+	}
+
+	entries := make([]gin.H, 0, len(views))
+	for i, view := range views {
+		entries = append(entries, gin.H{
+			"position":     i + 1,
+			"admissionId":  view.ID,
+			"student":      view.Student,
+			"category":     view.Category,
+			"waitlistedAt": view.WaitlistedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"courseId": courseID, "cycle": cycle, "total": len(entries), "entries": entries})
+}
@@ -0,0 +1,113 @@
+// Package eligibility checks an applicant's academic record against a
+// course's eligibility criteria.
+package eligibility
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"admission-portal-backend/internal/models"
+)
+
+const (
+	RuleMinimumPercentage = "minimum_percentage"
+	RuleRequiredSubjects  = "required_subjects"
+	RuleEntranceExam      = "entrance_exam"
+)
+
+// Enforcement modes for EligibilityCriteria.Enforcement.
+const (
+	ModeReject = "reject"
+	ModeFlag   = "flag"
+)
+
+// Mode returns how ineligible applications to a course are handled.
+func Mode(criteria models.EligibilityCriteria) string {
+	if criteria.Enforcement == ModeFlag {
+		return ModeFlag
+	}
+	return ModeReject
+}
+
+// Evaluate checks details against every rule configured in criteria and
+// returns a per-rule breakdown. Rules that are not configured are skipped.
+func Evaluate(criteria models.EligibilityCriteria, details models.AcademicDetails) models.EligibilityResult {
+	rules := []models.EligibilityRuleResult{}
+
+	if criteria.MinimumPercentage > 0 {
+		rule := models.EligibilityRuleResult{
+			Rule:     RuleMinimumPercentage,
+			Passed:   details.Percentage >= criteria.MinimumPercentage,
+			Expected: fmt.Sprintf("at least %.2f%%", criteria.MinimumPercentage),
+			Actual:   fmt.Sprintf("%.2f%%", details.Percentage),
+		}
+		if !rule.Passed {
+			rule.Message = fmt.Sprintf("Percentage is %.2f points below the minimum", criteria.MinimumPercentage-details.Percentage)
+		}
+		rules = append(rules, rule)
+	}
+
+	if len(criteria.RequiredSubjects) > 0 {
+		missing := missingSubjects(criteria.RequiredSubjects, details.Subjects)
+		rule := models.EligibilityRuleResult{
+			Rule:     RuleRequiredSubjects,
+			Passed:   len(missing) == 0,
+			Expected: strings.Join(criteria.RequiredSubjects, ", "),
+			Actual:   strings.Join(details.Subjects, ", "),
+		}
+		if !rule.Passed {
+			rule.Message = "Missing subjects: " + strings.Join(missing, ", ")
+		}
+		rules = append(rules, rule)
+	}
+
+	if criteria.EntranceExam {
+		rule := models.EligibilityRuleResult{
+			Rule:     RuleEntranceExam,
+			Passed:   details.EntranceExamScore != nil,
+			Expected: "entrance exam score",
+			Actual:   "not provided",
+		}
+		if rule.Passed {
+			rule.Actual = fmt.Sprintf("%.2f", *details.EntranceExamScore)
+		} else {
+			rule.Message = "An entrance exam score is required for this course"
+		}
+		rules = append(rules, rule)
+	}
+
+	passed := 0
+	for _, rule := range rules {
+		if rule.Passed {
+			passed++
+		}
+	}
+	score := 100.0
+	if len(rules) > 0 {
+		score = float64(passed) * 100 / float64(len(rules))
+	}
+
+	return models.EligibilityResult{
+		Eligible:    passed == len(rules),
+		Score:       score,
+		Rules:       rules,
+		EvaluatedAt: time.Now(),
+	}
+}
+
+// missingSubjects returns the required subjects not present in taken,
+// compared case-insensitively.
+func missingSubjects(required, taken []string) []string {
+	have := make(map[string]bool, len(taken))
+	for _, subject := range taken {
+		have[strings.ToLower(strings.TrimSpace(subject))] = true
+	}
+	missing := []string{}
+	for _, subject := range required {
+		if !have[strings.ToLower(strings.TrimSpace(subject))] {
+			missing = append(missing, subject)
+		}
+	}
+	return missing
+}
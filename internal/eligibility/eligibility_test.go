@@ -0,0 +1,111 @@
+package eligibility
+
+import (
+	"reflect"
+	"testing"
+
+	"admission-portal-backend/internal/models"
+)
+
+func TestEvaluate(t *testing.T) {
+	score := 72.5
+	criteria := models.EligibilityCriteria{
+		MinimumPercentage: 60,
+		RequiredSubjects:  []string{"Mathematics", "Physics"},
+		EntranceExam:      true,
+	}
+	tests := []struct {
+		name     string
+		criteria models.EligibilityCriteria
+		details  models.AcademicDetails
+		eligible bool
+		score    float64
+		failed   []string
+	}{
+		{
+			name:     "no rules",
+			details:  models.AcademicDetails{Percentage: 10},
+			eligible: true,
+			score:    100,
+		},
+		{
+			name:     "meets every rule",
+			criteria: criteria,
+			details:  models.AcademicDetails{Percentage: 60, Subjects: []string{" physics", "MATHEMATICS", "Chemistry"}, EntranceExamScore: &score},
+			eligible: true,
+			score:    100,
+		},
+		{
+			name:     "below minimum",
+			criteria: criteria,
+			details:  models.AcademicDetails{Percentage: 59.99, Subjects: []string{"Mathematics", "Physics"}, EntranceExamScore: &score},
+			score:    200.0 / 3,
+			failed:   []string{RuleMinimumPercentage},
+		},
+		{
+			name:     "missing subject and exam",
+			criteria: criteria,
+			details:  models.AcademicDetails{Percentage: 90, Subjects: []string{"Mathematics"}},
+			score:    100.0 / 3,
+			failed:   []string{RuleRequiredSubjects, RuleEntranceExam},
+		},
+		{
+			name:     "only configured rules count",
+			criteria: models.EligibilityCriteria{EntranceExam: true},
+			details:  models.AcademicDetails{},
+			score:    0,
+			failed:   []string{RuleEntranceExam},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Evaluate(tt.criteria, tt.details)
+			if result.Eligible != tt.eligible {
+				t.Errorf("Eligible = %v, want %v", result.Eligible, tt.eligible)
+			}
+			if result.Score != tt.score {
+				t.Errorf("Score = %v, want %v", result.Score, tt.score)
+			}
+			failed := []string{}
+			for _, rule := range result.Rules {
+				if !rule.Passed {
+					failed = append(failed, rule.Rule)
+					if rule.Message == "" {
+						t.Errorf("rule %s failed without a message", rule.Rule)
+					}
+				}
+			}
+			if tt.failed == nil {
+				tt.failed = []string{}
+			}
+			if !reflect.DeepEqual(failed, tt.failed) {
+				t.Errorf("failed rules = %v, want %v", failed, tt.failed)
+			}
+		})
+	}
+}
+
+func TestMissingSubjectsMessage(t *testing.T) {
+	result := Evaluate(
+		models.EligibilityCriteria{RequiredSubjects: []string{"Mathematics", "Physics", "Chemistry"}},
+		models.AcademicDetails{Subjects: []string{"physics"}},
+	)
+	want := "Missing subjects: Mathematics, Chemistry"
+	if got := result.Rules[0].Message; got != want {
+		t.Errorf("Message = %q, want %q", got, want)
+	}
+}
+
+func TestMode(t *testing.T) {
+	tests := map[string]string{
+		"":        ModeReject,
+		"reject":  ModeReject,
+		"flag":    ModeFlag,
+		"unknown": ModeReject,
+	}
+	for enforcement, want := range tests {
+		if got := Mode(models.EligibilityCriteria{Enforcement: enforcement}); got != want {
+			t.Errorf("Mode(%q) = %q, want %q", enforcement, got, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Package merit ranks a course's applicants on weighted criteria to build
+// merit lists.
+package merit
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/workflow"
+)
+
+var (
+	ErrNegativeWeight = errors.New("merit weights cannot be negative")
+	ErrNoWeights      = errors.New("at least one merit weight must be positive")
+)
+
+// DefaultWeights ranks on percentage alone.
+var DefaultWeights = models.MeritWeights{Percentage: 1}
+
+// Validate checks that weights can produce a ranking.
+func Validate(weights models.MeritWeights) error {
+	if weights.Percentage < 0 || weights.EntranceExam < 0 || weights.Category < 0 {
+		return ErrNegativeWeight
+	}
+	for _, score := range weights.CategoryScores {
+		if score < 0 {
+			return ErrNegativeWeight
+		}
+	}
+	if weights.Percentage == 0 && weights.EntranceExam == 0 && weights.Category == 0 {
+		return ErrNoWeights
+	}
+	return nil
+}
+
+// IsCandidate reports whether an admission in the given status competes for
+// a place on the merit list. Drafts and closed applications do not.
+func IsCandidate(status string) bool {
+	switch workflow.Normalize(status) {
+	case workflow.StatusSubmitted, workflow.StatusUnderReview, workflow.StatusShortlisted,
+		workflow.StatusWaitlisted, workflow.StatusOffered, workflow.StatusAccepted, workflow.StatusEnrolled:
+		return true
+	}
+	return false
+}
+
+// Score returns the weighted score of an admission, rounded to six decimal
+// places so that equal inputs always tie regardless of float noise.
+func Score(admission *models.Admission, weights models.MeritWeights) float64 {
+	score := weights.Percentage * admission.AcademicDetails.Percentage
+	if exam := admission.AcademicDetails.EntranceExamScore; exam != nil {
+		score += weights.EntranceExam * *exam
+	}
+	score += weights.Category * weights.CategoryScores[admission.Category]
+	return math.Round(score*1e6) / 1e6
+}
+
+// Rank orders admissions by score and numbers them from 1. Ties are broken,
+// in order, by higher entrance exam score, higher percentage, earlier
+// application and finally admission ID, so the same input always produces
+// the same list. The first seats entries are marked WithinCutoff.
+func Rank(admissions []models.Admission, weights models.MeritWeights, seats int) []models.MeritEntry {
+	ranked := append([]models.Admission{}, admissions...)
+	scores := make(map[primitive.ObjectID]float64, len(ranked))
+	for i := range ranked {
+		scores[ranked[i].ID] = Score(&ranked[i], weights)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := &ranked[i], &ranked[j]
+		if scores[a.ID] != scores[b.ID] {
+			return scores[a.ID] > scores[b.ID]
+		}
+		if examA, examB := examScore(a), examScore(b); examA != examB {
+			return examA > examB
+		}
+		if a.AcademicDetails.Percentage != b.AcademicDetails.Percentage {
+			return a.AcademicDetails.Percentage > b.AcademicDetails.Percentage
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID.Hex() < b.ID.Hex()
+	})
+
+	entries := make([]models.MeritEntry, 0, len(ranked))
+	for i := range ranked {
+		admission := &ranked[i]
+		entries = append(entries, models.MeritEntry{
+			Rank:              i + 1,
+			AdmissionID:       admission.ID,
+			StudentID:         admission.StudentID,
+			Score:             scores[admission.ID],
+			Percentage:        admission.AcademicDetails.Percentage,
+			EntranceExamScore: admission.AcademicDetails.EntranceExamScore,
+			Category:          admission.Category,
+			WithinCutoff:      i < seats,
+		})
+	}
+	return entries
+}
+
+// examScore treats a missing entrance exam score as lower than any real one.
+func examScore(admission *models.Admission) float64 {
+	if admission.AcademicDetails.EntranceExamScore == nil {
+		return -1
+	}
+	return *admission.AcademicDetails.EntranceExamScore
+}
@@ -0,0 +1,106 @@
+package merit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/workflow"
+)
+
+func applicant(percentage float64, exam *float64, category string, createdAt time.Time) models.Admission {
+	return models.Admission{
+		ID:              primitive.NewObjectID(),
+		StudentID:       primitive.NewObjectID(),
+		Category:        category,
+		AcademicDetails: models.AcademicDetails{Percentage: percentage, EntranceExamScore: exam},
+		CreatedAt:       createdAt,
+	}
+}
+
+func score(v float64) *float64 { return &v }
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights models.MeritWeights
+		want    error
+	}{
+		{"default", DefaultWeights, nil},
+		{"exam only", models.MeritWeights{EntranceExam: 1}, nil},
+		{"no weights", models.MeritWeights{CategoryScores: map[string]float64{"sc": 5}}, ErrNoWeights},
+		{"negative weight", models.MeritWeights{Percentage: 1, EntranceExam: -1}, ErrNegativeWeight},
+		{"negative category score", models.MeritWeights{Category: 1, CategoryScores: map[string]float64{"sc": -5}}, ErrNegativeWeight},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.weights); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Validate = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	weights := models.MeritWeights{Percentage: 0.6, EntranceExam: 0.4, Category: 1, CategoryScores: map[string]float64{"sc": 5}}
+	tests := []struct {
+		name      string
+		admission models.Admission
+		want      float64
+	}{
+		{"all parts", applicant(80, score(90), "sc", time.Time{}), 0.6*80 + 0.4*90 + 5},
+		{"no exam", applicant(80, nil, "sc", time.Time{}), 0.6*80 + 5},
+		{"unlisted category", applicant(80, score(90), "general", time.Time{}), 0.6*80 + 0.4*90},
+		// 0.1 and 0.2 do not add up to 0.3 exactly in floating point
+		{"rounded", applicant(0.1, score(0.2), "", time.Time{}), 0.06 + 0.08},
+	}
+	for _, tt := range tests {
+		if got := Score(&tt.admission, weights); got != roundScore(tt.want) {
+			t.Errorf("%s: Score = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func roundScore(v float64) float64 {
+	return float64(int64(v*1e6+0.5)) / 1e6
+}
+
+func TestRank(t *testing.T) {
+	now := time.Now()
+	top := applicant(95, nil, "", now)
+	examTie := applicant(90, score(80), "", now) // same score as noExam, wins on exam
+	noExam := applicant(90, nil, "", now)        // same score, no exam
+	early := applicant(85, nil, "", now.Add(-time.Hour))
+	late := applicant(85, nil, "", now)
+
+	entries := Rank([]models.Admission{late, noExam, early, examTie, top}, DefaultWeights, 2)
+	want := []primitive.ObjectID{top.ID, examTie.ID, noExam.ID, early.ID, late.ID}
+	if len(entries) != len(want) {
+		t.Fatalf("Rank returned %d entries, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if entry.AdmissionID != want[i] {
+			t.Errorf("rank %d is %s, want %s", i+1, entry.AdmissionID.Hex(), want[i].Hex())
+		}
+		if entry.Rank != i+1 || entry.WithinCutoff != (i < 2) {
+			t.Errorf("entry %d = rank %d, within cutoff %v", i, entry.Rank, entry.WithinCutoff)
+		}
+	}
+}
+
+func TestIsCandidate(t *testing.T) {
+	for status, want := range map[string]bool{
+		workflow.StatusDraft:      false,
+		workflow.StatusSubmitted:  true,
+		workflow.StatusWaitlisted: true,
+		workflow.StatusEnrolled:   true,
+		workflow.StatusRejected:   false,
+		workflow.StatusWithdrawn:  false,
+		"pending":                 true,
+	} {
+		if got := IsCandidate(status); got != want {
+			t.Errorf("IsCandidate(%s) = %v, want %v", status, got, want)
+		}
+	}
+}
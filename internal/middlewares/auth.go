@@ -2,14 +2,18 @@ package middlewares
 
 import (
 	"net/http"
-	"os"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/auth"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/repositories"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(sessions repositories.SessionRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -26,39 +30,44 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		tokenString := parts[1]
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
+		claims, err := auth.ParseAccessToken(parts[1])
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// Set user ID and role in context
-			c.Set("userID", claims["user_id"])
-			c.Set("role", claims["role"])
-			c.Next()
-		} else {
+		// Reject tokens whose session was logged out or revoked
+		sessionID, err := primitive.ObjectIDFromHex(claims.SessionID)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 			c.Abort()
 			return
 		}
+		session, err := sessions.FindByID(c.Request.Context(), sessionID)
+		if err != nil || !session.Active(time.Now()) || session.UserID.Hex() != claims.UserID {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked or has expired"})
+			c.Abort()
+			return
+		}
+
+		// Set user ID, role and session in context
+		c.Set("userID", claims.UserID)
+		c.Set("role", claims.Role)
+		c.Set("sessionID", claims.SessionID)
+		c.Next()
 	}
 }
 
-func AdminOnly() gin.HandlerFunc {
+// RequirePermission only lets through callers whose role grants every one of
+// perms.
+func RequirePermission(perms ...rbac.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists || role != "admin" {
+		role, _ := c.Get("role")
+		roleName, _ := role.(string)
+		if !rbac.Can(roleName, perms...) {
 			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Only admin users can perform this action. If you believe this is a mistake, please contact support.",
+				"error": "You do not have permission to perform this action. If you believe this is a mistake, please contact support.",
 			})
 			c.Abort()
 			return
@@ -0,0 +1,150 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/repositories"
+)
+
+const (
+	idempotencyKeyHeader   = "Idempotency-Key"
+	idempotentReplayHeader = "Idempotent-Replayed"
+	// idempotencyKeyTTL is how long a key's response is kept for retries.
+	idempotencyKeyTTL = 24 * time.Hour
+	// maxIdempotencyKeyLength bounds the keys clients may send.
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotency makes POST requests sent with an Idempotency-Key header safe
+// to retry. The first request with a key runs as usual and its response is
+// stored; later requests from the same user with the key get that response
+// back, marked with Idempotent-Replayed, without running again. Reusing a
+// key for a different request is refused with 422, and a retry that arrives
+// while the first request is still running gets 409. Server errors are not
+// stored, so the request can be retried with the same key. The body is read
+// up front to fingerprint the request, so bodies larger than maxBody are
+// refused with 413. It must run after AuthMiddleware.
+func Idempotency(keys repositories.IdempotencyRepository, maxBody int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength || !printable(key) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be up to 255 printable characters"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBody))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Request body is larger than the %d byte limit", maxBody)})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now()
+		record := &models.IdempotencyRecord{
+			UserID:      c.GetString("userID"),
+			Key:         key,
+			RequestHash: requestFingerprint(c, body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(idempotencyKeyTTL),
+		}
+		ctx := c.Request.Context()
+		reserved, err := keys.Reserve(ctx, record)
+		switch {
+		case errors.Is(err, repositories.ErrDuplicate):
+			replayIdempotent(c, reserved, record.RequestHash)
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error while checking Idempotency-Key"})
+			c.Abort()
+			return
+		}
+
+		writer := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		completed := false
+		defer func() {
+			// Free the key if the handler failed or panicked, even when the
+			// client has gone and the request's context is cancelled
+			if !completed {
+				if err := keys.Release(context.Background(), reserved.ID); err != nil {
+					log.Printf("Error releasing idempotency key: %v", err)
+				}
+			}
+		}()
+
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		if err := keys.Complete(ctx, reserved.ID, status, writer.Header().Get("Content-Type"), writer.body.Bytes()); err != nil {
+			log.Printf("Error storing idempotent response: %v", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// replayIdempotent answers a request whose key was used before.
+func replayIdempotent(c *gin.Context, record *models.IdempotencyRecord, requestHash string) {
+	switch {
+	case record.RequestHash != requestHash:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "This Idempotency-Key was already used for a different request"})
+	case record.StatusCode == 0:
+		c.JSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still being processed"})
+	default:
+		c.Header(idempotentReplayHeader, "true")
+		c.Data(record.StatusCode, record.ContentType, record.Body)
+	}
+	c.Abort()
+}
+
+// requestFingerprint identifies what a request asks for: its method, path
+// and body.
+func requestFingerprint(c *gin.Context, body []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// capturingWriter keeps a copy of the response body as it is written.
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,240 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/repositories"
+)
+
+// idempotentServer routes POST and GET /things through Idempotency as the
+// user named in the X-User header. The handler answers with the status in
+// the X-Status header, 201 by default, and counts how often it runs. With
+// block set, it signals started and waits for block to close before
+// answering.
+func idempotentServer(t *testing.T, calls *int32, started, block chan struct{}) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", c.GetHeader("X-User"))
+	})
+	router.Use(Idempotency(repositories.NewMemoryStore().IdempotencyKeys, 64))
+	handler := func(c *gin.Context) {
+		n := atomic.AddInt32(calls, 1)
+		if block != nil {
+			started <- struct{}{}
+			<-block
+		}
+		status := http.StatusCreated
+		if c.GetHeader("X-Status") == "500" {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"call": n})
+	}
+	router.POST("/things", handler)
+	router.GET("/things", handler)
+	return router
+}
+
+type idempotentRequest struct {
+	method string
+	user   string
+	key    string
+	body   string
+	status string
+}
+
+func (r idempotentRequest) send(router *gin.Engine) *httptest.ResponseRecorder {
+	method := r.method
+	if method == "" {
+		method = http.MethodPost
+	}
+	user := r.user
+	if user == "" {
+		user = "u1"
+	}
+	req := httptest.NewRequest(method, "/things", strings.NewReader(r.body))
+	req.Header.Set("X-User", user)
+	req.Header.Set("X-Status", r.status)
+	if r.key != "" {
+		req.Header.Set(idempotencyKeyHeader, r.key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency(t *testing.T) {
+	type step struct {
+		req      idempotentRequest
+		status   int
+		body     string
+		replayed bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+		calls int32
+	}{
+		{
+			name: "retry replays the first response",
+			steps: []step{
+				{req: idempotentRequest{key: "k1", body: `{"a":1}`}, status: 201, body: `{"call":1}`},
+				{req: idempotentRequest{key: "k1", body: `{"a":1}`}, status: 201, body: `{"call":1}`, replayed: true},
+			},
+			calls: 1,
+		},
+		{
+			name: "without a key every request runs",
+			steps: []step{
+				{req: idempotentRequest{body: `{}`}, status: 201, body: `{"call":1}`},
+				{req: idempotentRequest{body: `{}`}, status: 201, body: `{"call":2}`},
+			},
+			calls: 2,
+		},
+		{
+			name: "other methods are not tracked",
+			steps: []step{
+				{req: idempotentRequest{method: http.MethodGet, key: "k1"}, status: 201, body: `{"call":1}`},
+				{req: idempotentRequest{method: http.MethodGet, key: "k1"}, status: 201, body: `{"call":2}`},
+			},
+			calls: 2,
+		},
+		{
+			name: "key reused for another body",
+			steps: []step{
+				{req: idempotentRequest{key: "k1", body: `{"a":1}`}, status: 201},
+				{req: idempotentRequest{key: "k1", body: `{"a":2}`}, status: 422},
+			},
+			calls: 1,
+		},
+		{
+			name: "keys are scoped to the user",
+			steps: []step{
+				{req: idempotentRequest{key: "k1", body: `{}`}, status: 201, body: `{"call":1}`},
+				{req: idempotentRequest{user: "u2", key: "k1", body: `{}`}, status: 201, body: `{"call":2}`},
+			},
+			calls: 2,
+		},
+		{
+			name: "server errors are not kept",
+			steps: []step{
+				{req: idempotentRequest{key: "k1", status: "500"}, status: 500},
+				{req: idempotentRequest{key: "k1"}, status: 201, body: `{"call":2}`},
+				{req: idempotentRequest{key: "k1"}, status: 201, body: `{"call":2}`, replayed: true},
+			},
+			calls: 2,
+		},
+		{
+			name: "invalid keys",
+			steps: []step{
+				{req: idempotentRequest{key: "has space"}, status: 400},
+				{req: idempotentRequest{key: strings.Repeat("k", maxIdempotencyKeyLength+1)}, status: 400},
+				{req: idempotentRequest{key: strings.Repeat("k", maxIdempotencyKeyLength)}, status: 201},
+			},
+			calls: 1,
+		},
+		{
+			name: "body over the limit",
+			steps: []step{
+				{req: idempotentRequest{key: "k1", body: strings.Repeat("x", 65)}, status: 413},
+				{req: idempotentRequest{key: "k1", body: strings.Repeat("x", 64)}, status: 201},
+			},
+			calls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			router := idempotentServer(t, &calls, nil, nil)
+			for i, step := range tt.steps {
+				w := step.req.send(router)
+				if w.Code != step.status {
+					t.Fatalf("step %d: status %d, want %d (%s)", i, w.Code, step.status, w.Body.String())
+				}
+				if step.body != "" && w.Body.String() != step.body {
+					t.Errorf("step %d: body %s, want %s", i, w.Body.String(), step.body)
+				}
+				if replayed := w.Header().Get(idempotentReplayHeader) == "true"; replayed != step.replayed {
+					t.Errorf("step %d: replayed %v, want %v", i, replayed, step.replayed)
+				}
+			}
+			if calls != tt.calls {
+				t.Errorf("handler ran %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	var calls int32
+	started, block := make(chan struct{}), make(chan struct{})
+	router := idempotentServer(t, &calls, started, block)
+	req := idempotentRequest{key: "k1", body: `{}`}
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- req.send(router) }()
+	<-started
+
+	if w := req.send(router); w.Code != http.StatusConflict {
+		t.Errorf("retry while running: status %d, want 409", w.Code)
+	}
+	close(block)
+	if w := <-first; w.Code != http.StatusCreated {
+		t.Errorf("first request: status %d, want 201", w.Code)
+	}
+	if w := req.send(router); w.Header().Get(idempotentReplayHeader) != "true" {
+		t.Errorf("retry after completion was not replayed")
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
+
+// contextKeys refuses to release keys once the caller's context is done,
+// as a database client would.
+type contextKeys struct {
+	repositories.IdempotencyRepository
+}
+
+func (k contextKeys) Release(ctx context.Context, id primitive.ObjectID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return k.IdempotencyRepository.Release(ctx, id)
+}
+
+func TestIdempotencyReleasesAfterDisconnect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userID", "u1") })
+	router.Use(Idempotency(contextKeys{repositories.NewMemoryStore().IdempotencyKeys}, 64))
+	var calls int32
+	router.POST("/things", func(c *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"})
+	})
+
+	// The client gives up while the request fails
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(`{}`)).WithContext(ctx)
+	req.Header.Set(idempotencyKeyHeader, "k1")
+	cancel()
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	retry := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(`{}`))
+	retry.Header.Set(idempotencyKeyHeader, "k1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, retry)
+	if w.Code != http.StatusInternalServerError || calls != 2 {
+		t.Errorf("retry after a disconnect: status %d after %d calls, want the handler to run again", w.Code, calls)
+	}
+}
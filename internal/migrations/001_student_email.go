@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// studentEmailIndex makes email unique across accounts and serves the
+// lookup done on every login.
+var studentEmailIndex = Migration{
+	Version: 1,
+	Name:    "students_email_unique",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		if err := checkDuplicateEmails(ctx, db); err != nil {
+			return err
+		}
+		return createIndexes(ctx, db, "students", mongo.IndexModel{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetName("email").SetUnique(true),
+		})
+	},
+	Down: func(ctx context.Context, db *mongo.Database) error {
+		return dropIndexes(ctx, db, "students", "email")
+	},
+}
+
+// checkDuplicateEmails fails, naming the addresses, when accounts already
+// share an email, since the unique index cannot be built until they are
+// merged or renamed by hand.
+func checkDuplicateEmails(ctx context.Context, db *mongo.Database) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$email", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+		{{Key: "$limit", Value: 10}},
+	}
+	cursor, err := db.Collection("students").Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	var duplicates []struct {
+		Email string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &duplicates); err != nil {
+		return err
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+	emails := make([]string, 0, len(duplicates))
+	for _, d := range duplicates {
+		emails = append(emails, fmt.Sprintf("%s (%d accounts)", d.Email, d.Count))
+	}
+	return fmt.Errorf("accounts share an email, merge or rename them first: %s", strings.Join(emails, ", "))
+}
@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// admissionIndexes serves a student's own applications, keeps one
+// application per student, course and cycle, and covers the waitlist and
+// offer expiry queries.
+var admissionIndexes = Migration{
+	Version: 2,
+	Name:    "admissions_indexes",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		if err := checkDuplicateApplications(ctx, db); err != nil {
+			return err
+		}
+		return createIndexes(ctx, db, "admissions",
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "studentId", Value: 1}, {Key: "createdAt", Value: -1}},
+				Options: options.Index().SetName("student_created"),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "studentId", Value: 1}, {Key: "courseId", Value: 1}, {Key: "cycle", Value: 1}},
+				Options: options.Index().SetName("student_course_cycle").SetUnique(true),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "status", Value: 1}, {Key: "courseId", Value: 1}, {Key: "cycle", Value: 1}, {Key: "waitlistedAt", Value: 1}, {Key: "createdAt", Value: 1}},
+				Options: options.Index().SetName("status_course_cycle_waitlisted"),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "status", Value: 1}, {Key: "offerExpiresAt", Value: 1}},
+				Options: options.Index().SetName("status_offer_expires"),
+			},
+		)
+	},
+	Down: func(ctx context.Context, db *mongo.Database) error {
+		return dropIndexes(ctx, db, "admissions", "student_created", "student_course_cycle", "status_course_cycle_waitlisted", "status_offer_expires")
+	},
+}
+
+// checkDuplicateApplications fails, naming the admissions, when a student
+// applied to the same course more than once in a cycle, which was allowed
+// before duplicates were rejected. The unique index cannot be built until
+// all but one of each group are removed by hand.
+func checkDuplicateApplications(ctx context.Context, db *mongo.Database) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.M{"createdAt": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"studentId": "$studentId", "courseId": "$courseId", "cycle": "$cycle"},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+		{{Key: "$limit", Value: 10}},
+	}
+	cursor, err := db.Collection("admissions").Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	var duplicates []struct {
+		IDs []primitive.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &duplicates); err != nil {
+		return err
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+	groups := make([]string, 0, len(duplicates))
+	for _, d := range duplicates {
+		ids := make([]string, 0, len(d.IDs))
+		for _, id := range d.IDs {
+			ids = append(ids, id.Hex())
+		}
+		groups = append(groups, "["+strings.Join(ids, ", ")+"]")
+	}
+	return fmt.Errorf("students applied to the same course more than once in a cycle; keep one admission of each group, oldest first, and delete the others: %s", strings.Join(groups, " "))
+}
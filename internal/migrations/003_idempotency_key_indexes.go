@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// idempotencyKeyIndexes scopes Idempotency-Key values to their user and lets
+// MongoDB delete them once they expire.
+var idempotencyKeyIndexes = Migration{
+	Version: 3,
+	Name:    "idempotency_keys_indexes",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		return createIndexes(ctx, db, "idempotency_keys",
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "key", Value: 1}},
+				Options: options.Index().SetName("user_key").SetUnique(true),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+				Options: options.Index().SetName("expires").SetExpireAfterSeconds(0),
+			},
+		)
+	},
+	Down: func(ctx context.Context, db *mongo.Database) error {
+		return dropIndexes(ctx, db, "idempotency_keys", "user_key", "expires")
+	},
+}
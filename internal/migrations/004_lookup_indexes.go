@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// lookupIndex is an index lookupIndexes creates, with the collection it
+// belongs to.
+type lookupIndex struct {
+	collection string
+	model      mongo.IndexModel
+}
+
+// lookups are the fields the other repositories look documents up by.
+var lookups = []lookupIndex{
+	{"sessions", mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "revokedAt", Value: 1}},
+		Options: options.Index().SetName("user_revoked"),
+	}},
+	{"action_tokens", mongo.IndexModel{
+		Keys:    bson.D{{Key: "purpose", Value: 1}, {Key: "tokenHash", Value: 1}},
+		Options: options.Index().SetName("purpose_token"),
+	}},
+	{"action_tokens", mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "purpose", Value: 1}, {Key: "usedAt", Value: 1}},
+		Options: options.Index().SetName("user_purpose_used"),
+	}},
+	{"documents", mongo.IndexModel{
+		Keys:    bson.D{{Key: "ownerId", Value: 1}},
+		Options: options.Index().SetName("owner"),
+	}},
+	{"documents", mongo.IndexModel{
+		Keys:    bson.D{{Key: "admissionId", Value: 1}},
+		Options: options.Index().SetName("admission"),
+	}},
+	{"payments", mongo.IndexModel{
+		Keys:    bson.D{{Key: "intentId", Value: 1}},
+		Options: options.Index().SetName("intent"),
+	}},
+	{"payments", mongo.IndexModel{
+		Keys:    bson.D{{Key: "admissionId", Value: 1}},
+		Options: options.Index().SetName("admission"),
+	}},
+	{"merit_lists", mongo.IndexModel{
+		Keys:    bson.D{{Key: "courseId", Value: 1}, {Key: "cycle", Value: 1}, {Key: "version", Value: -1}},
+		Options: options.Index().SetName("course_cycle_version").SetUnique(true),
+	}},
+	{"admission_cycles", mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetName("code").SetUnique(true),
+	}},
+	{"cycle_seats", mongo.IndexModel{
+		Keys:    bson.D{{Key: "cycle", Value: 1}, {Key: "courseId", Value: 1}},
+		Options: options.Index().SetName("cycle_course"),
+	}},
+}
+
+var lookupIndexes = Migration{
+	Version: 4,
+	Name:    "lookup_indexes",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		for _, index := range lookups {
+			if err := createIndexes(ctx, db, index.collection, index.model); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(ctx context.Context, db *mongo.Database) error {
+		for _, index := range lookups {
+			if err := dropIndexes(ctx, db, index.collection, *index.model.Options.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"admission-portal-backend/internal/workflow"
+)
+
+// backfillSubmittedAt sets submittedAt on applications made before drafts
+// had it. Anything past draft was submitted when its history says so, or
+// when it was created. Drafts carrying a fee were complete applications
+// waiting on payment, so they count as submitted when created and stay
+// locked against edits.
+var backfillSubmittedAt = Migration{
+	Version: 5,
+	Name:    "backfill_admissions_submitted_at",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		admissions := db.Collection("admissions")
+		submitted := bson.M{"$ifNull": bson.A{historyChangedAt(workflow.StatusSubmitted, 0), "$createdAt"}}
+		filter := bson.M{"status": bson.M{"$ne": workflow.StatusDraft}, "submittedAt": bson.M{"$exists": false}}
+		update := bson.A{bson.M{"$set": bson.M{"submittedAt": submitted}}}
+		if _, err := admissions.UpdateMany(ctx, filter, update); err != nil {
+			return fmt.Errorf("backfilling submitted applications: %w", err)
+		}
+
+		filter = bson.M{
+			"status":      workflow.StatusDraft,
+			"submittedAt": bson.M{"$exists": false},
+			"$or":         bson.A{bson.M{"applicationFee": bson.M{"$gt": 0}}, bson.M{"lateFee": bson.M{"$gt": 0}}},
+		}
+		update = bson.A{bson.M{"$set": bson.M{"submittedAt": "$createdAt"}}}
+		if _, err := admissions.UpdateMany(ctx, filter, update); err != nil {
+			return fmt.Errorf("backfilling unpaid applications: %w", err)
+		}
+		return nil
+	},
+	// Older code ignores submittedAt, so there is nothing to undo
+	Down: func(ctx context.Context, db *mongo.Database) error { return nil },
+}
+
+// historyChangedAt is an aggregation expression for when an admission's
+// status history first (index 0) or last (index -1) moved to status, or
+// null if it never did.
+func historyChangedAt(status string, index int) bson.M {
+	matching := bson.M{"$filter": bson.M{
+		"input": bson.M{"$ifNull": bson.A{"$statusHistory", bson.A{}}},
+		"as":    "change",
+		"cond":  bson.M{"$eq": bson.A{"$$change.to", status}},
+	}}
+	return bson.M{"$let": bson.M{
+		"vars": bson.M{"change": bson.M{"$arrayElemAt": bson.A{matching, index}}},
+		"in":   "$$change.changedAt",
+	}}
+}
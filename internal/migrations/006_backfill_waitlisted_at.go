@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"admission-portal-backend/internal/workflow"
+)
+
+// backfillWaitlistedAt dates the waitlisted applications that predate
+// waitlistedAt from the last time their history moved them to the
+// waitlist, so they keep their place in line.
+var backfillWaitlistedAt = Migration{
+	Version: 6,
+	Name:    "backfill_admissions_waitlisted_at",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		waitlisted := bson.M{"$ifNull": bson.A{historyChangedAt(workflow.StatusWaitlisted, -1), "$updatedAt"}}
+		filter := bson.M{"status": workflow.StatusWaitlisted, "waitlistedAt": bson.M{"$exists": false}}
+		update := bson.A{bson.M{"$set": bson.M{"waitlistedAt": waitlisted}}}
+		_, err := db.Collection("admissions").UpdateMany(ctx, filter, update)
+		return err
+	},
+	// Older code ignores waitlistedAt, so there is nothing to undo
+	Down: func(ctx context.Context, db *mongo.Database) error { return nil },
+}
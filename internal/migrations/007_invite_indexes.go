@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// inviteIndexes serves the pending invite check made when inviting an
+// email, and the search for bootstrap invites at startup.
+var inviteIndexes = Migration{
+	Version: 7,
+	Name:    "invites_indexes",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		return createIndexes(ctx, db, "invites",
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "email", Value: 1}},
+				Options: options.Index().SetName("email"),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "bootstrap", Value: 1}},
+				Options: options.Index().SetName("bootstrap").SetSparse(true),
+			},
+		)
+	},
+	Down: func(ctx context.Context, db *mongo.Database) error {
+		return dropIndexes(ctx, db, "invites", "email", "bootstrap")
+	},
+}
@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"admission-portal-backend/internal/workflow"
+)
+
+// backfillCourseSeatsFilled brings each course's seatsFilled in line with
+// the applications holding its seats. Applications approved before seats
+// were counted never reserved one, so they are first relabelled as the
+// offers they are; seatsFilled is then recounted from every offered,
+// accepted and enrolled application outside a cycle. Applications in a
+// cycle count against the cycle's seat matrix instead and are left alone.
+var backfillCourseSeatsFilled = Migration{
+	Version: 8,
+	Name:    "backfill_courses_seats_filled",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		admissions := db.Collection("admissions")
+		noCycle := bson.M{"$in": bson.A{nil, ""}}
+		filter := bson.M{"status": "approved", "cycle": noCycle}
+		update := bson.M{"$set": bson.M{"status": workflow.StatusOffered}}
+		if _, err := admissions.UpdateMany(ctx, filter, update); err != nil {
+			return fmt.Errorf("relabelling approved applications: %w", err)
+		}
+
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{
+				"cycle":  noCycle,
+				"status": bson.M{"$in": bson.A{workflow.StatusOffered, workflow.StatusAccepted, workflow.StatusEnrolled}},
+			}}},
+			{{Key: "$group", Value: bson.M{"_id": "$courseId", "filled": bson.M{"$sum": 1}}}},
+		}
+		cursor, err := admissions.Aggregate(ctx, pipeline)
+		if err != nil {
+			return fmt.Errorf("counting seats held: %w", err)
+		}
+		var counts []struct {
+			CourseID primitive.ObjectID `bson:"_id"`
+			Filled   int                `bson:"filled"`
+		}
+		if err := cursor.All(ctx, &counts); err != nil {
+			return fmt.Errorf("counting seats held: %w", err)
+		}
+
+		courses := db.Collection("courses")
+		counted := bson.A{}
+		for _, count := range counts {
+			counted = append(counted, count.CourseID)
+			update := bson.M{"$set": bson.M{"seatsFilled": count.Filled}}
+			if _, err := courses.UpdateOne(ctx, bson.M{"_id": count.CourseID}, update); err != nil {
+				return fmt.Errorf("setting seats filled on course %s: %w", count.CourseID.Hex(), err)
+			}
+		}
+		update = bson.M{"$set": bson.M{"seatsFilled": 0}}
+		if _, err := courses.UpdateMany(ctx, bson.M{"_id": bson.M{"$nin": counted}}, update); err != nil {
+			return fmt.Errorf("clearing seats filled: %w", err)
+		}
+		return nil
+	},
+	// Every version that reads seatsFilled reads offered the same as the
+	// approved it replaced, so there is nothing to undo
+	Down: func(ctx context.Context, db *mongo.Database) error { return nil },
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// backfillEmailVerified marks accounts created before email verification
+// as verified. Every account since has emailVerified stored, so a missing
+// field means the account predates the check and was never sent a link;
+// without this its owner could no longer apply for admission.
+var backfillEmailVerified = Migration{
+	Version: 9,
+	Name:    "backfill_students_email_verified",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		filter := bson.M{"emailVerified": bson.M{"$exists": false}}
+		update := bson.M{"$set": bson.M{"emailVerified": true}}
+		_, err := db.Collection("students").UpdateMany(ctx, filter, update)
+		return err
+	},
+	// Older code ignores emailVerified, so there is nothing to undo
+	Down: func(ctx context.Context, db *mongo.Database) error { return nil },
+}
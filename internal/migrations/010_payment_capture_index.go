@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// paymentCaptureIndex allows one captured payment per admission and fee,
+// so two callbacks racing to capture payments for the same fee cannot both
+// collect it.
+var paymentCaptureIndex = Migration{
+	Version: 10,
+	Name:    "payments_captured_unique",
+	Up: func(ctx context.Context, db *mongo.Database) error {
+		if err := checkDuplicateCaptures(ctx, db); err != nil {
+			return err
+		}
+		return createIndexes(ctx, db, "payments", mongo.IndexModel{
+			Keys: bson.D{{Key: "admissionId", Value: 1}, {Key: "purpose", Value: 1}},
+			Options: options.Index().SetName("admission_purpose_captured").SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": "captured"}),
+		})
+	},
+	Down: func(ctx context.Context, db *mongo.Database) error {
+		return dropIndexes(ctx, db, "payments", "admission_purpose_captured")
+	},
+}
+
+// checkDuplicateCaptures fails, naming the payments, when a fee was
+// captured more than once for an admission. The extra payments must be
+// refunded, which marks them refunded, before the index can be built.
+func checkDuplicateCaptures(ctx context.Context, db *mongo.Database) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": "captured"}}},
+		{{Key: "$sort", Value: bson.M{"capturedAt": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"admissionId": "$admissionId", "purpose": "$purpose"},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+		{{Key: "$limit", Value: 10}},
+	}
+	cursor, err := db.Collection("payments").Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	var duplicates []struct {
+		IDs []primitive.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &duplicates); err != nil {
+		return err
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+	groups := make([]string, 0, len(duplicates))
+	for _, d := range duplicates {
+		ids := make([]string, 0, len(d.IDs))
+		for _, id := range d.IDs {
+			ids = append(ids, id.Hex())
+		}
+		groups = append(groups, "["+strings.Join(ids, ", ")+"]")
+	}
+	return fmt.Errorf("fees were captured more than once for an admission; keep the first payment of each group and refund the others in full: %s", strings.Join(groups, " "))
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Server error codes dropIndexes tolerates, so rolling back an index that
+// is already gone succeeds.
+const (
+	codeNamespaceNotFound = 26
+	codeIndexNotFound     = 27
+)
+
+// createIndexes creates indexes on collection. Creating an index that
+// already exists with the same name and keys does nothing.
+func createIndexes(ctx context.Context, db *mongo.Database, collection string, indexes ...mongo.IndexModel) error {
+	if _, err := db.Collection(collection).Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("creating indexes on %s: %w", collection, err)
+	}
+	return nil
+}
+
+// dropIndexes drops the named indexes from collection, skipping any that
+// do not exist.
+func dropIndexes(ctx context.Context, db *mongo.Database, collection string, names ...string) error {
+	for _, name := range names {
+		_, err := db.Collection(collection).Indexes().DropOne(ctx, name)
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && (cmdErr.Code == codeIndexNotFound || cmdErr.Code == codeNamespaceNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("dropping index %s on %s: %w", name, collection, err)
+		}
+	}
+	return nil
+}
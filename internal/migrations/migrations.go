@@ -0,0 +1,58 @@
+// Package migrations brings a MongoDB database's indexes and data up to what
+// the current code expects. Each change is a numbered Migration; the
+// versions applied to a database are recorded in its schema_migrations
+// collection so every migration runs once, in order, and can be rolled back.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migration is one versioned change to the database.
+type Migration struct {
+	// Version orders migrations. Versions are never reused or renumbered
+	// once released.
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+	// Down undoes Up. It is nil for migrations that cannot be undone.
+	Down func(ctx context.Context, db *mongo.Database) error
+}
+
+var (
+	// ErrIrreversible is returned when rolling back a migration without a
+	// Down step.
+	ErrIrreversible = errors.New("migration cannot be rolled back")
+	// ErrUnknownMigration is returned when rolling back a version this build
+	// does not know, usually one applied by a newer release.
+	ErrUnknownMigration = errors.New("migration is not known to this build")
+	// ErrLocked is returned when another process kept the migration lock
+	// for longer than the runner was willing to wait.
+	ErrLocked = errors.New("migrations are being run by another process")
+)
+
+// registry lists every migration. Add new ones at the end with the next
+// version.
+var registry = []Migration{
+	studentEmailIndex,
+	admissionIndexes,
+	idempotencyKeyIndexes,
+	lookupIndexes,
+	backfillSubmittedAt,
+	backfillWaitlistedAt,
+	inviteIndexes,
+	backfillCourseSeatsFilled,
+	backfillEmailVerified,
+	paymentCaptureIndex,
+}
+
+// All returns the known migrations ordered by version.
+func All() []Migration {
+	migrations := append([]Migration(nil), registry...)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import "testing"
+
+func TestRegistry(t *testing.T) {
+	names := map[string]int{}
+	for i, m := range registry {
+		// Versions are numbered from 1 with no gaps, in the order they were added
+		if m.Version != i+1 {
+			t.Errorf("registry[%d] is version %d, want %d", i, m.Version, i+1)
+		}
+		if m.Name == "" || m.Up == nil {
+			t.Errorf("migration %d needs a name and an Up step", m.Version)
+		}
+		if other, ok := names[m.Name]; ok {
+			t.Errorf("migrations %d and %d are both named %q", other, m.Version, m.Name)
+		}
+		names[m.Name] = m.Version
+	}
+
+	all := All()
+	if len(all) != len(registry) {
+		t.Fatalf("All returned %d migrations, want %d", len(all), len(registry))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].Version <= all[i-1].Version {
+			t.Errorf("All is out of order: version %d follows %d", all[i].Version, all[i-1].Version)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// collectionName holds one document per applied version, plus the lock.
+	collectionName = "schema_migrations"
+	lockID         = "lock"
+	// staleLockAge is how old a lock must be before it is treated as left
+	// behind by a run that died.
+	staleLockAge = 15 * time.Minute
+	// DefaultLockWait is how long NewRunner's runner waits for another
+	// process to finish migrating.
+	DefaultLockWait = time.Minute
+)
+
+// record is what schema_migrations keeps for an applied version.
+type record struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"appliedAt"`
+}
+
+// Status describes one migration for reporting.
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+	// Unknown marks a version applied to the database that this build has
+	// no migration for.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// Runner applies and rolls back migrations against a database.
+type Runner struct {
+	db         *mongo.Database
+	migrations []Migration
+	// LockWait is how long Up and Down wait for a concurrent run to finish
+	// before giving up with ErrLocked.
+	LockWait time.Duration
+}
+
+// NewRunner returns a Runner for db with every known migration.
+func NewRunner(db *mongo.Database) *Runner {
+	return &Runner{db: db, migrations: All(), LockWait: DefaultLockWait}
+}
+
+func (r *Runner) collection() *mongo.Collection {
+	return r.db.Collection(collectionName)
+}
+
+// applied loads the versions applied to the database, keyed by version.
+func (r *Runner) applied(ctx context.Context) (map[int]record, error) {
+	// Only version records have numeric ids; the lock's is a string
+	cursor, err := r.collection().Find(ctx, bson.M{"_id": bson.M{"$type": "number"}})
+	if err != nil {
+		return nil, err
+	}
+	var records []record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	applied := make(map[int]record, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+	return applied, nil
+}
+
+// Status reports every known migration and whether it has been applied,
+// followed by any applied versions this build does not know.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(r.migrations))
+	for _, m := range r.migrations {
+		status := Status{Version: m.Version, Name: m.Name}
+		if rec, ok := applied[m.Version]; ok {
+			appliedAt := rec.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			delete(applied, m.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, rec := range sortedRecords(applied, false) {
+		appliedAt := rec.AppliedAt
+		statuses = append(statuses, Status{Version: rec.Version, Name: rec.Name, Applied: true, AppliedAt: &appliedAt, Unknown: true})
+	}
+	return statuses, nil
+}
+
+// Pending returns the known migrations not yet applied, in order.
+func (r *Runner) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range r.migrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration in version order and returns those it
+// applied. It stops at the first failure; the migrations before it stay
+// applied.
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	pending, err := r.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for _, m := range pending {
+		started := time.Now()
+		if err := m.Up(ctx, r.db); err != nil {
+			return done, fmt.Errorf("applying migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		rec := record{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}
+		if _, err := r.collection().InsertOne(ctx, rec); err != nil {
+			return done, fmt.Errorf("recording migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		log.Printf("Applied migration %d (%s) in %s", m.Version, m.Name, time.Since(started).Round(time.Millisecond))
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Down rolls back the steps most recently applied migrations, newest first,
+// and returns those it rolled back.
+func (r *Runner) Down(ctx context.Context, steps int) ([]Migration, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int]Migration, len(r.migrations))
+	for _, m := range r.migrations {
+		known[m.Version] = m
+	}
+
+	var done []Migration
+	for _, rec := range sortedRecords(applied, true) {
+		if len(done) == steps {
+			break
+		}
+		m, ok := known[rec.Version]
+		if !ok {
+			return done, fmt.Errorf("rolling back migration %d (%s): %w", rec.Version, rec.Name, ErrUnknownMigration)
+		}
+		if m.Down == nil {
+			return done, fmt.Errorf("rolling back migration %d (%s): %w", m.Version, m.Name, ErrIrreversible)
+		}
+		if err := m.Down(ctx, r.db); err != nil {
+			return done, fmt.Errorf("rolling back migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		if _, err := r.collection().DeleteOne(ctx, bson.M{"_id": m.Version}); err != nil {
+			return done, fmt.Errorf("unrecording migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		log.Printf("Rolled back migration %d (%s)", m.Version, m.Name)
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// lock takes the migration lock so that only one process migrates at a
+// time, waiting up to LockWait for another holder to let go. While held,
+// the lock is refreshed every staleLockAge/3 so a long migration is never
+// mistaken for a dead one. It returns the function that releases the lock.
+func (r *Runner) lock(ctx context.Context) (func(), error) {
+	host, _ := os.Hostname()
+	// owner tells this run's lock apart from one taken over after it
+	owner := primitive.NewObjectID().Hex()
+	deadline := time.Now().Add(r.LockWait)
+	for {
+		now := time.Now()
+		// Take over a lock left behind by a run that died
+		stale := bson.M{"_id": lockID, "lockedAt": bson.M{"$lt": now.Add(-staleLockAge)}}
+		if _, err := r.collection().DeleteOne(ctx, stale); err != nil {
+			return nil, err
+		}
+		_, err := r.collection().InsertOne(ctx, bson.M{"_id": lockID, "owner": owner, "lockedAt": now, "host": host, "pid": os.Getpid()})
+		if err == nil {
+			stop, stopped := make(chan struct{}), make(chan struct{})
+			go r.refreshLock(owner, stop, stopped)
+			release := func() {
+				close(stop)
+				<-stopped
+				// The caller's context may be done; release regardless
+				if _, err := r.collection().DeleteOne(context.Background(), bson.M{"_id": lockID, "owner": owner}); err != nil {
+					log.Printf("Error releasing migration lock: %v", err)
+				}
+			}
+			return release, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrLocked
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// refreshLock moves the lock's lockedAt forward every staleLockAge/3 until
+// stop is closed, then closes stopped.
+func (r *Runner) refreshLock(owner string, stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(staleLockAge / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			result, err := r.collection().UpdateOne(context.Background(), bson.M{"_id": lockID, "owner": owner}, bson.M{"$set": bson.M{"lockedAt": now}})
+			switch {
+			case err != nil:
+				log.Printf("Error refreshing migration lock: %v", err)
+			case result.MatchedCount == 0:
+				log.Printf("Migration lock was taken over by another process")
+			}
+		}
+	}
+}
+
+// sortedRecords returns applied's records by version, newest first when
+// descending.
+func sortedRecords(applied map[int]record, descending bool) []record {
+	records := make([]record, 0, len(applied))
+	for _, rec := range applied {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if descending {
+			return records[i].Version > records[j].Version
+		}
+		return records[i].Version < records[j].Version
+	})
+	return records
+}
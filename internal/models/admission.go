@@ -28,7 +28,10 @@ type AcademicDetails struct {
 	Institution          string   `bson:"institution" json:"institution"`
 	YearOfCompletion     int      `bson:"yearOfCompletion" json:"yearOfCompletion"`
 	Percentage           float64  `bson:"percentage" json:"percentage"`
-	Documents            []string `bson:"documents" json:"documents"`
+	Subjects             []string `bson:"subjects" json:"subjects"`
+	// EntranceExamScore is nil when the applicant has not taken the exam.
+	EntranceExamScore *float64 `bson:"entranceExamScore,omitempty" json:"entranceExamScore,omitempty"`
+	Documents         []string `bson:"documents" json:"documents"`
 }
 
 type Documents struct {
@@ -36,8 +39,60 @@ type Documents struct {
 	IDProof                   string   `bson:"idProof" json:"idProof"`
 	AddressProof              string   `bson:"addressProof" json:"addressProof"`
 	QualificationCertificates []string `bson:"qualificationCertificates" json:"qualificationCertificates"`
+	// CategoryCertificate backs the applicant's reservation category. It is
+	// required for every category except general.
+	CategoryCertificate string `bson:"categoryCertificate,omitempty" json:"categoryCertificate,omitempty"`
 }
 
+// StatusChange records a single move in an admission's lifecycle.
+type StatusChange struct {
+	From      string             `bson:"from" json:"from"`
+	To        string             `bson:"to" json:"to"`
+	ChangedBy primitive.ObjectID `bson:"changedBy,omitempty" json:"changedBy,omitempty"`
+	Role      string             `bson:"role" json:"role"`
+	Comments  string             `bson:"comments,omitempty" json:"comments,omitempty"`
+	// SeatQuota is the quota of the seat the admission holds after the
+	// change, if any.
+	SeatQuota string `bson:"seatQuota,omitempty" json:"seatQuota,omitempty"`
+	// OfferExpiresAt is the deadline to answer the offer the change made,
+	// if it made one.
+	OfferExpiresAt *time.Time `bson:"offerExpiresAt,omitempty" json:"offerExpiresAt,omitempty"`
+	ChangedAt      time.Time  `bson:"changedAt" json:"changedAt"`
+}
+
+// Preference statuses. A preference starts pending, or ineligible when the
+// applicant fails a course's rules; allocation then marks it allocated, or
+// no_seats / unavailable when the course is full or gone. Preferences ranked
+// below the allocated one are not_reached.
+const (
+	PreferencePending     = "pending"
+	PreferenceIneligible  = "ineligible"
+	PreferenceAllocated   = "allocated"
+	PreferenceNoSeats     = "no_seats"
+	PreferenceUnavailable = "unavailable"
+	PreferenceNotReached  = "not_reached"
+)
+
+// Preference is one ranked course choice on a multi-course application.
+type Preference struct {
+	Rank        int                `bson:"rank" json:"rank"`
+	CourseID    primitive.ObjectID `bson:"courseId" json:"courseId"`
+	Status      string             `bson:"status" json:"status"`
+	Eligibility *EligibilityResult `bson:"eligibility,omitempty" json:"eligibility,omitempty"`
+}
+
+// Admission is an application to CourseID in the admission cycle with code
+// Cycle. Multi-course applications also carry their ranked Preferences;
+// CourseID is then the course the applicant is currently being considered
+// for or was allocated to. Admissions made before cycles existed have no
+// Cycle and use the course's own seats. SeatQuota is the quota of the seat
+// held in the cycle while the admission holds one. ApplicationFee and
+// LateFee are the fees charged to submit the application, fixed when it was
+// made. SubmittedAt is set once the student submits the application; its
+// contents can no longer be edited after that, even while it waits as a
+// draft for the application fee. OfferExpiresAt is the deadline to accept or
+// decline the current offer. WaitlistedAt orders the course's waitlist while
+// the admission is on it.
 type Admission struct {
 	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
 	StudentID       primitive.ObjectID `bson:"studentId" json:"studentId"`
@@ -47,6 +102,42 @@ type Admission struct {
 	Documents       Documents          `bson:"documents" json:"documents"`
 	Status          string             `bson:"status" json:"status"`
 	Comments        string             `bson:"comments,omitempty" json:"comments,omitempty"`
+	StatusHistory   []StatusChange     `bson:"statusHistory" json:"statusHistory"`
+	Eligibility     *EligibilityResult `bson:"eligibility,omitempty" json:"eligibility,omitempty"`
+	Preferences     []Preference       `bson:"preferences,omitempty" json:"preferences,omitempty"`
+	Category        string             `bson:"category,omitempty" json:"category,omitempty"`
+	Cycle           string             `bson:"cycle,omitempty" json:"cycle,omitempty"`
+	SeatQuota       string             `bson:"seatQuota,omitempty" json:"seatQuota,omitempty"`
+	ApplicationFee  float64            `bson:"applicationFee,omitempty" json:"applicationFee,omitempty"`
+	LateFee         float64            `bson:"lateFee,omitempty" json:"lateFee,omitempty"`
+	Late            bool               `bson:"late,omitempty" json:"late,omitempty"`
+	SubmittedAt     *time.Time         `bson:"submittedAt,omitempty" json:"submittedAt,omitempty"`
+	OfferExpiresAt  *time.Time         `bson:"offerExpiresAt,omitempty" json:"offerExpiresAt,omitempty"`
+	WaitlistedAt    *time.Time         `bson:"waitlistedAt,omitempty" json:"waitlistedAt,omitempty"`
 	CreatedAt       time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt       time.Time          `bson:"updatedAt" json:"updatedAt"`
 }
+
+// StudentSummary is the part of a student record shown next to their
+// applications.
+type StudentSummary struct {
+	ID    primitive.ObjectID `json:"id"`
+	Name  string             `json:"name"`
+	Email string             `json:"email"`
+	Phone string             `json:"phone"`
+}
+
+// CourseSummary is the part of a course shown next to applications for it.
+type CourseSummary struct {
+	ID       primitive.ObjectID `json:"id"`
+	Name     string             `json:"name"`
+	Duration string             `json:"duration"`
+}
+
+// AdmissionView is an admission joined with its student and course, as
+// returned to admins. Student or Course is nil if the record is gone.
+type AdmissionView struct {
+	Admission
+	Student *StudentSummary `json:"student"`
+	Course  *CourseSummary  `json:"course"`
+}
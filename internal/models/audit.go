@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditChange is one field that differs between the before and after state
+// of an audited record. Field is a dotted path; a nil Before or After means
+// the field was added or removed.
+type AuditChange struct {
+	Field  string `bson:"field" json:"field"`
+	Before any    `bson:"before" json:"before"`
+	After  any    `bson:"after" json:"after"`
+}
+
+// AuditEvent records one privileged write. Events form a hash chain: Hash
+// covers the event's contents and PrevHash, the Hash of the event before it,
+// so editing or deleting a stored event breaks every later link.
+type AuditEvent struct {
+	// Sequence numbers events from 1 without gaps. It is also the document
+	// ID, so two writers can never both claim the same place in the chain.
+	Sequence   int64              `bson:"_id" json:"sequence"`
+	ActorID    primitive.ObjectID `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	ActorRole  string             `bson:"actorRole" json:"actorRole"`
+	Action     string             `bson:"action" json:"action"`
+	TargetType string             `bson:"targetType" json:"targetType"`
+	TargetID   string             `bson:"targetId" json:"targetId"`
+	Changes    []AuditChange      `bson:"changes" json:"changes"`
+	IP         string             `bson:"ip" json:"ip"`
+	RequestID  string             `bson:"requestId" json:"requestId"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+	PrevHash   string             `bson:"prevHash" json:"prevHash"`
+	Hash       string             `bson:"hash" json:"hash"`
+}
+
+// PendingAuditEvent is an event that could not be appended to the chain
+// when it happened. It waits in a queue until it can be, keeping the time
+// of the change in Event.CreatedAt.
+type PendingAuditEvent struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Event     AuditEvent         `bson:"event" json:"event"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
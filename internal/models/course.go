@@ -10,6 +10,9 @@ type EligibilityCriteria struct {
 	MinimumPercentage float64  `bson:"minimumPercentage" json:"minimumPercentage"`
 	RequiredSubjects  []string `bson:"requiredSubjects" json:"requiredSubjects"`
 	EntranceExam      bool     `bson:"entranceExam" json:"entranceExam"`
+	// Enforcement is "reject" (the default) to refuse ineligible applications
+	// or "flag" to accept them and leave the decision to reviewers.
+	Enforcement string `bson:"enforcement,omitempty" json:"enforcement,omitempty" binding:"omitempty,oneof=reject flag"`
 }
 
 type Fees struct {
@@ -24,8 +27,20 @@ type Course struct {
 	Description         string              `bson:"description" json:"description"`
 	Duration            string              `bson:"duration" json:"duration"`
 	Seats               int                 `bson:"seats" json:"seats"`
+	SeatsFilled         int                 `bson:"seatsFilled" json:"seatsFilled"`
+	SeatsRemaining      int                 `bson:"-" json:"seatsRemaining"`
+	SeatMatrix          []SeatMatrixRow     `bson:"-" json:"seatMatrix,omitempty"`
 	EligibilityCriteria EligibilityCriteria `bson:"eligibilityCriteria" json:"eligibilityCriteria"`
 	Fees                Fees                `bson:"fees" json:"fees"`
 	CreatedAt           time.Time           `bson:"created_at" json:"created_at"`
 	UpdatedAt           time.Time           `bson:"updated_at" json:"updated_at"`
 }
+
+// RemainingSeats returns how many seats are still free. It never goes below
+// zero, even if Seats was lowered after offers were made.
+func (c *Course) RemainingSeats() int {
+	if c.SeatsFilled >= c.Seats {
+		return 0
+	}
+	return c.Seats - c.SeatsFilled
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Where an admission cycle stands at a given time.
+const (
+	CycleUpcoming = "upcoming"
+	CycleOpen     = "open"
+	CycleLate     = "late"
+	CycleClosed   = "closed"
+)
+
+// CycleCourse is a course offered in an admission cycle. Seats overrides the
+// course's own seat count for this cycle when set. Quotas set aside seats
+// for reservation categories; the seats left over form the general quota.
+type CycleCourse struct {
+	CourseID primitive.ObjectID `bson:"courseId" json:"courseId" binding:"required"`
+	Seats    *int               `bson:"seats,omitempty" json:"seats,omitempty" binding:"omitempty,min=0"`
+	Quotas   []Quota            `bson:"quotas,omitempty" json:"quotas,omitempty" binding:"dive"`
+}
+
+// Quota is the number of a course's seats reserved for one category.
+type Quota struct {
+	Category string `bson:"category" json:"category" binding:"required"`
+	Seats    int    `bson:"seats" json:"seats" binding:"min=0"`
+}
+
+// SeatConversion hands the unfilled seats of the From quota over to the To
+// quota once After has passed, or once the cycle stops taking applications
+// when After is not set.
+type SeatConversion struct {
+	From  string     `bson:"from" json:"from" binding:"required"`
+	To    string     `bson:"to" json:"to" binding:"required"`
+	After *time.Time `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// LateSubmission lets applications in after ClosesAt until Until. They are
+// marked late and charged Fee on top of the usual fees.
+type LateSubmission struct {
+	Until time.Time `bson:"until" json:"until" binding:"required"`
+	Fee   float64   `bson:"fee" json:"fee" binding:"min=0"`
+}
+
+// AdmissionCycle is one intake, such as "Fall 2027". Applications are only
+// accepted between OpensAt and ClosesAt, or later under LateSubmission, and
+// only for the courses the cycle offers. Admissions refer to their cycle by
+// Code. CurrentStatus is not stored; handlers fill it in from Status.
+type AdmissionCycle struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Code           string             `bson:"code" json:"code"`
+	Name           string             `bson:"name" json:"name"`
+	OpensAt        time.Time          `bson:"opensAt" json:"opensAt"`
+	ClosesAt       time.Time          `bson:"closesAt" json:"closesAt"`
+	LateSubmission *LateSubmission    `bson:"lateSubmission,omitempty" json:"lateSubmission,omitempty"`
+	Courses        []CycleCourse      `bson:"courses" json:"courses"`
+	Conversions    []SeatConversion   `bson:"conversions,omitempty" json:"conversions,omitempty"`
+	CurrentStatus  string             `bson:"-" json:"status"`
+	CreatedAt      time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Status reports whether the cycle accepts applications at now.
+func (c *AdmissionCycle) Status(now time.Time) string {
+	switch {
+	case now.Before(c.OpensAt):
+		return CycleUpcoming
+	case !now.After(c.ClosesAt):
+		return CycleOpen
+	case c.LateSubmission != nil && !now.After(c.LateSubmission.Until):
+		return CycleLate
+	}
+	return CycleClosed
+}
+
+// Deadline is when the cycle stops taking applications, including any late
+// submission period.
+func (c *AdmissionCycle) Deadline() time.Time {
+	if c.LateSubmission != nil {
+		return c.LateSubmission.Until
+	}
+	return c.ClosesAt
+}
+
+// ConversionActive reports whether conversion applies at now.
+func (c *AdmissionCycle) ConversionActive(conversion SeatConversion, now time.Time) bool {
+	after := c.Deadline()
+	if conversion.After != nil {
+		after = *conversion.After
+	}
+	return now.After(after)
+}
+
+// Course returns the cycle's entry for courseID, if the cycle offers it.
+func (c *AdmissionCycle) Course(courseID primitive.ObjectID) (*CycleCourse, bool) {
+	for i := range c.Courses {
+		if c.Courses[i].CourseID == courseID {
+			return &c.Courses[i], true
+		}
+	}
+	return nil, false
+}
+
+// SeatsFor returns how many seats course has in this cycle.
+func (c *AdmissionCycle) SeatsFor(course *Course) int {
+	if entry, ok := c.Course(course.ID); ok && entry.Seats != nil {
+		return *entry.Seats
+	}
+	return course.Seats
+}
+
+// SeatMatrixRow is one quota of a course's seats in a cycle. Converted is
+// how many seats conversions moved into the quota, or out of it when
+// negative; Limit is Seats plus Converted.
+type SeatMatrixRow struct {
+	Category  string `json:"category"`
+	Seats     int    `json:"seats"`
+	Converted int    `json:"converted"`
+	Limit     int    `json:"limit"`
+	Filled    int    `json:"filled"`
+	Remaining int    `json:"remaining"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCycleStatus(t *testing.T) {
+	opens := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	closes := opens.AddDate(0, 1, 0)
+	cycle := AdmissionCycle{OpensAt: opens, ClosesAt: closes}
+	late := cycle
+	late.LateSubmission = &LateSubmission{Until: closes.AddDate(0, 0, 7), Fee: 500}
+
+	tests := []struct {
+		name  string
+		cycle AdmissionCycle
+		at    time.Time
+		want  string
+	}{
+		{"before opening", cycle, opens.Add(-time.Second), CycleUpcoming},
+		{"at opening", cycle, opens, CycleOpen},
+		{"at closing", cycle, closes, CycleOpen},
+		{"after closing", cycle, closes.Add(time.Second), CycleClosed},
+		{"late period", late, closes.Add(time.Second), CycleLate},
+		{"end of late period", late, late.LateSubmission.Until, CycleLate},
+		{"after late period", late, late.LateSubmission.Until.Add(time.Second), CycleClosed},
+	}
+	for _, tt := range tests {
+		if got := tt.cycle.Status(tt.at); got != tt.want {
+			t.Errorf("%s: Status = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	if !cycle.Deadline().Equal(closes) || !late.Deadline().Equal(late.LateSubmission.Until) {
+		t.Errorf("deadlines %v and %v, want closing and the end of the late period", cycle.Deadline(), late.Deadline())
+	}
+}
+
+func TestCycleConversionActive(t *testing.T) {
+	closes := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	cycle := AdmissionCycle{OpensAt: closes.AddDate(0, -1, 0), ClosesAt: closes}
+	after := closes.AddDate(0, 0, 3)
+
+	atDeadline := SeatConversion{From: "sc", To: "general"}
+	scheduled := SeatConversion{From: "sc", To: "general", After: &after}
+	if cycle.ConversionActive(atDeadline, closes) || !cycle.ConversionActive(atDeadline, closes.Add(time.Second)) {
+		t.Error("an unscheduled conversion should apply once applications close")
+	}
+	if cycle.ConversionActive(scheduled, after) || !cycle.ConversionActive(scheduled, after.Add(time.Second)) {
+		t.Error("a scheduled conversion should apply once its time has passed")
+	}
+}
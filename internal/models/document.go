@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kinds of uploaded document.
+const (
+	DocumentKindPhoto                    = "photo"
+	DocumentKindIDProof                  = "id_proof"
+	DocumentKindAddressProof             = "address_proof"
+	DocumentKindQualificationCertificate = "qualification_certificate"
+	DocumentKindAcademicRecord           = "academic_record"
+	DocumentKindCategoryCertificate      = "category_certificate"
+)
+
+// Document is the metadata of an uploaded file. The file itself is kept in
+// blob storage under StorageKey.
+type Document struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	OwnerID     primitive.ObjectID  `bson:"ownerId" json:"ownerId"`
+	AdmissionID *primitive.ObjectID `bson:"admissionId,omitempty" json:"admissionId,omitempty"`
+	Kind        string              `bson:"kind" json:"kind"`
+	FileName    string              `bson:"fileName" json:"fileName"`
+	ContentType string              `bson:"contentType" json:"contentType"`
+	Size        int64               `bson:"size" json:"size"`
+	Checksum    string              `bson:"checksum" json:"checksum"`
+	StorageKey  string              `bson:"storageKey" json:"-"`
+	CreatedAt   time.Time           `bson:"createdAt" json:"createdAt"`
+}
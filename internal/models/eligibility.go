@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// EligibilityRuleResult is the outcome of checking one eligibility rule.
+type EligibilityRuleResult struct {
+	Rule     string `bson:"rule" json:"rule"`
+	Passed   bool   `bson:"passed" json:"passed"`
+	Expected string `bson:"expected" json:"expected"`
+	Actual   string `bson:"actual" json:"actual"`
+	Message  string `bson:"message,omitempty" json:"message,omitempty"`
+}
+
+// EligibilityResult summarises how an application measures up against a
+// course's EligibilityCriteria. Score is the percentage of rules passed.
+type EligibilityResult struct {
+	Eligible    bool                    `bson:"eligible" json:"eligible"`
+	Score       float64                 `bson:"score" json:"score"`
+	Rules       []EligibilityRuleResult `bson:"rules" json:"rules"`
+	EvaluatedAt time.Time               `bson:"evaluatedAt" json:"evaluatedAt"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IdempotencyRecord remembers the outcome of a request made with an
+// Idempotency-Key so a retry gets the same response instead of repeating the
+// write. Keys are scoped to the user who sent them. RequestHash fingerprints
+// the method, path and body the key was first used with; StatusCode is zero
+// while that request is still being handled.
+type IdempotencyRecord struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	UserID      string             `bson:"userId"`
+	Key         string             `bson:"key"`
+	RequestHash string             `bson:"requestHash"`
+	StatusCode  int                `bson:"statusCode,omitempty"`
+	ContentType string             `bson:"contentType,omitempty"`
+	Body        []byte             `bson:"body,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt"`
+	ExpiresAt   time.Time          `bson:"expiresAt"`
+}
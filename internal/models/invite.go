@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Invite statuses, derived from an invite's timestamps.
+const (
+	InvitePending  = "pending"
+	InviteAccepted = "accepted"
+	InviteRevoked  = "revoked"
+	InviteExpired  = "expired"
+)
+
+// Invite lets someone create a staff account with the role it names. It is
+// redeemed with a signed token that carries the invite's ID and expiry;
+// the invite itself records whether it has been used or revoked, which is
+// what makes the token single-use.
+type Invite struct {
+	ID    primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Email string             `bson:"email,omitempty" json:"email,omitempty"`
+	Role  string             `bson:"role" json:"role"`
+	// InvitedBy is zero for the bootstrap invite, which the server issues
+	// to itself on first run and which lets whoever holds it choose the
+	// email.
+	InvitedBy  primitive.ObjectID  `bson:"invitedBy,omitempty" json:"invitedBy,omitempty"`
+	Bootstrap  bool                `bson:"bootstrap,omitempty" json:"bootstrap,omitempty"`
+	ExpiresAt  time.Time           `bson:"expiresAt" json:"expiresAt"`
+	AcceptedAt *time.Time          `bson:"acceptedAt,omitempty" json:"acceptedAt,omitempty"`
+	UserID     *primitive.ObjectID `bson:"userId,omitempty" json:"userId,omitempty"`
+	RevokedAt  *time.Time          `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	Status     string              `bson:"-" json:"status"`
+	CreatedAt  time.Time           `bson:"createdAt" json:"createdAt"`
+}
+
+// CurrentStatus returns the invite's status at now.
+func (i *Invite) CurrentStatus(now time.Time) string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InviteAccepted
+	case i.RevokedAt != nil:
+		return InviteRevoked
+	case !now.Before(i.ExpiresAt):
+		return InviteExpired
+	default:
+		return InvitePending
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MeritWeights configures how applicants are scored for a merit list. The
+// score is Percentage × AcademicDetails.Percentage + EntranceExam × the
+// entrance exam score + Category × CategoryScores[category]. A missing exam
+// score or unlisted category counts as zero.
+type MeritWeights struct {
+	Percentage     float64            `bson:"percentage" json:"percentage"`
+	EntranceExam   float64            `bson:"entranceExam" json:"entranceExam"`
+	Category       float64            `bson:"category" json:"category"`
+	CategoryScores map[string]float64 `bson:"categoryScores,omitempty" json:"categoryScores,omitempty"`
+}
+
+// MeritEntry is one applicant's place on a merit list.
+type MeritEntry struct {
+	Rank              int                `bson:"rank" json:"rank"`
+	AdmissionID       primitive.ObjectID `bson:"admissionId" json:"admissionId"`
+	StudentID         primitive.ObjectID `bson:"studentId" json:"studentId"`
+	Score             float64            `bson:"score" json:"score"`
+	Percentage        float64            `bson:"percentage" json:"percentage"`
+	EntranceExamScore *float64           `bson:"entranceExamScore,omitempty" json:"entranceExamScore,omitempty"`
+	Category          string             `bson:"category,omitempty" json:"category,omitempty"`
+	// WithinCutoff is true for the applicants ranked inside the seat count.
+	WithinCutoff bool `bson:"withinCutoff" json:"withinCutoff"`
+}
+
+// MeritList is one published version of the ranking for a course and cycle.
+// Published lists are never changed; republishing creates a new Version.
+type MeritList struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CourseID    primitive.ObjectID `bson:"courseId" json:"courseId"`
+	Cycle       string             `bson:"cycle" json:"cycle"`
+	Version     int                `bson:"version" json:"version"`
+	Weights     MeritWeights       `bson:"weights" json:"weights"`
+	Seats       int                `bson:"seats" json:"seats"`
+	Entries     []MeritEntry       `bson:"entries" json:"entries"`
+	PublishedBy primitive.ObjectID `bson:"publishedBy,omitempty" json:"publishedBy,omitempty"`
+	PublishedAt time.Time          `bson:"publishedAt" json:"publishedAt"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// What a payment is for.
+const (
+	PaymentPurposeApplication = "application_fee"
+	PaymentPurposeTuition     = "tuition"
+)
+
+// Payment statuses. A payment is pending until the gateway reports the
+// payer's checkout, then captured or failed. A captured payment becomes
+// refunded once all of it has been paid back.
+const (
+	PaymentPending  = "pending"
+	PaymentCaptured = "captured"
+	PaymentFailed   = "failed"
+	PaymentRefunded = "refunded"
+)
+
+// PaymentLine is one fee making up a payment. Amounts on payments are in
+// the currency's minor unit (paise, cents).
+type PaymentLine struct {
+	Description string `bson:"description" json:"description"`
+	Amount      int64  `bson:"amount" json:"amount"`
+}
+
+// Refund statuses. A refund is recorded as pending before the gateway is
+// asked to pay it, so concurrent requests cannot pay back more than was
+// captured, and succeeded once the gateway confirms it. Refunds stored
+// before statuses were tracked have none and count as succeeded.
+const (
+	RefundPending   = "pending"
+	RefundSucceeded = "succeeded"
+)
+
+// Refund is money paid back on a captured payment. Key is the idempotency
+// key it was requested with.
+type Refund struct {
+	ID         primitive.ObjectID `bson:"id" json:"id"`
+	Key        string             `bson:"key" json:"-"`
+	Amount     int64              `bson:"amount" json:"amount"`
+	Status     string             `bson:"status,omitempty" json:"status,omitempty"`
+	Reason     string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	GatewayRef string             `bson:"gatewayRef" json:"gatewayRef"`
+	RefundedBy primitive.ObjectID `bson:"refundedBy" json:"refundedBy"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// Receipt is issued when a payment is captured.
+type Receipt struct {
+	Number       string        `bson:"number" json:"number"`
+	IssuedAt     time.Time     `bson:"issuedAt" json:"issuedAt"`
+	StudentName  string        `bson:"studentName" json:"studentName"`
+	StudentEmail string        `bson:"studentEmail" json:"studentEmail"`
+	CourseName   string        `bson:"courseName" json:"courseName"`
+	Lines        []PaymentLine `bson:"lines" json:"lines"`
+	Total        int64         `bson:"total" json:"total"`
+	Currency     string        `bson:"currency" json:"currency"`
+}
+
+// Payment collects one fee for an admission through the payment gateway.
+// IntentID is the gateway's reference for it.
+type Payment struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	AdmissionID   primitive.ObjectID `bson:"admissionId" json:"admissionId"`
+	StudentID     primitive.ObjectID `bson:"studentId" json:"studentId"`
+	Purpose       string             `bson:"purpose" json:"purpose"`
+	Amount        int64              `bson:"amount" json:"amount"`
+	Currency      string             `bson:"currency" json:"currency"`
+	Lines         []PaymentLine      `bson:"lines" json:"lines"`
+	Status        string             `bson:"status" json:"status"`
+	Gateway       string             `bson:"gateway" json:"gateway"`
+	IntentID      string             `bson:"intentId" json:"intentId"`
+	ClientSecret  string             `bson:"clientSecret" json:"clientSecret,omitempty"`
+	FailureReason string             `bson:"failureReason,omitempty" json:"failureReason,omitempty"`
+	Refunded      int64              `bson:"refunded" json:"refunded"`
+	Refunds       []Refund           `bson:"refunds" json:"refunds"`
+	Receipt       *Receipt           `bson:"receipt,omitempty" json:"receipt,omitempty"`
+	CapturedAt    *time.Time         `bson:"capturedAt,omitempty" json:"capturedAt,omitempty"`
+	CreatedAt     time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session is a logged-in device. It owns the current refresh token and
+// remembers the hashes of the last tokens it rotated away from, so a
+// replayed token can be recognised.
+type Session struct {
+	ID                  primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID              primitive.ObjectID `bson:"userId" json:"userId"`
+	RefreshTokenHash    string             `bson:"refreshTokenHash" json:"-"`
+	PreviousTokenHashes []string           `bson:"previousTokenHashes" json:"-"`
+	UserAgent           string             `bson:"userAgent,omitempty" json:"userAgent,omitempty"`
+	IP                  string             `bson:"ip,omitempty" json:"ip,omitempty"`
+	CreatedAt           time.Time          `bson:"createdAt" json:"createdAt"`
+	LastUsedAt          time.Time          `bson:"lastUsedAt" json:"lastUsedAt"`
+	ExpiresAt           time.Time          `bson:"expiresAt" json:"expiresAt"`
+	RevokedAt           *time.Time         `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	RevokedReason       string             `bson:"revokedReason,omitempty" json:"revokedReason,omitempty"`
+}
+
+// Active reports whether the session can still be used at the given time.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -15,15 +15,16 @@ type Address struct {
 }
 
 type Student struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Email       string             `bson:"email" json:"email" binding:"required,email"`
-	Password    string             `bson:"password" json:"password" binding:"required,min=6"`
-	Name        string             `bson:"name" json:"name" binding:"required"`
-	Phone       string             `bson:"phone" json:"phone"`
-	DateOfBirth string             `bson:"dateOfBirth" json:"dateOfBirth"`
-	Gender      string             `bson:"gender" json:"gender"`
-	Address     Address            `bson:"address" json:"address"`
-	Role        string             `bson:"role" json:"role"`
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Email         string             `bson:"email" json:"email" binding:"required,email"`
+	Password      string             `bson:"password" json:"password" binding:"required,min=6"`
+	Name          string             `bson:"name" json:"name" binding:"required"`
+	Phone         string             `bson:"phone" json:"phone"`
+	DateOfBirth   string             `bson:"dateOfBirth" json:"dateOfBirth"`
+	Gender        string             `bson:"gender" json:"gender"`
+	Address       Address            `bson:"address" json:"address"`
+	Role          string             `bson:"role" json:"role"`
+	EmailVerified bool               `bson:"emailVerified" json:"emailVerified"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
 }
@@ -0,0 +1,149 @@
+package payments
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Fake intent states.
+const (
+	fakeCreated    = "created"
+	fakeAuthorized = "authorized"
+	fakeCaptured   = "captured"
+	fakeFailed     = "failed"
+)
+
+type fakeIntent struct {
+	amount   int64
+	state    string
+	refunded int64
+}
+
+// FakeGateway settles payments in memory. Checkout is completed with
+// Complete instead of by a payer, and its callbacks are signed like a real
+// gateway's so the webhook path is exercised end to end.
+type FakeGateway struct {
+	secret []byte
+
+	mu       sync.Mutex
+	intents  map[string]*fakeIntent
+	captures map[string]bool
+	refunds  map[string]string
+}
+
+func NewFakeGateway(secret []byte) *FakeGateway {
+	return &FakeGateway{
+		secret:   secret,
+		intents:  map[string]*fakeIntent{},
+		captures: map[string]bool{},
+		refunds:  map[string]string{},
+	}
+}
+
+func (g *FakeGateway) Name() string { return "fake" }
+
+func (g *FakeGateway) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id := "pi_fake_" + randomID()
+	g.intents[id] = &fakeIntent{amount: req.Amount, state: fakeCreated}
+	return &Intent{ID: id, ClientSecret: id + "_secret_" + randomID()}, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, intentID, idempotencyKey string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	intent, ok := g.intents[intentID]
+	if !ok {
+		return ErrIntentNotFound
+	}
+	if g.captures[idempotencyKey] || intent.state == fakeCaptured {
+		return nil
+	}
+	if intent.state != fakeAuthorized {
+		return ErrNotAuthorized
+	}
+	intent.state = fakeCaptured
+	g.captures[idempotencyKey] = true
+	return nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, intentID string, amount int64, idempotencyKey string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if id, ok := g.refunds[idempotencyKey]; ok {
+		return id, nil
+	}
+	intent, ok := g.intents[intentID]
+	if !ok {
+		return "", ErrIntentNotFound
+	}
+	if intent.state != fakeCaptured {
+		return "", ErrNotAuthorized
+	}
+	if amount <= 0 || intent.refunded+amount > intent.amount {
+		return "", ErrRefundTooLarge
+	}
+	intent.refunded += amount
+	id := "re_fake_" + randomID()
+	g.refunds[idempotencyKey] = id
+	return id, nil
+}
+
+func (g *FakeGateway) ParseWebhook(payload []byte, header http.Header) (*Event, error) {
+	if err := VerifySignature(g.secret, payload, header.Get(SignatureHeader), time.Now()); err != nil {
+		return nil, err
+	}
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return &event, nil
+}
+
+// Complete authorizes the intent, or fails it, and returns the signed
+// callback announcing the outcome. An intent that was already settled keeps
+// its outcome.
+func (g *FakeGateway) Complete(intentID string, succeed bool) ([]byte, http.Header, error) {
+	g.mu.Lock()
+	intent, ok := g.intents[intentID]
+	if !ok {
+		g.mu.Unlock()
+		return nil, nil, ErrIntentNotFound
+	}
+	event := Event{ID: "evt_fake_" + randomID(), IntentID: intentID, Created: time.Now().UTC()}
+	// Only a new intent can go either way; later calls repeat the outcome
+	if intent.state == fakeCreated {
+		intent.state = fakeFailed
+		if succeed {
+			intent.state = fakeAuthorized
+		}
+	}
+	event.Type = EventAuthorized
+	if intent.state == fakeFailed {
+		event.Type, event.Reason = EventFailed, "declined by the fake gateway"
+	}
+	g.mu.Unlock()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := http.Header{}
+	header.Set(SignatureHeader, Sign(g.secret, payload, time.Now()))
+	return payload, header, nil
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
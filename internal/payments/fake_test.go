@@ -0,0 +1,88 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFakeGateway(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGateway([]byte("secret"))
+	intent, err := g.CreateIntent(ctx, IntentRequest{Amount: 1000, Currency: "INR"})
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+
+	if err := g.Capture(ctx, intent.ID, "c1"); !errors.Is(err, ErrNotAuthorized) {
+		t.Errorf("Capture before checkout = %v, want ErrNotAuthorized", err)
+	}
+	if _, err := g.Refund(ctx, intent.ID, 100, "early"); !errors.Is(err, ErrNotAuthorized) {
+		t.Errorf("Refund before capture = %v, want ErrNotAuthorized", err)
+	}
+
+	payload, header, err := g.Complete(intent.ID, true)
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	event, err := g.ParseWebhook(payload, header)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.Type != EventAuthorized || event.IntentID != intent.ID {
+		t.Errorf("event = %+v, want %s for %s", event, EventAuthorized, intent.ID)
+	}
+	if _, err := g.ParseWebhook(append(payload, ' '), header); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("ParseWebhook of a changed body = %v, want ErrInvalidSignature", err)
+	}
+	// A settled intent keeps its outcome
+	payload, header, _ = g.Complete(intent.ID, false)
+	if event, _ := g.ParseWebhook(payload, header); event == nil || event.Type != EventAuthorized {
+		t.Errorf("second Complete = %+v, want %s", event, EventAuthorized)
+	}
+
+	if err := g.Capture(ctx, intent.ID, "c1"); err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if err := g.Capture(ctx, intent.ID, "c1"); err != nil {
+		t.Errorf("repeated Capture = %v", err)
+	}
+
+	first, err := g.Refund(ctx, intent.ID, 600, "r1")
+	if err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if again, err := g.Refund(ctx, intent.ID, 600, "r1"); err != nil || again != first {
+		t.Errorf("repeated Refund = %q, %v, want %q", again, err, first)
+	}
+	if _, err := g.Refund(ctx, intent.ID, 500, "r2"); !errors.Is(err, ErrRefundTooLarge) {
+		t.Errorf("Refund past the amount = %v, want ErrRefundTooLarge", err)
+	}
+	if _, err := g.Refund(ctx, intent.ID, 400, "r2"); err != nil {
+		t.Errorf("Refund of the rest = %v", err)
+	}
+	if _, err := g.Refund(ctx, "pi_missing", 1, "r3"); !errors.Is(err, ErrIntentNotFound) {
+		t.Errorf("Refund of an unknown intent = %v, want ErrIntentNotFound", err)
+	}
+}
+
+func TestFakeGatewayDeclined(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGateway([]byte("secret"))
+	intent, _ := g.CreateIntent(ctx, IntentRequest{Amount: 1000, Currency: "INR"})
+
+	payload, header, err := g.Complete(intent.ID, false)
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	event, err := g.ParseWebhook(payload, header)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.Type != EventFailed || event.Reason == "" {
+		t.Errorf("event = %+v, want %s with a reason", event, EventFailed)
+	}
+	if err := g.Capture(ctx, intent.ID, "c1"); !errors.Is(err, ErrNotAuthorized) {
+		t.Errorf("Capture of a declined intent = %v, want ErrNotAuthorized", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Package payments talks to the payment gateway that collects application
+// fees and tuition. The gateway is chosen with the PAYMENT_GATEWAY
+// environment variable so local development does not need a real provider.
+package payments
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Webhook event types.
+const (
+	// EventAuthorized means the payer completed checkout and the amount can
+	// be captured.
+	EventAuthorized = "payment.authorized"
+	// EventFailed means checkout failed or was abandoned.
+	EventFailed = "payment.failed"
+)
+
+var (
+	ErrIntentNotFound   = errors.New("payment intent not found")
+	ErrNotAuthorized    = errors.New("payment intent has not been authorized")
+	ErrRefundTooLarge   = errors.New("refund exceeds the captured amount")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// IntentRequest asks the gateway to prepare a payment. Amount is in the
+// currency's minor unit (paise, cents).
+type IntentRequest struct {
+	Amount      int64
+	Currency    string
+	Reference   string
+	Description string
+}
+
+// Intent is a payment prepared at the gateway. The payer completes it with
+// ClientSecret, or at CheckoutURL when the gateway hosts the checkout page.
+type Intent struct {
+	ID           string
+	ClientSecret string
+	CheckoutURL  string
+}
+
+// Event is a verified webhook callback about an intent.
+type Event struct {
+	ID       string    `json:"id"`
+	Type     string    `json:"type"`
+	IntentID string    `json:"intentId"`
+	Reason   string    `json:"reason,omitempty"`
+	Created  time.Time `json:"created"`
+}
+
+type PaymentGateway interface {
+	Name() string
+	CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error)
+	// Capture collects an authorized intent. Repeating a capture with the
+	// same idempotency key has no further effect.
+	Capture(ctx context.Context, intentID, idempotencyKey string) error
+	// Refund returns amount of a captured intent and returns the gateway's
+	// refund ID. Repeating a refund with the same idempotency key returns
+	// the original refund instead of paying out again.
+	Refund(ctx context.Context, intentID string, amount int64, idempotencyKey string) (string, error)
+	// ParseWebhook verifies a callback's signature and decodes it.
+	ParseWebhook(payload []byte, header http.Header) (*Event, error)
+}
+
+// Simulator is implemented by gateways that can stand in for a payer
+// during development. Complete settles the intent and returns the signed
+// webhook callback the gateway would send for it.
+type Simulator interface {
+	Complete(intentID string, succeed bool) (payload []byte, header http.Header, err error)
+}
+
+// FromEnv builds the gateway selected by PAYMENT_GATEWAY:
+//
+//	fake (default) settles payments in memory; webhooks are signed with
+//	     PAYMENT_WEBHOOK_SECRET, or a random secret when it is not set
+func FromEnv() (PaymentGateway, error) {
+	switch os.Getenv("PAYMENT_GATEWAY") {
+	case "", "fake":
+		secret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+		if secret == "" {
+			random := make([]byte, 32)
+			if _, err := rand.Read(random); err != nil {
+				return nil, err
+			}
+			secret = hex.EncodeToString(random)
+		}
+		return NewFakeGateway([]byte(secret)), nil
+	default:
+		return nil, fmt.Errorf("unknown PAYMENT_GATEWAY %q", os.Getenv("PAYMENT_GATEWAY"))
+	}
+}
+
+// Currency returns the currency fees are charged in, set with
+// PAYMENT_CURRENCY (default "INR").
+func Currency() string {
+	if currency := os.Getenv("PAYMENT_CURRENCY"); currency != "" {
+		return currency
+	}
+	return "INR"
+}
+
+// MinorUnits converts an amount in major units, as course fees are stored,
+// to minor units.
+func MinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
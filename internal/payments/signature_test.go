@@ -0,0 +1,43 @@
+package payments
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("secret")
+	payload := []byte(`{"id":"evt_1"}`)
+	now := time.Unix(1_700_000_000, 0)
+	signed := Sign(secret, payload, now)
+
+	tests := []struct {
+		name    string
+		secret  []byte
+		payload []byte
+		header  string
+		now     time.Time
+		ok      bool
+	}{
+		{"valid", secret, payload, signed, now, true},
+		{"within tolerance", secret, payload, signed, now.Add(signatureTolerance), true},
+		{"clock behind", secret, payload, signed, now.Add(-signatureTolerance), true},
+		{"too old", secret, payload, signed, now.Add(signatureTolerance + time.Second), false},
+		{"from the future", secret, payload, signed, now.Add(-signatureTolerance - time.Second), false},
+		{"other secret", []byte("other"), payload, signed, now, false},
+		{"tampered body", secret, []byte(`{"id":"evt_2"}`), signed, now, false},
+		{"missing signature", secret, payload, "t=1700000000", now, false},
+		{"bad timestamp", secret, payload, "t=soon,v1=00", now, false},
+		{"empty", secret, payload, "", now, false},
+	}
+	for _, tt := range tests {
+		err := VerifySignature(tt.secret, tt.payload, tt.header, tt.now)
+		if tt.ok && err != nil {
+			t.Errorf("%s: VerifySignature = %v", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: VerifySignature = %v, want ErrInvalidSignature", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Package query turns list request parameters (pagination, filters and
+// sorting) into storage-neutral Options, and defines the Page returned by
+// list endpoints. Field names are BSON paths so every store can apply them.
+package query
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+type Op string
+
+const (
+	OpEq  Op = "eq"
+	OpNe  Op = "ne"
+	OpIn  Op = "in"
+	OpGte Op = "gte"
+	OpLte Op = "lte"
+	// OpSearch matches documents where any of Fields contains Value, a
+	// string, ignoring case.
+	OpSearch Op = "search"
+)
+
+// Type is the type a filter parameter is parsed into.
+type Type int
+
+const (
+	String Type = iota
+	ObjectID
+	Time
+	Number
+	Bool
+)
+
+// Condition restricts results to documents whose Field satisfies Op against
+// Value. For OpIn, Value is a []any. OpSearch uses Fields instead of Field.
+type Condition struct {
+	Field  string
+	Fields []string
+	Op     Op
+	Value  any
+}
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Options describe one page of a list query. A zero Limit means no limit.
+type Options struct {
+	Conditions []Condition
+	Sort       []Sort
+	Limit      int
+	Offset     int
+}
+
+// Where returns a copy of o with an extra condition.
+func (o Options) Where(field string, op Op, value any) Options {
+	o.Conditions = append(append([]Condition{}, o.Conditions...), Condition{Field: field, Op: op, Value: value})
+	return o
+}
+
+// Page is one page of results plus the metadata clients need to fetch the
+// next one.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Page       int    `json:"page"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// NewPage wraps items fetched with opts.
+func NewPage[T any](items []T, total int64, opts Options) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	page := &Page[T]{Items: items, Total: total, Limit: opts.Limit, Page: 1}
+	if opts.Limit > 0 {
+		page.Page = opts.Offset/opts.Limit + 1
+		if next := opts.Offset + len(items); int64(next) < total {
+			page.NextCursor = encodeCursor(next)
+		}
+	}
+	return page
+}
+
+// Filter whitelists a query parameter that may be used to filter a list.
+type Filter struct {
+	Param string
+	Field string
+	Type  Type
+	Op    Op
+}
+
+// Spec declares what a list endpoint accepts. Sorts maps a sort key to the
+// field it orders by; DefaultSort is a key, prefixed with "-" for descending.
+// Search lists the fields the free-text "q" parameter looks in.
+type Spec struct {
+	Filters     []Filter
+	Sorts       map[string]string
+	DefaultSort string
+	Search      []string
+}
+
+var ErrInvalidQuery = errors.New("invalid query")
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidQuery, fmt.Sprintf(format, args...))
+}
+
+// Parse reads limit, page or cursor, sort, the whitelisted filters and the
+// search text from values. Unknown parameters are ignored; malformed ones
+// are reported as ErrInvalidQuery.
+func Parse(values url.Values, spec Spec) (Options, error) {
+	opts := Options{Limit: DefaultLimit}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return opts, invalid("limit must be a positive integer")
+		}
+		if limit > MaxLimit {
+			limit = MaxLimit
+		}
+		opts.Limit = limit
+	}
+
+	switch {
+	case values.Get("cursor") != "":
+		offset, err := decodeCursor(values.Get("cursor"))
+		if err != nil {
+			return opts, invalid("cursor is not valid")
+		}
+		opts.Offset = offset
+	case values.Get("page") != "":
+		page, err := strconv.Atoi(values.Get("page"))
+		if err != nil || page < 1 {
+			return opts, invalid("page must be a positive integer")
+		}
+		opts.Offset = (page - 1) * opts.Limit
+	}
+
+	sortKey := values.Get("sort")
+	if sortKey == "" {
+		sortKey = spec.DefaultSort
+	}
+	if sortKey != "" {
+		desc := strings.HasPrefix(sortKey, "-")
+		field, ok := spec.Sorts[strings.TrimPrefix(sortKey, "-")]
+		if !ok {
+			return opts, invalid("cannot sort by %q", strings.TrimPrefix(sortKey, "-"))
+		}
+		opts.Sort = append(opts.Sort, Sort{Field: field, Desc: desc})
+	}
+	// Break ties on _id so pages are stable
+	opts.Sort = append(opts.Sort, Sort{Field: "_id"})
+
+	for _, filter := range spec.Filters {
+		raw := values.Get(filter.Param)
+		if raw == "" {
+			continue
+		}
+		if filter.Op == OpIn {
+			list := []any{}
+			for _, part := range strings.Split(raw, ",") {
+				value, err := parseValue(strings.TrimSpace(part), filter.Type)
+				if err != nil {
+					return opts, invalid("%s: %v", filter.Param, err)
+				}
+				list = append(list, value)
+			}
+			opts.Conditions = append(opts.Conditions, Condition{Field: filter.Field, Op: OpIn, Value: list})
+			continue
+		}
+		value, err := parseValue(raw, filter.Type)
+		if err != nil {
+			return opts, invalid("%s: %v", filter.Param, err)
+		}
+		// An upper bound given as a plain date includes that whole day
+		if ts, ok := value.(time.Time); ok && filter.Op == OpLte && len(raw) == len("2006-01-02") {
+			value = ts.Add(24*time.Hour - time.Millisecond)
+		}
+		opts.Conditions = append(opts.Conditions, Condition{Field: filter.Field, Op: filter.Op, Value: value})
+	}
+
+	// Every word of q has to appear in at least one of the search fields
+	if len(spec.Search) > 0 {
+		for _, term := range strings.Fields(values.Get("q")) {
+			opts.Conditions = append(opts.Conditions, Condition{Fields: spec.Search, Op: OpSearch, Value: term})
+		}
+	}
+
+	return opts, nil
+}
+
+func parseValue(raw string, t Type) (any, error) {
+	switch t {
+	case ObjectID:
+		return primitive.ObjectIDFromHex(raw)
+	case Time:
+		if ts, err := time.Parse(time.RFC3339, raw); err == nil {
+			return ts, nil
+		}
+		ts, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return nil, errors.New("expected an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+		return ts, nil
+	case Number:
+		return strconv.ParseFloat(raw, 64)
+	case Bool:
+		return strconv.ParseBool(raw)
+	default:
+		return raw, nil
+	}
+}
+
+// Cursors are opaque to clients; they currently carry the offset of the
+// next page.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), "o:"))
+	if err != nil || offset < 0 || !strings.HasPrefix(string(raw), "o:") {
+		return 0, errors.New("malformed cursor")
+	}
+	return offset, nil
+}
@@ -0,0 +1,145 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var testSpec = Spec{
+	Filters: []Filter{
+		{Param: "status", Field: "status", Type: String, Op: OpIn},
+		{Param: "courseId", Field: "courseId", Type: ObjectID, Op: OpEq},
+		{Param: "from", Field: "createdAt", Type: Time, Op: OpGte},
+		{Param: "to", Field: "createdAt", Type: Time, Op: OpLte},
+		{Param: "minSeats", Field: "seats", Type: Number, Op: OpGte},
+	},
+	Sorts:       map[string]string{"createdAt": "createdAt", "name": "name"},
+	DefaultSort: "-createdAt",
+}
+
+func TestParseDefaults(t *testing.T) {
+	opts, err := Parse(url.Values{}, testSpec)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := Options{Limit: DefaultLimit, Sort: []Sort{{Field: "createdAt", Desc: true}, {Field: "_id"}}}
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("Parse() = %+v, want %+v", opts, want)
+	}
+}
+
+func TestParsePaging(t *testing.T) {
+	tests := []struct {
+		name   string
+		values url.Values
+		limit  int
+		offset int
+	}{
+		{"page", url.Values{"limit": {"10"}, "page": {"3"}}, 10, 20},
+		{"limit is capped", url.Values{"limit": {"1000"}}, MaxLimit, 0},
+		{"cursor wins over page", url.Values{"cursor": {encodeCursor(7)}, "page": {"5"}}, DefaultLimit, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := Parse(tt.values, testSpec)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if opts.Limit != tt.limit || opts.Offset != tt.offset {
+				t.Errorf("limit %d offset %d, want %d and %d", opts.Limit, opts.Offset, tt.limit, tt.offset)
+			}
+		})
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	courseID := primitive.NewObjectID()
+	opts, err := Parse(url.Values{
+		"status":   {"submitted, offered"},
+		"courseId": {courseID.Hex()},
+		"from":     {"2024-01-01T09:00:00Z"},
+		"to":       {"2024-01-31"},
+		"minSeats": {"30"},
+		"ignored":  {"x"},
+		"sort":     {"name"},
+	}, testSpec)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []Condition{
+		{Field: "status", Op: OpIn, Value: []any{"submitted", "offered"}},
+		{Field: "courseId", Op: OpEq, Value: courseID},
+		{Field: "createdAt", Op: OpGte, Value: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)},
+		// A plain date as an upper bound covers the whole day
+		{Field: "createdAt", Op: OpLte, Value: time.Date(2024, 1, 31, 23, 59, 59, int(999*time.Millisecond), time.UTC)},
+		{Field: "seats", Op: OpGte, Value: 30.0},
+	}
+	if !reflect.DeepEqual(opts.Conditions, want) {
+		t.Errorf("Conditions = %+v, want %+v", opts.Conditions, want)
+	}
+	if wantSort := []Sort{{Field: "name"}, {Field: "_id"}}; !reflect.DeepEqual(opts.Sort, wantSort) {
+		t.Errorf("Sort = %+v, want %+v", opts.Sort, wantSort)
+	}
+}
+
+func TestParseSearch(t *testing.T) {
+	spec := testSpec
+	spec.Search = []string{"personalDetails.firstName", "personalDetails.email"}
+	opts, err := Parse(url.Values{"q": {"  asha  Example.com "}}, spec)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []Condition{
+		{Fields: spec.Search, Op: OpSearch, Value: "asha"},
+		{Fields: spec.Search, Op: OpSearch, Value: "Example.com"},
+	}
+	if !reflect.DeepEqual(opts.Conditions, want) {
+		t.Errorf("Conditions = %+v, want %+v", opts.Conditions, want)
+	}
+
+	// Endpoints without search fields ignore q
+	if opts, _ := Parse(url.Values{"q": {"asha"}}, testSpec); len(opts.Conditions) != 0 {
+		t.Errorf("q on an endpoint without search added %+v", opts.Conditions)
+	}
+}
+
+func TestParseRejectsMalformedParameters(t *testing.T) {
+	for _, values := range []url.Values{
+		{"limit": {"0"}},
+		{"limit": {"ten"}},
+		{"page": {"-1"}},
+		{"cursor": {"not-a-cursor"}},
+		{"cursor": {encodeCursor(0)[:2]}},
+		{"sort": {"password"}},
+		{"courseId": {"123"}},
+		{"from": {"yesterday"}},
+		{"minSeats": {"many"}},
+	} {
+		if _, err := Parse(values, testSpec); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("Parse(%v) = %v, want ErrInvalidQuery", values, err)
+		}
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	page := NewPage([]int{1, 2}, 5, Options{Limit: 2, Offset: 2})
+	if page.Page != 2 || page.Total != 5 || page.NextCursor == "" {
+		t.Fatalf("NewPage = %+v, want page 2 of 5 with a cursor", page)
+	}
+	if offset, err := decodeCursor(page.NextCursor); err != nil || offset != 4 {
+		t.Errorf("next cursor offset = %d, %v, want 4", offset, err)
+	}
+
+	last := NewPage([]int{5}, 5, Options{Limit: 2, Offset: 4})
+	if last.NextCursor != "" {
+		t.Errorf("last page has cursor %q", last.NextCursor)
+	}
+	if empty := NewPage[int](nil, 0, Options{}); empty.Items == nil || empty.Page != 1 {
+		t.Errorf("empty page = %+v, want page 1 with no items", empty)
+	}
+}
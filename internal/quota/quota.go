@@ -0,0 +1,173 @@
+// Package quota splits a course's seats in an admission cycle between
+// reservation categories and decides which quotas an applicant may take a
+// seat from.
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"admission-portal-backend/internal/models"
+)
+
+// General is the open quota. It holds the seats no other quota reserves and
+// is available to every applicant.
+const General = "general"
+
+// Categories are the reservation categories an applicant may declare, in
+// the order seat matrices list them.
+var Categories = []string{General, "sc", "st", "obc", "ews", "international", "sports", "management"}
+
+var (
+	ErrUnknownCategory = errors.New("unknown reservation category")
+	ErrGeneralQuota    = errors.New("the general quota is the seats left over and cannot be set")
+	ErrDuplicateQuota  = errors.New("category has more than one quota")
+	ErrOverbooked      = errors.New("quotas reserve more seats than the course has")
+	ErrSelfConversion  = errors.New("a seat conversion must move seats to a different category")
+)
+
+// Normalize returns the canonical form of a declared category. No category
+// means general.
+func Normalize(category string) string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category == "" {
+		return General
+	}
+	return category
+}
+
+// IsValid reports whether category is a known category.
+func IsValid(category string) bool {
+	for _, known := range Categories {
+		if known == Normalize(category) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateQuotas checks the quotas of a course with the given seats.
+func ValidateQuotas(seats int, quotas []models.Quota) error {
+	seen := map[string]bool{}
+	reserved := 0
+	for _, q := range quotas {
+		category := Normalize(q.Category)
+		switch {
+		case !IsValid(category):
+			return fmt.Errorf("%w: %s", ErrUnknownCategory, q.Category)
+		case category == General:
+			return ErrGeneralQuota
+		case seen[category]:
+			return fmt.Errorf("%w: %s", ErrDuplicateQuota, category)
+		}
+		seen[category] = true
+		reserved += q.Seats
+	}
+	if reserved > seats {
+		return ErrOverbooked
+	}
+	return nil
+}
+
+// ValidateConversions checks a cycle's seat conversion rules.
+func ValidateConversions(conversions []models.SeatConversion) error {
+	for _, conversion := range conversions {
+		from, to := Normalize(conversion.From), Normalize(conversion.To)
+		if !IsValid(from) {
+			return fmt.Errorf("%w: %s", ErrUnknownCategory, conversion.From)
+		}
+		if !IsValid(to) {
+			return fmt.Errorf("%w: %s", ErrUnknownCategory, conversion.To)
+		}
+		if from == to {
+			return ErrSelfConversion
+		}
+	}
+	return nil
+}
+
+// Buckets returns the quotas an applicant of category may take a seat
+// from, in the order they are tried: their own quota first, then general.
+func Buckets(category string) []string {
+	category = Normalize(category)
+	if category == General {
+		return []string{General}
+	}
+	return []string{category, General}
+}
+
+// Matrix works out the seat matrix of a course in a cycle at now from its
+// seats and the number of seats filled in each quota. Active conversions
+// are applied in the order the cycle lists them, each moving the seats
+// still unfilled in its From quota to its To quota, so a quota converted
+// away takes no more applicants.
+func Matrix(cycle *models.AdmissionCycle, course *models.Course, filled map[string]int, now time.Time) []models.SeatMatrixRow {
+	seats := cycle.SeatsFor(course)
+	var quotas []models.Quota
+	if entry, ok := cycle.Course(course.ID); ok {
+		quotas = entry.Quotas
+	}
+
+	rows := map[string]*models.SeatMatrixRow{}
+	row := func(category string) *models.SeatMatrixRow {
+		if rows[category] == nil {
+			rows[category] = &models.SeatMatrixRow{Category: category}
+		}
+		return rows[category]
+	}
+
+	general := seats
+	for _, q := range quotas {
+		row(Normalize(q.Category)).Seats = q.Seats
+		general -= q.Seats
+	}
+	if general < 0 {
+		// Seats were lowered below the quotas after they were set
+		general = 0
+	}
+	row(General).Seats = general
+	for _, r := range rows {
+		r.Limit = r.Seats
+	}
+
+	for _, conversion := range cycle.Conversions {
+		if !cycle.ConversionActive(conversion, now) {
+			continue
+		}
+		from, to := row(Normalize(conversion.From)), row(Normalize(conversion.To))
+		unfilled := from.Limit - filled[from.Category]
+		if unfilled <= 0 {
+			continue
+		}
+		from.Limit -= unfilled
+		from.Converted -= unfilled
+		to.Limit += unfilled
+		to.Converted += unfilled
+	}
+
+	matrix := []models.SeatMatrixRow{}
+	for _, category := range Categories {
+		r, ok := rows[category]
+		if !ok {
+			continue
+		}
+		r.Filled = filled[category]
+		if r.Remaining = r.Limit - r.Filled; r.Remaining < 0 {
+			r.Remaining = 0
+		}
+		matrix = append(matrix, *r)
+	}
+	return matrix
+}
+
+// Limit returns the number of seats category may fill according to matrix.
+func Limit(matrix []models.SeatMatrixRow, category string) int {
+	for _, r := range matrix {
+		if r.Category == category {
+			return r.Limit
+		}
+	}
+	return 0
+}
@@ -0,0 +1,194 @@
+package quota
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"":        General,
+		"  ":      General,
+		"OBC":     "obc",
+		" Sports": "sports",
+	}
+	for in, want := range tests {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if !IsValid("SC") || IsValid("nri") {
+		t.Error("IsValid should accept known categories in any case and reject others")
+	}
+}
+
+func TestValidateQuotas(t *testing.T) {
+	tests := []struct {
+		name   string
+		seats  int
+		quotas []models.Quota
+		want   error
+	}{
+		{"no quotas", 10, nil, nil},
+		{"within seats", 10, []models.Quota{{Category: "sc", Seats: 2}, {Category: "ST", Seats: 1}}, nil},
+		{"every seat reserved", 3, []models.Quota{{Category: "sc", Seats: 3}}, nil},
+		{"overbooked", 3, []models.Quota{{Category: "sc", Seats: 2}, {Category: "st", Seats: 2}}, ErrOverbooked},
+		{"unknown category", 10, []models.Quota{{Category: "nri", Seats: 1}}, ErrUnknownCategory},
+		{"general set", 10, []models.Quota{{Category: "general", Seats: 1}}, ErrGeneralQuota},
+		{"duplicate after normalizing", 10, []models.Quota{{Category: "sc", Seats: 1}, {Category: "SC", Seats: 1}}, ErrDuplicateQuota},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateQuotas(tt.seats, tt.quotas); !errors.Is(err, tt.want) {
+				t.Errorf("ValidateQuotas() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConversions(t *testing.T) {
+	tests := []struct {
+		name        string
+		conversions []models.SeatConversion
+		want        error
+	}{
+		{"none", nil, nil},
+		{"to general", []models.SeatConversion{{From: "sports", To: "general"}}, nil},
+		{"unknown from", []models.SeatConversion{{From: "nri", To: "general"}}, ErrUnknownCategory},
+		{"unknown to", []models.SeatConversion{{From: "sc", To: "nri"}}, ErrUnknownCategory},
+		{"to itself", []models.SeatConversion{{From: "sc", To: "SC"}}, ErrSelfConversion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateConversions(tt.conversions); !errors.Is(err, tt.want) {
+				t.Errorf("ValidateConversions() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	tests := map[string][]string{
+		"":        {General},
+		"general": {General},
+		"OBC":     {"obc", General},
+	}
+	for category, want := range tests {
+		if got := Buckets(category); !reflect.DeepEqual(got, want) {
+			t.Errorf("Buckets(%q) = %v, want %v", category, got, want)
+		}
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	now := time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	course := &models.Course{ID: primitive.NewObjectID(), Seats: 10}
+	eight := 8
+	cycleWith := func(entry models.CycleCourse, conversions ...models.SeatConversion) *models.AdmissionCycle {
+		entry.CourseID = course.ID
+		return &models.AdmissionCycle{
+			OpensAt:     now.Add(-48 * time.Hour),
+			ClosesAt:    future,
+			Courses:     []models.CycleCourse{entry},
+			Conversions: conversions,
+		}
+	}
+	quotas := []models.Quota{{Category: "sc", Seats: 3}, {Category: "sports", Seats: 2}}
+
+	tests := []struct {
+		name   string
+		cycle  *models.AdmissionCycle
+		filled map[string]int
+		want   []models.SeatMatrixRow
+	}{
+		{
+			name:  "general only",
+			cycle: cycleWith(models.CycleCourse{}),
+			want:  []models.SeatMatrixRow{{Category: General, Seats: 10, Limit: 10, Remaining: 10}},
+		},
+		{
+			name:   "quotas take seats from general",
+			cycle:  cycleWith(models.CycleCourse{Quotas: quotas}),
+			filled: map[string]int{General: 2, "sc": 3},
+			want: []models.SeatMatrixRow{
+				{Category: General, Seats: 5, Limit: 5, Filled: 2, Remaining: 3},
+				{Category: "sc", Seats: 3, Limit: 3, Filled: 3},
+				{Category: "sports", Seats: 2, Limit: 2, Remaining: 2},
+			},
+		},
+		{
+			name:  "cycle overrides course seats",
+			cycle: cycleWith(models.CycleCourse{Seats: &eight, Quotas: quotas}),
+			want: []models.SeatMatrixRow{
+				{Category: General, Seats: 3, Limit: 3, Remaining: 3},
+				{Category: "sc", Seats: 3, Limit: 3, Remaining: 3},
+				{Category: "sports", Seats: 2, Limit: 2, Remaining: 2},
+			},
+		},
+		{
+			name:   "active conversion moves unfilled seats",
+			cycle:  cycleWith(models.CycleCourse{Quotas: quotas}, models.SeatConversion{From: "sports", To: General, After: &past}),
+			filled: map[string]int{"sports": 1},
+			want: []models.SeatMatrixRow{
+				{Category: General, Seats: 5, Converted: 1, Limit: 6, Remaining: 6},
+				{Category: "sc", Seats: 3, Limit: 3, Remaining: 3},
+				{Category: "sports", Seats: 2, Converted: -1, Limit: 1, Filled: 1},
+			},
+		},
+		{
+			name:  "future conversion is ignored",
+			cycle: cycleWith(models.CycleCourse{Quotas: quotas}, models.SeatConversion{From: "sports", To: General, After: &future}),
+			want: []models.SeatMatrixRow{
+				{Category: General, Seats: 5, Limit: 5, Remaining: 5},
+				{Category: "sc", Seats: 3, Limit: 3, Remaining: 3},
+				{Category: "sports", Seats: 2, Limit: 2, Remaining: 2},
+			},
+		},
+		{
+			name: "conversions chain in order",
+			cycle: cycleWith(models.CycleCourse{Quotas: quotas},
+				models.SeatConversion{From: "sports", To: "sc", After: &past},
+				models.SeatConversion{From: "sc", To: General, After: &past},
+			),
+			filled: map[string]int{"sc": 1},
+			want: []models.SeatMatrixRow{
+				{Category: General, Seats: 5, Converted: 4, Limit: 9, Remaining: 9},
+				{Category: "sc", Seats: 3, Converted: -2, Limit: 1, Filled: 1},
+				{Category: "sports", Seats: 2, Converted: -2, Remaining: 0},
+			},
+		},
+		{
+			name:   "overfilled quota has nothing remaining",
+			cycle:  cycleWith(models.CycleCourse{Quotas: quotas}),
+			filled: map[string]int{"sc": 4},
+			want: []models.SeatMatrixRow{
+				{Category: General, Seats: 5, Limit: 5, Remaining: 5},
+				{Category: "sc", Seats: 3, Limit: 3, Filled: 4},
+				{Category: "sports", Seats: 2, Limit: 2, Remaining: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Matrix(tt.cycle, course, tt.filled, now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Matrix() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+
+	matrix := Matrix(cycleWith(models.CycleCourse{Quotas: quotas}), course, nil, now)
+	if got := Limit(matrix, "sc"); got != 3 {
+		t.Errorf("Limit(sc) = %d, want 3", got)
+	}
+	if got := Limit(matrix, "obc"); got != 0 {
+		t.Errorf("Limit(obc) = %d, want 0 for a category without a quota", got)
+	}
+}
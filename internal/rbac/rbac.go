@@ -0,0 +1,154 @@
+// Package rbac maps user roles to the permissions they grant. Handlers and
+// middleware ask whether a role has a permission instead of comparing role
+// names, so roles can be reshaped here without touching call sites.
+package rbac
+
+import (
+	"sort"
+
+	"admission-portal-backend/internal/workflow"
+)
+
+type Permission string
+
+const (
+	// AdmissionsRead allows viewing every student's applications and documents.
+	AdmissionsRead Permission = "admissions:read"
+	// AdmissionsReview allows the screening moves: under review, shortlist,
+	// waitlist and reject.
+	AdmissionsReview Permission = "admissions:review"
+	// AdmissionsDecide allows making offers and enrolling applicants.
+	AdmissionsDecide Permission = "admissions:decide"
+	CoursesWrite     Permission = "courses:write"
+	FeesRead         Permission = "fees:read"
+	FeesRefund       Permission = "fees:refund"
+	UsersRead        Permission = "users:read"
+	RolesManage      Permission = "roles:manage"
+	AuditRead        Permission = "audit:read"
+	// CyclesWrite allows opening admission cycles and changing their dates
+	// and seats.
+	CyclesWrite Permission = "cycles:write"
+)
+
+const (
+	RoleSuperAdmin        = "super_admin"
+	RoleAdmissionsOfficer = "admissions_officer"
+	RoleReviewer          = "reviewer"
+	RoleFinance           = "finance"
+	RoleStudent           = "student"
+)
+
+// RoleLegacyAdmin is the single admin role used before roles were split up.
+// It is treated as RoleSuperAdmin, and may still be stored on old accounts.
+const RoleLegacyAdmin = "admin"
+
+var grants = map[string][]Permission{
+	RoleSuperAdmin: {
+		AdmissionsRead, AdmissionsReview, AdmissionsDecide, CoursesWrite, CyclesWrite,
+		FeesRead, FeesRefund, UsersRead, RolesManage, AuditRead,
+	},
+	RoleAdmissionsOfficer: {AdmissionsRead, AdmissionsReview, AdmissionsDecide, CoursesWrite, CyclesWrite, UsersRead},
+	RoleReviewer:          {AdmissionsRead, AdmissionsReview},
+	RoleFinance:           {AdmissionsRead, FeesRead, FeesRefund},
+	RoleStudent:           {},
+}
+
+// transitionPermissions is the permission staff need to move an admission
+// into each status. Statuses only students or the system can reach have no
+// entry.
+var transitionPermissions = map[string]Permission{
+	workflow.StatusUnderReview: AdmissionsReview,
+	workflow.StatusShortlisted: AdmissionsReview,
+	workflow.StatusWaitlisted:  AdmissionsReview,
+	workflow.StatusRejected:    AdmissionsReview,
+	workflow.StatusOffered:     AdmissionsDecide,
+	workflow.StatusEnrolled:    AdmissionsDecide,
+}
+
+// Normalize returns the current name for role, translating the legacy
+// "admin" role.
+func Normalize(role string) string {
+	if role == RoleLegacyAdmin {
+		return RoleSuperAdmin
+	}
+	return role
+}
+
+// IsValid reports whether role is a known role.
+func IsValid(role string) bool {
+	_, ok := grants[Normalize(role)]
+	return ok
+}
+
+// IsStaff reports whether role is a known role other than student.
+func IsStaff(role string) bool {
+	return IsValid(role) && Normalize(role) != RoleStudent
+}
+
+// Roles lists every role in alphabetical order.
+func Roles() []string {
+	roles := make([]string, 0, len(grants))
+	for role := range grants {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// StaffRoles lists the stored role values that denote staff, including the
+// legacy admin role, for use in queries.
+func StaffRoles() []string {
+	roles := []string{RoleLegacyAdmin}
+	for _, role := range Roles() {
+		if role != RoleStudent {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// Permissions returns the permissions granted to role; unknown roles have
+// none.
+func Permissions(role string) []Permission {
+	return append([]Permission{}, grants[Normalize(role)]...)
+}
+
+// Can reports whether role has every one of perms.
+func Can(role string, perms ...Permission) bool {
+	granted := grants[Normalize(role)]
+	for _, perm := range perms {
+		found := false
+		for _, g := range granted {
+			if g == perm {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// WorkflowRole returns the workflow role a user acts as: students as
+// students, every staff role as workflow.RoleStaff. The system role passes
+// through unchanged.
+func WorkflowRole(role string) string {
+	switch {
+	case role == workflow.RoleSystem:
+		return workflow.RoleSystem
+	case IsStaff(role):
+		return workflow.RoleStaff
+	case Normalize(role) == RoleStudent:
+		return workflow.RoleStudent
+	}
+	return role
+}
+
+// TransitionPermission returns the permission staff need to move an
+// admission into status, if any.
+func TransitionPermission(status string) (Permission, bool) {
+	perm, ok := transitionPermissions[status]
+	return perm, ok
+}
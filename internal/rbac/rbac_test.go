@@ -0,0 +1,94 @@
+package rbac
+
+import (
+	"testing"
+
+	"admission-portal-backend/internal/workflow"
+)
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		role  string
+		perms []Permission
+		want  bool
+	}{
+		{RoleSuperAdmin, []Permission{RolesManage, AuditRead, FeesRefund}, true},
+		{RoleLegacyAdmin, []Permission{RolesManage}, true},
+		{RoleAdmissionsOfficer, []Permission{AdmissionsDecide, CyclesWrite}, true},
+		{RoleAdmissionsOfficer, []Permission{FeesRefund}, false},
+		{RoleReviewer, []Permission{AdmissionsRead, AdmissionsReview}, true},
+		{RoleReviewer, []Permission{AdmissionsReview, AdmissionsDecide}, false},
+		{RoleFinance, []Permission{FeesRefund}, true},
+		{RoleFinance, []Permission{AdmissionsReview}, false},
+		{RoleStudent, []Permission{AdmissionsRead}, false},
+		{"janitor", []Permission{AdmissionsRead}, false},
+		// Asking for nothing is always allowed
+		{RoleStudent, nil, true},
+	}
+	for _, tt := range tests {
+		if got := Can(tt.role, tt.perms...); got != tt.want {
+			t.Errorf("Can(%s, %v) = %v, want %v", tt.role, tt.perms, got, tt.want)
+		}
+	}
+}
+
+func TestRoles(t *testing.T) {
+	for _, role := range []string{RoleSuperAdmin, RoleLegacyAdmin, RoleAdmissionsOfficer, RoleReviewer, RoleFinance} {
+		if !IsValid(role) || !IsStaff(role) {
+			t.Errorf("%s: IsValid %v, IsStaff %v, want both", role, IsValid(role), IsStaff(role))
+		}
+	}
+	if !IsValid(RoleStudent) || IsStaff(RoleStudent) {
+		t.Error("students should be a valid role that is not staff")
+	}
+	if IsValid("janitor") || IsStaff("janitor") {
+		t.Error("unknown roles should be neither valid nor staff")
+	}
+
+	staff := StaffRoles()
+	if len(staff) != len(Roles()) || staff[0] != RoleLegacyAdmin {
+		t.Errorf("StaffRoles() = %v, want the legacy admin role and every role but student", staff)
+	}
+	for _, role := range staff {
+		if role == RoleStudent {
+			t.Errorf("StaffRoles() includes %s", role)
+		}
+	}
+}
+
+func TestPermissionsReturnsCopy(t *testing.T) {
+	perms := Permissions(RoleReviewer)
+	perms[0] = RolesManage
+	if Can(RoleReviewer, RolesManage) {
+		t.Error("changing the result of Permissions changed the grants")
+	}
+}
+
+func TestWorkflowRole(t *testing.T) {
+	tests := map[string]string{
+		RoleStudent:         workflow.RoleStudent,
+		RoleReviewer:        workflow.RoleStaff,
+		RoleLegacyAdmin:     workflow.RoleStaff,
+		workflow.RoleSystem: workflow.RoleSystem,
+	}
+	for role, want := range tests {
+		if got := WorkflowRole(role); got != want {
+			t.Errorf("WorkflowRole(%s) = %s, want %s", role, got, want)
+		}
+	}
+}
+
+func TestTransitionPermission(t *testing.T) {
+	if perm, ok := TransitionPermission(workflow.StatusOffered); !ok || perm != AdmissionsDecide {
+		t.Errorf("offered needs %s, %v, want admissions:decide", perm, ok)
+	}
+	if perm, ok := TransitionPermission(workflow.StatusRejected); !ok || perm != AdmissionsReview {
+		t.Errorf("rejected needs %s, %v, want admissions:review", perm, ok)
+	}
+	// Only students accept and withdraw
+	for _, status := range []string{workflow.StatusAccepted, workflow.StatusWithdrawn} {
+		if _, ok := TransitionPermission(status); ok {
+			t.Errorf("staff permission defined for %s", status)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+)
+
+// NewMemoryStore returns thread-safe repositories that keep everything in
+// process memory. It is meant for local development and tests.
+func NewMemoryStore() *Store {
+	return &Store{
+		Students:   &memoryStudentRepository{table: newMemoryTable[models.Student]()},
+		Courses:    &memoryCourseRepository{table: newMemoryTable[models.Course]()},
+		Admissions: &memoryAdmissionRepository{table: newMemoryTable[models.Admission]()},
+		Sessions:   &memorySessionRepository{table: newMemoryTable[models.Session]()},
+		Tokens:     &memoryActionTokenRepository{table: newMemoryTable[models.ActionToken]()},
+		Invites:    &memoryInviteRepository{table: newMemoryTable[models.Invite]()},
+		Documents:  &memoryDocumentRepository{table: newMemoryTable[models.Document]()},
+		Audit:      &memoryAuditRepository{table: newMemoryTable[models.AuditEvent]()},
+		AuditQueue: &memoryAuditQueueRepository{table: newMemoryTable[models.PendingAuditEvent]()},
+		MeritLists: &memoryMeritListRepository{table: newMemoryTable[models.MeritList]()},
+		Cycles:     &memoryCycleRepository{table: newMemoryTable[models.AdmissionCycle]()},
+		CycleSeats: &memoryCycleSeatRepository{filled: map[memoryCycleSeatKey]int{}},
+		Payments:   &memoryPaymentRepository{table: newMemoryTable[models.Payment]()},
+
+		IdempotencyKeys: &memoryIdempotencyRepository{table: newMemoryTable[models.IdempotencyRecord]()},
+	}
+}
+
+// memoryTable stores documents in insertion order. Documents are kept in
+// their BSON encoding so callers always receive deep copies and see the same
+// field handling (omitempty, time precision) as they would with MongoDB.
+type memoryTable[T any] struct {
+	mu   sync.RWMutex
+	ids  []primitive.ObjectID
+	docs map[primitive.ObjectID][]byte
+}
+
+func newMemoryTable[T any]() *memoryTable[T] {
+	return &memoryTable[T]{docs: make(map[primitive.ObjectID][]byte)}
+}
+
+func (t *memoryTable[T]) insert(id primitive.ObjectID, doc *T) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, exists := t.docs[id]; exists {
+		return ErrDuplicate
+	}
+	t.ids = append(t.ids, id)
+	t.docs[id] = raw
+	return nil
+}
+
+func (t *memoryTable[T]) get(id primitive.ObjectID) (*T, error) {
+	t.mu.RLock()
+	raw, ok := t.docs[id]
+	t.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	var doc T
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// find returns every document accepted by match, in insertion order.
+func (t *memoryTable[T]) find(match func(*T) bool) ([]T, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	results := []T{}
+	for _, id := range t.ids {
+		var doc T
+		if err := bson.Unmarshal(t.docs[id], &doc); err != nil {
+			return nil, err
+		}
+		if match == nil || match(&doc) {
+			results = append(results, doc)
+		}
+	}
+	return results, nil
+}
+
+// update applies fn to the stored document while holding the write lock, so
+// read-modify-write sequences are atomic. If fn returns an error the stored
+// document is left untouched.
+func (t *memoryTable[T]) update(id primitive.ObjectID, fn func(*T) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	raw, ok := t.docs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	var doc T
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+	if err := fn(&doc); err != nil {
+		return err
+	}
+	raw, err := bson.Marshal(&doc)
+	if err != nil {
+		return err
+	}
+	t.docs[id] = raw
+	return nil
+}
+
+func (t *memoryTable[T]) delete(id primitive.ObjectID) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.docs[id]; !ok {
+		return ErrNotFound
+	}
+	delete(t.docs, id)
+	for i, existing := range t.ids {
+		if existing == id {
+			t.ids = append(t.ids[:i], t.ids[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/workflow"
+)
+
+type memoryAdmissionRepository struct {
+	table *memoryTable[models.Admission]
+	// mu makes the duplicate application check and the insert one step.
+	mu sync.Mutex
+}
+
+func (r *memoryAdmissionRepository) Create(ctx context.Context, admission *models.Admission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, err := r.table.find(func(a *models.Admission) bool {
+		return a.StudentID == admission.StudentID && a.CourseID == admission.CourseID && a.Cycle == admission.Cycle
+	})
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return ErrDuplicate
+	}
+	if admission.ID.IsZero() {
+		admission.ID = primitive.NewObjectID()
+	}
+	return r.table.insert(admission.ID, admission)
+}
+
+func (r *memoryAdmissionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Admission, error) {
+	return r.table.get(id)
+}
+
+func (r *memoryAdmissionRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.Admission], error) {
+	return r.table.list(opts)
+}
+
+func (r *memoryAdmissionRepository) Transition(ctx context.Context, id primitive.ObjectID, change models.StatusChange) error {
+	return r.table.update(id, func(a *models.Admission) error {
+		if a.Status != change.From {
+			return ErrConflict
+		}
+		a.Status = change.To
+		a.Comments = change.Comments
+		a.SeatQuota = change.SeatQuota
+		a.OfferExpiresAt = change.OfferExpiresAt
+		a.WaitlistedAt = waitlistedAt(change)
+		a.UpdatedAt = change.ChangedAt
+		a.StatusHistory = append(a.StatusHistory, change)
+		return nil
+	})
+}
+
+func (r *memoryAdmissionRepository) Allocate(ctx context.Context, id primitive.ObjectID, courseID primitive.ObjectID, preferences []models.Preference, change models.StatusChange) error {
+	return r.table.update(id, func(a *models.Admission) error {
+		if a.Status != change.From {
+			return ErrConflict
+		}
+		a.Status = change.To
+		a.Comments = change.Comments
+		a.SeatQuota = change.SeatQuota
+		a.OfferExpiresAt = change.OfferExpiresAt
+		a.WaitlistedAt = waitlistedAt(change)
+		a.CourseID = courseID
+		a.Preferences = preferences
+		a.UpdatedAt = change.ChangedAt
+		a.StatusHistory = append(a.StatusHistory, change)
+		return nil
+	})
+}
+
+func (r *memoryAdmissionRepository) UpdateDraft(ctx context.Context, admission *models.Admission) error {
+	return r.saveDraft(admission, nil)
+}
+
+func (r *memoryAdmissionRepository) Submit(ctx context.Context, admission *models.Admission, change *models.StatusChange) error {
+	return r.saveDraft(admission, change)
+}
+
+// saveDraft stores the draft's contents and, when change is not nil, moves
+// it on, provided it is still an unsubmitted draft.
+func (r *memoryAdmissionRepository) saveDraft(admission *models.Admission, change *models.StatusChange) error {
+	return r.table.update(admission.ID, func(a *models.Admission) error {
+		if a.Status != workflow.StatusDraft || a.SubmittedAt != nil {
+			return ErrConflict
+		}
+		a.CourseID = admission.CourseID
+		a.PersonalDetails = admission.PersonalDetails
+		a.AcademicDetails = admission.AcademicDetails
+		a.Documents = admission.Documents
+		a.Eligibility = admission.Eligibility
+		a.Preferences = admission.Preferences
+		a.Category = admission.Category
+		a.Cycle = admission.Cycle
+		a.ApplicationFee = admission.ApplicationFee
+		a.LateFee = admission.LateFee
+		a.Late = admission.Late
+		a.SubmittedAt = admission.SubmittedAt
+		a.UpdatedAt = admission.UpdatedAt
+		if change != nil {
+			a.Status = change.To
+			a.Comments = change.Comments
+			a.UpdatedAt = change.ChangedAt
+			a.StatusHistory = append(a.StatusHistory, *change)
+		}
+		return nil
+	})
+}
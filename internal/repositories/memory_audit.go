@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"encoding/binary"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type memoryAuditRepository struct {
+	table *memoryTable[models.AuditEvent]
+}
+
+// sequenceKey packs an audit sequence number into the ObjectID key the
+// memory table uses, so the table's duplicate check guards the sequence.
+func sequenceKey(sequence int64) primitive.ObjectID {
+	var key primitive.ObjectID
+	binary.BigEndian.PutUint64(key[4:], uint64(sequence))
+	return key
+}
+
+func (r *memoryAuditRepository) Append(ctx context.Context, event *models.AuditEvent) error {
+	return r.table.insert(sequenceKey(event.Sequence), event)
+}
+
+func (r *memoryAuditRepository) Last(ctx context.Context) (*models.AuditEvent, error) {
+	page, err := r.table.list(query.Options{Sort: []query.Sort{{Field: "_id", Desc: true}}, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, ErrNotFound
+	}
+	return &page.Items[0], nil
+}
+
+func (r *memoryAuditRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.AuditEvent], error) {
+	return r.table.list(opts)
+}
+
+type memoryAuditQueueRepository struct {
+	table *memoryTable[models.PendingAuditEvent]
+}
+
+func (r *memoryAuditQueueRepository) Add(ctx context.Context, pending *models.PendingAuditEvent) error {
+	if pending.ID.IsZero() {
+		pending.ID = primitive.NewObjectID()
+	}
+	return r.table.insert(pending.ID, pending)
+}
+
+func (r *memoryAuditQueueRepository) Oldest(ctx context.Context, limit int) ([]models.PendingAuditEvent, error) {
+	page, err := r.table.list(query.Options{Sort: []query.Sort{{Field: "_id"}}, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+func (r *memoryAuditQueueRepository) Remove(ctx context.Context, id primitive.ObjectID) error {
+	return r.table.delete(id)
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type memoryCourseRepository struct {
+	table *memoryTable[models.Course]
+}
+
+func (r *memoryCourseRepository) Create(ctx context.Context, course *models.Course) error {
+	if course.ID.IsZero() {
+		course.ID = primitive.NewObjectID()
+	}
+	return r.table.insert(course.ID, course)
+}
+
+func (r *memoryCourseRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Course, error) {
+	return r.table.get(id)
+}
+
+func (r *memoryCourseRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.Course], error) {
+	return r.table.list(opts)
+}
+
+func (r *memoryCourseRepository) Update(ctx context.Context, course *models.Course) error {
+	return r.table.update(course.ID, func(stored *models.Course) error {
+		stored.Name = course.Name
+		stored.Description = course.Description
+		stored.Duration = course.Duration
+		stored.Seats = course.Seats
+		stored.EligibilityCriteria = course.EligibilityCriteria
+		stored.Fees = course.Fees
+		stored.UpdatedAt = course.UpdatedAt
+		return nil
+	})
+}
+
+func (r *memoryCourseRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return r.table.delete(id)
+}
+
+func (r *memoryCourseRepository) ReserveSeat(ctx context.Context, id primitive.ObjectID) error {
+	return r.table.update(id, func(course *models.Course) error {
+		if course.SeatsFilled >= course.Seats {
+			return ErrNoSeats
+		}
+		course.SeatsFilled++
+		return nil
+	})
+}
+
+func (r *memoryCourseRepository) ReleaseSeat(ctx context.Context, id primitive.ObjectID) error {
+	return r.table.update(id, func(course *models.Course) error {
+		if course.SeatsFilled > 0 {
+			course.SeatsFilled--
+		}
+		return nil
+	})
+}
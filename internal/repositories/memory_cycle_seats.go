@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryCycleSeatKey struct {
+	cycle    string
+	courseID primitive.ObjectID
+	quota    string
+}
+
+type memoryCycleSeatRepository struct {
+	mu     sync.Mutex
+	filled map[memoryCycleSeatKey]int
+}
+
+func (r *memoryCycleSeatRepository) Reserve(ctx context.Context, cycle string, courseID primitive.ObjectID, quota string, limit int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryCycleSeatKey{cycle, courseID, quota}
+	if r.filled[key] >= limit {
+		return ErrNoSeats
+	}
+	r.filled[key]++
+	return nil
+}
+
+func (r *memoryCycleSeatRepository) Release(ctx context.Context, cycle string, courseID primitive.ObjectID, quota string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryCycleSeatKey{cycle, courseID, quota}
+	if r.filled[key] > 0 {
+		r.filled[key]--
+	}
+	return nil
+}
+
+func (r *memoryCycleSeatRepository) Filled(ctx context.Context, cycle string, courseID primitive.ObjectID) (map[string]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	filled := map[string]int{}
+	for key, count := range r.filled {
+		if key.cycle == cycle && key.courseID == courseID && count > 0 {
+			filled[key.quota] = count
+		}
+	}
+	return filled, nil
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type memoryCycleRepository struct {
+	table *memoryTable[models.AdmissionCycle]
+	// mu makes the duplicate code check and the insert one step.
+	mu sync.Mutex
+}
+
+func (r *memoryCycleRepository) Create(ctx context.Context, cycle *models.AdmissionCycle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.FindByCode(ctx, cycle.Code); err == nil {
+		return ErrDuplicate
+	}
+	if cycle.ID.IsZero() {
+		cycle.ID = primitive.NewObjectID()
+	}
+	return r.table.insert(cycle.ID, cycle)
+}
+
+func (r *memoryCycleRepository) FindByCode(ctx context.Context, code string) (*models.AdmissionCycle, error) {
+	cycles, err := r.table.find(func(c *models.AdmissionCycle) bool { return c.Code == code })
+	if err != nil {
+		return nil, err
+	}
+	if len(cycles) == 0 {
+		return nil, ErrNotFound
+	}
+	return &cycles[0], nil
+}
+
+func (r *memoryCycleRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.AdmissionCycle], error) {
+	return r.table.list(opts)
+}
+
+func (r *memoryCycleRepository) Update(ctx context.Context, cycle *models.AdmissionCycle) error {
+	return r.table.update(cycle.ID, func(existing *models.AdmissionCycle) error {
+		existing.Name = cycle.Name
+		existing.OpensAt = cycle.OpensAt
+		existing.ClosesAt = cycle.ClosesAt
+		existing.LateSubmission = cycle.LateSubmission
+		existing.Courses = cycle.Courses
+		existing.Conversions = cycle.Conversions
+		existing.UpdatedAt = cycle.UpdatedAt
+		return nil
+	})
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+)
+
+type memoryDocumentRepository struct {
+	table *memoryTable[models.Document]
+}
+
+func (r *memoryDocumentRepository) Create(ctx context.Context, document *models.Document) error {
+	if document.ID.IsZero() {
+		document.ID = primitive.NewObjectID()
+	}
+	return r.table.insert(document.ID, document)
+}
+
+func (r *memoryDocumentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Document, error) {
+	return r.table.get(id)
+}
+
+func (r *memoryDocumentRepository) ListByOwner(ctx context.Context, ownerID primitive.ObjectID) ([]models.Document, error) {
+	return r.table.find(func(d *models.Document) bool { return d.OwnerID == ownerID })
+}
+
+func (r *memoryDocumentRepository) ListByAdmission(ctx context.Context, admissionID primitive.ObjectID) ([]models.Document, error) {
+	return r.table.find(func(d *models.Document) bool { return d.AdmissionID != nil && *d.AdmissionID == admissionID })
+}
+
+func (r *memoryDocumentRepository) AttachToAdmission(ctx context.Context, id, ownerID, admissionID primitive.ObjectID) error {
+	return r.table.update(id, func(d *models.Document) error {
+		if d.OwnerID != ownerID {
+			return ErrNotFound
+		}
+		if d.AdmissionID != nil && *d.AdmissionID != admissionID {
+			return ErrConflict
+		}
+		d.AdmissionID = &admissionID
+		return nil
+	})
+}
+
+func (r *memoryDocumentRepository) DetachFromAdmission(ctx context.Context, id, admissionID primitive.ObjectID) error {
+	return r.table.update(id, func(d *models.Document) error {
+		if d.AdmissionID != nil && *d.AdmissionID == admissionID {
+			d.AdmissionID = nil
+		}
+		return nil
+	})
+}
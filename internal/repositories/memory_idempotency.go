@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+)
+
+type memoryIdempotencyRepository struct {
+	table *memoryTable[models.IdempotencyRecord]
+	// mu makes looking for an earlier use of a key and reserving it one step.
+	mu sync.Mutex
+}
+
+func (r *memoryIdempotencyRepository) Reserve(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, err := r.table.find(func(e *models.IdempotencyRecord) bool {
+		return e.UserID == record.UserID && e.Key == record.Key
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range existing {
+		if existing[i].ExpiresAt.After(time.Now()) {
+			return &existing[i], ErrDuplicate
+		}
+		r.table.delete(existing[i].ID)
+	}
+
+	if record.ID.IsZero() {
+		record.ID = primitive.NewObjectID()
+	}
+	if err := r.table.insert(record.ID, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (r *memoryIdempotencyRepository) Complete(ctx context.Context, id primitive.ObjectID, statusCode int, contentType string, body []byte) error {
+	return r.table.update(id, func(e *models.IdempotencyRecord) error {
+		e.StatusCode = statusCode
+		e.ContentType = contentType
+		e.Body = body
+		return nil
+	})
+}
+
+func (r *memoryIdempotencyRepository) Release(ctx context.Context, id primitive.ObjectID) error {
+	r.table.delete(id)
+	return nil
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type memoryInviteRepository struct {
+	table *memoryTable[models.Invite]
+}
+
+func (r *memoryInviteRepository) Create(ctx context.Context, invite *models.Invite) error {
+	if invite.ID.IsZero() {
+		invite.ID = primitive.NewObjectID()
+	}
+	return r.table.insert(invite.ID, invite)
+}
+
+func (r *memoryInviteRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Invite, error) {
+	return r.table.get(id)
+}
+
+func (r *memoryInviteRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.Invite], error) {
+	return r.table.list(opts)
+}
+
+func (r *memoryInviteRepository) Accept(ctx context.Context, id, userID primitive.ObjectID, now time.Time) error {
+	return r.table.update(id, func(invite *models.Invite) error {
+		if invite.CurrentStatus(now) != models.InvitePending {
+			return ErrConflict
+		}
+		invite.AcceptedAt = &now
+		invite.UserID = &userID
+		return nil
+	})
+}
+
+func (r *memoryInviteRepository) Reopen(ctx context.Context, id primitive.ObjectID) error {
+	return r.table.update(id, func(invite *models.Invite) error {
+		invite.AcceptedAt = nil
+		invite.UserID = nil
+		return nil
+	})
+}
+
+func (r *memoryInviteRepository) Revoke(ctx context.Context, id primitive.ObjectID, now time.Time) error {
+	return r.table.update(id, func(invite *models.Invite) error {
+		if invite.CurrentStatus(now) != models.InvitePending {
+			return ErrConflict
+		}
+		invite.RevokedAt = &now
+		return nil
+	})
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type memoryMeritListRepository struct {
+	table *memoryTable[models.MeritList]
+	// mu makes the duplicate version check and the insert one step.
+	mu sync.Mutex
+}
+
+func (r *memoryMeritListRepository) Create(ctx context.Context, list *models.MeritList) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, err := r.table.find(func(l *models.MeritList) bool {
+		return l.CourseID == list.CourseID && l.Cycle == list.Cycle && l.Version == list.Version
+	})
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return ErrDuplicate
+	}
+
+	if list.ID.IsZero() {
+		list.ID = primitive.NewObjectID()
+	}
+	return r.table.insert(list.ID, list)
+}
+
+func (r *memoryMeritListRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.MeritList, error) {
+	return r.table.get(id)
+}
+
+func (r *memoryMeritListRepository) Latest(ctx context.Context, courseID primitive.ObjectID, cycle string) (*models.MeritList, error) {
+	opts := query.Options{Sort: []query.Sort{{Field: "version", Desc: true}}, Limit: 1}
+	page, err := r.table.list(opts.Where("courseId", query.OpEq, courseID).Where("cycle", query.OpEq, cycle))
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, ErrNotFound
+	}
+	return &page.Items[0], nil
+}
+
+func (r *memoryMeritListRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.MeritList], error) {
+	return r.table.list(opts)
+}
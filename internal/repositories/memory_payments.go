@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type memoryPaymentRepository struct {
+	table *memoryTable[models.Payment]
+	// captureMu serializes captures, so checking for another captured
+	// payment and capturing this one happen as a unit.
+	captureMu sync.Mutex
+}
+
+func (r *memoryPaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	if payment.ID.IsZero() {
+		payment.ID = primitive.NewObjectID()
+	}
+	return r.table.insert(payment.ID, payment)
+}
+
+func (r *memoryPaymentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Payment, error) {
+	return r.table.get(id)
+}
+
+func (r *memoryPaymentRepository) FindByIntent(ctx context.Context, intentID string) (*models.Payment, error) {
+	payments, err := r.table.find(func(p *models.Payment) bool { return p.IntentID == intentID })
+	if err != nil {
+		return nil, err
+	}
+	if len(payments) == 0 {
+		return nil, ErrNotFound
+	}
+	return &payments[0], nil
+}
+
+func (r *memoryPaymentRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.Payment], error) {
+	return r.table.list(opts)
+}
+
+func (r *memoryPaymentRepository) Capture(ctx context.Context, id primitive.ObjectID, receipt models.Receipt) error {
+	r.captureMu.Lock()
+	defer r.captureMu.Unlock()
+
+	payment, err := r.table.get(id)
+	if err != nil {
+		return err
+	}
+	captured, err := r.table.find(func(p *models.Payment) bool {
+		return p.ID != id && p.AdmissionID == payment.AdmissionID && p.Purpose == payment.Purpose && p.Status == models.PaymentCaptured
+	})
+	if err != nil {
+		return err
+	}
+	if len(captured) > 0 {
+		return ErrDuplicate
+	}
+
+	return r.table.update(id, func(p *models.Payment) error {
+		if p.Status != models.PaymentPending {
+			return ErrConflict
+		}
+		p.Status = models.PaymentCaptured
+		p.Receipt = &receipt
+		p.CapturedAt = &receipt.IssuedAt
+		p.UpdatedAt = receipt.IssuedAt
+		return nil
+	})
+}
+
+func (r *memoryPaymentRepository) Fail(ctx context.Context, id primitive.ObjectID, reason string, at time.Time) error {
+	return r.table.update(id, func(p *models.Payment) error {
+		if p.Status != models.PaymentPending {
+			return ErrConflict
+		}
+		p.Status = models.PaymentFailed
+		p.FailureReason = reason
+		p.UpdatedAt = at
+		return nil
+	})
+}
+
+func (r *memoryPaymentRepository) AddRefund(ctx context.Context, id primitive.ObjectID, refund models.Refund) error {
+	return r.table.update(id, func(p *models.Payment) error {
+		if findRefund(p, refund.Key) != nil {
+			return ErrDuplicate
+		}
+		if p.Status != models.PaymentCaptured || p.Refunded+refund.Amount > p.Amount {
+			return ErrConflict
+		}
+		refund.Status = models.RefundPending
+		p.Refunded += refund.Amount
+		p.Refunds = append(p.Refunds, refund)
+		p.UpdatedAt = refund.CreatedAt
+		return nil
+	})
+}
+
+func (r *memoryPaymentRepository) SettleRefund(ctx context.Context, id primitive.ObjectID, key, gatewayRef string, at time.Time) error {
+	return r.table.update(id, func(p *models.Payment) error {
+		refund := findRefund(p, key)
+		if refund == nil || refund.Status != models.RefundPending {
+			return ErrConflict
+		}
+		refund.Status = models.RefundSucceeded
+		refund.GatewayRef = gatewayRef
+		p.UpdatedAt = at
+		if p.Status == models.PaymentCaptured && p.Refunded >= p.Amount {
+			p.Status = models.PaymentRefunded
+		}
+		return nil
+	})
+}
+
+func (r *memoryPaymentRepository) CancelRefund(ctx context.Context, id primitive.ObjectID, key string) error {
+	return r.table.update(id, func(p *models.Payment) error {
+		for i, refund := range p.Refunds {
+			if refund.Key == key && refund.Status == models.RefundPending {
+				p.Refunded -= refund.Amount
+				p.Refunds = append(append([]models.Refund{}, p.Refunds[:i]...), p.Refunds[i+1:]...)
+				return nil
+			}
+		}
+		return ErrConflict
+	})
+}
+
+func findRefund(payment *models.Payment, key string) *models.Refund {
+	for i := range payment.Refunds {
+		if payment.Refunds[i].Key == key {
+			return &payment.Refunds[i]
+		}
+	}
+	return nil
+}
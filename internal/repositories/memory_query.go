@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/query"
+)
+
+// list applies query options to the stored documents. Conditions and sort
+// keys are evaluated on the BSON encoding, so they use the same field paths
+// as the MongoDB implementation.
+func (t *memoryTable[T]) list(opts query.Options) (*query.Page[T], error) {
+	t.mu.RLock()
+	matched := []bson.Raw{}
+	for _, id := range t.ids {
+		raw := bson.Raw(t.docs[id])
+		if matchesAll(raw, opts.Conditions) {
+			matched = append(matched, raw)
+		}
+	}
+	t.mu.RUnlock()
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		for _, s := range opts.Sort {
+			c := compareValues(lookup(matched[i], s.Field), lookup(matched[j], s.Field))
+			if c == 0 {
+				continue
+			}
+			if s.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+
+	total := int64(len(matched))
+	start := opts.Offset
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := len(matched)
+	if opts.Limit > 0 && start+opts.Limit < end {
+		end = start + opts.Limit
+	}
+
+	items := make([]T, 0, end-start)
+	for _, raw := range matched[start:end] {
+		var doc T
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+		items = append(items, doc)
+	}
+	return query.NewPage(items, total, opts), nil
+}
+
+func matchesAll(raw bson.Raw, conditions []query.Condition) bool {
+	for _, cond := range conditions {
+		if cond.Op == query.OpSearch {
+			if !containsText(raw, cond.Fields, cond.Value.(string)) {
+				return false
+			}
+			continue
+		}
+		if !matches(lookup(raw, cond.Field), cond) {
+			return false
+		}
+	}
+	return true
+}
+
+func matches(value any, cond query.Condition) bool {
+	switch cond.Op {
+	case query.OpNe:
+		return !equalValues(value, cond.Value)
+	case query.OpIn:
+		for _, candidate := range cond.Value.([]any) {
+			if equalValues(value, candidate) {
+				return true
+			}
+		}
+		return false
+	case query.OpGte:
+		c := compareValues(value, cond.Value)
+		return value != nil && c != incomparable && c >= 0
+	case query.OpLte:
+		c := compareValues(value, cond.Value)
+		return value != nil && c != incomparable && c <= 0
+	default:
+		return equalValues(value, cond.Value)
+	}
+}
+
+// containsText reports whether any of the string fields contains text,
+// ignoring case.
+func containsText(raw bson.Raw, fields []string, text string) bool {
+	text = strings.ToLower(text)
+	for _, field := range fields {
+		if value, ok := lookup(raw, field).(string); ok && strings.Contains(strings.ToLower(value), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues follows MongoDB equality: a condition on an array field
+// matches if any element matches.
+func equalValues(value, want any) bool {
+	if list, ok := value.([]any); ok {
+		for _, element := range list {
+			if compareValues(element, want) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	return compareValues(value, want) == 0
+}
+
+// lookup returns the value at a dotted BSON path as a plain Go value, or nil
+// when the path does not exist.
+func lookup(raw bson.Raw, path string) any {
+	return lookupPath(bson.RawValue{Type: bsontype.EmbeddedDocument, Value: raw}, strings.Split(path, "."))
+}
+
+// lookupPath follows keys from value. As in MongoDB, a key that is not an
+// index applied to an array reaches into every element, giving the list of
+// values found.
+func lookupPath(value bson.RawValue, keys []string) any {
+	if len(keys) == 0 {
+		return fromRaw(value)
+	}
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		next, err := value.Document().LookupErr(keys[0])
+		if err != nil {
+			return nil
+		}
+		return lookupPath(next, keys[1:])
+	case bsontype.Array:
+		if _, err := strconv.Atoi(keys[0]); err == nil {
+			next, err := value.Array().LookupErr(keys[0])
+			if err != nil {
+				return nil
+			}
+			return lookupPath(next, keys[1:])
+		}
+		elements, err := value.Array().Values()
+		if err != nil {
+			return nil
+		}
+		found := []any{}
+		for _, element := range elements {
+			switch v := lookupPath(element, keys).(type) {
+			case nil:
+			case []any:
+				found = append(found, v...)
+			default:
+				found = append(found, v)
+			}
+		}
+		return found
+	}
+	return nil
+}
+
+func fromRaw(value bson.RawValue) any {
+	switch value.Type {
+	case bsontype.String:
+		return value.StringValue()
+	case bsontype.ObjectID:
+		return value.ObjectID()
+	case bsontype.DateTime:
+		return value.Time()
+	case bsontype.Double:
+		return value.Double()
+	case bsontype.Int32:
+		return float64(value.Int32())
+	case bsontype.Int64:
+		return float64(value.Int64())
+	case bsontype.Boolean:
+		return value.Boolean()
+	case bsontype.Array:
+		values, err := value.Array().Values()
+		if err != nil {
+			return nil
+		}
+		list := make([]any, 0, len(values))
+		for _, element := range values {
+			list = append(list, fromRaw(element))
+		}
+		return list
+	default:
+		return nil
+	}
+}
+
+// compareValues orders two plain values. nil sorts first, and numbers given
+// as any integer type compare with float64 values. Values of different types
+// return incomparable.
+func compareValues(a, b any) int {
+	a, b = normalize(a), normalize(b)
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch av := a.(type) {
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv)
+		}
+	case float64:
+		if bv, ok := b.(float64); ok {
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			}
+			return 0
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0
+			case !av:
+				return -1
+			}
+			return 1
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			return av.Compare(bv)
+		}
+	case primitive.ObjectID:
+		if bv, ok := b.(primitive.ObjectID); ok {
+			return bytes.Compare(av[:], bv[:])
+		}
+	}
+	return incomparable
+}
+
+// incomparable is returned by compareValues for values of different types,
+// which never match each other.
+const incomparable = 2
+
+func normalize(v any) any {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	case primitive.DateTime:
+		return n.Time()
+	}
+	return v
+}
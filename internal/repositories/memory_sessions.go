@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+)
+
+type memorySessionRepository struct {
+	table *memoryTable[models.Session]
+}
+
+func (r *memorySessionRepository) Create(ctx context.Context, session *models.Session) error {
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	return r.table.insert(session.ID, session)
+}
+
+func (r *memorySessionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	return r.table.get(id)
+}
+
+func (r *memorySessionRepository) Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, usedAt time.Time) error {
+	return r.table.update(id, func(session *models.Session) error {
+		if session.RevokedAt != nil || session.RefreshTokenHash != oldHash {
+			return ErrConflict
+		}
+		session.PreviousTokenHashes = append(session.PreviousTokenHashes, oldHash)
+		if n := len(session.PreviousTokenHashes); n > MaxPreviousTokenHashes {
+			session.PreviousTokenHashes = session.PreviousTokenHashes[n-MaxPreviousTokenHashes:]
+		}
+		session.RefreshTokenHash = newHash
+		session.LastUsedAt = usedAt
+		return nil
+	})
+}
+
+func (r *memorySessionRepository) Revoke(ctx context.Context, id primitive.ObjectID, reason string) error {
+	return r.table.update(id, func(session *models.Session) error {
+		revokeSession(session, reason)
+		return nil
+	})
+}
+
+func (r *memorySessionRepository) RevokeAllForUser(ctx context.Context, userID primitive.ObjectID, reason string) error {
+	sessions, err := r.table.find(func(s *models.Session) bool { return s.UserID == userID && s.RevokedAt == nil })
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		err := r.table.update(session.ID, func(s *models.Session) error {
+			revokeSession(s, reason)
+			return nil
+		})
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func revokeSession(session *models.Session, reason string) {
+	if session.RevokedAt != nil {
+		return
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	session.RevokedReason = reason
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type memoryStudentRepository struct {
+	table *memoryTable[models.Student]
+	// mu makes the duplicate email check and the insert one step.
+	mu sync.Mutex
+}
+
+func (r *memoryStudentRepository) Create(ctx context.Context, student *models.Student) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.FindByEmail(ctx, student.Email); err == nil {
+		return ErrDuplicate
+	}
+	if student.ID.IsZero() {
+		student.ID = primitive.NewObjectID()
+	}
+	return r.table.insert(student.ID, student)
+}
+
+func (r *memoryStudentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Student, error) {
+	return r.table.get(id)
+}
+
+func (r *memoryStudentRepository) FindByEmail(ctx context.Context, email string) (*models.Student, error) {
+	students, err := r.table.find(func(s *models.Student) bool { return s.Email == email })
+	if err != nil {
+		return nil, err
+	}
+	if len(students) == 0 {
+		return nil, ErrNotFound
+	}
+	return &students[0], nil
+}
+
+func (r *memoryStudentRepository) Update(ctx context.Context, student *models.Student) error {
+	return r.table.update(student.ID, func(stored *models.Student) error {
+		*stored = *student
+		return nil
+	})
+}
+
+func (r *memoryStudentRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.Student], error) {
+	return r.table.list(opts)
+}
@@ -0,0 +1,671 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/workflow"
+)
+
+func TestMemoryStudentsRejectDuplicateEmail(t *testing.T) {
+	ctx := context.Background()
+	students := NewMemoryStore().Students
+	first := &models.Student{Email: "a@example.com", Name: "A"}
+	if err := students.Create(ctx, first); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if first.ID.IsZero() {
+		t.Fatal("Create did not assign an ID")
+	}
+	if err := students.Create(ctx, &models.Student{Email: "a@example.com"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("second Create = %v, want ErrDuplicate", err)
+	}
+	found, err := students.FindByEmail(ctx, "a@example.com")
+	if err != nil || found.ID != first.ID {
+		t.Errorf("FindByEmail = %v, %v, want the first student", found, err)
+	}
+	if _, err := students.FindByEmail(ctx, "b@example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindByEmail(unknown) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryTableReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	students := NewMemoryStore().Students
+	student := &models.Student{Email: "a@example.com", Name: "A"}
+	if err := students.Create(ctx, student); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	student.Name = "changed"
+	found, _ := students.FindByID(ctx, student.ID)
+	found.Email = "changed"
+	again, _ := students.FindByID(ctx, student.ID)
+	if again.Name != "A" || again.Email != "a@example.com" {
+		t.Errorf("stored student changed without Update: %+v", again)
+	}
+}
+
+func TestMemoryCoursesUpdateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	courses := NewMemoryStore().Courses
+	course := &models.Course{Name: "Physics", Seats: 30}
+	if err := courses.Create(ctx, course); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	course.Seats = 40
+	course.SeatsFilled = 5
+	if err := courses.Update(ctx, course); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	found, _ := courses.FindByID(ctx, course.ID)
+	if found.Seats != 40 {
+		t.Errorf("Seats = %d after Update, want 40", found.Seats)
+	}
+	if found.SeatsFilled != 0 {
+		t.Errorf("Update changed SeatsFilled to %d; only seat reservations may", found.SeatsFilled)
+	}
+
+	if err := courses.Delete(ctx, course.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := courses.FindByID(ctx, course.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindByID after Delete = %v, want ErrNotFound", err)
+	}
+	if err := courses.Delete(ctx, course.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+	if err := courses.Update(ctx, course); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update after Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryAdmissionsTransition(t *testing.T) {
+	ctx := context.Background()
+	admissions := NewMemoryStore().Admissions
+	admission := &models.Admission{StudentID: primitive.NewObjectID(), CourseID: primitive.NewObjectID(), Status: workflow.StatusSubmitted}
+	if err := admissions.Create(ctx, admission); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	transitions := []struct {
+		from, to string
+		want     error
+	}{
+		{workflow.StatusSubmitted, workflow.StatusUnderReview, nil},
+		{workflow.StatusSubmitted, workflow.StatusRejected, ErrConflict},
+		{workflow.StatusUnderReview, workflow.StatusOffered, nil},
+	}
+	for _, tt := range transitions {
+		err := admissions.Transition(ctx, admission.ID, models.StatusChange{From: tt.from, To: tt.to, ChangedAt: now})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Transition %s -> %s = %v, want %v", tt.from, tt.to, err, tt.want)
+		}
+	}
+
+	stored, err := admissions.FindByID(ctx, admission.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if stored.Status != workflow.StatusOffered || len(stored.StatusHistory) != 2 {
+		t.Fatalf("stored admission = status %s with %d history entries, want offered with 2", stored.Status, len(stored.StatusHistory))
+	}
+	if last := stored.StatusHistory[1]; last.From != workflow.StatusUnderReview || last.To != workflow.StatusOffered || !last.ChangedAt.Equal(now) {
+		t.Errorf("last history entry = %+v", last)
+	}
+	if err := admissions.Transition(ctx, primitive.NewObjectID(), models.StatusChange{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Transition(unknown) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryAdmissionsRejectDuplicates(t *testing.T) {
+	ctx := context.Background()
+	admissions := NewMemoryStore().Admissions
+	studentID, courseID := primitive.NewObjectID(), primitive.NewObjectID()
+	if err := admissions.Create(ctx, &models.Admission{StudentID: studentID, CourseID: courseID, Cycle: "FALL27"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	duplicates := []struct {
+		name    string
+		student primitive.ObjectID
+		cycle   string
+		want    error
+	}{
+		{"same cycle", studentID, "FALL27", ErrDuplicate},
+		{"other cycle", studentID, "SPRING28", nil},
+		{"other student", primitive.NewObjectID(), "FALL27", nil},
+	}
+	for _, tt := range duplicates {
+		err := admissions.Create(ctx, &models.Admission{StudentID: tt.student, CourseID: courseID, Cycle: tt.cycle})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Create in %s = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestMemoryAdmissionsWaitlistedAt(t *testing.T) {
+	ctx := context.Background()
+	admissions := NewMemoryStore().Admissions
+	admission := &models.Admission{StudentID: primitive.NewObjectID(), CourseID: primitive.NewObjectID(), Status: workflow.StatusSubmitted}
+	if err := admissions.Create(ctx, admission); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	transitions := []struct {
+		from, to string
+		want     error
+	}{
+		{workflow.StatusSubmitted, workflow.StatusUnderReview, nil},
+		{workflow.StatusUnderReview, workflow.StatusWaitlisted, nil},
+		{workflow.StatusUnderReview, workflow.StatusOffered, ErrConflict},
+		{workflow.StatusWaitlisted, workflow.StatusOffered, nil},
+	}
+	for _, tt := range transitions {
+		err := admissions.Transition(ctx, admission.ID, models.StatusChange{From: tt.from, To: tt.to, ChangedAt: now})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Transition %s -> %s = %v, want %v", tt.from, tt.to, err, tt.want)
+		}
+		stored, _ := admissions.FindByID(ctx, admission.ID)
+		if tt.want == nil && (stored.WaitlistedAt != nil) != (tt.to == workflow.StatusWaitlisted) {
+			t.Errorf("after %s waitlistedAt = %v", tt.to, stored.WaitlistedAt)
+		}
+		if tt.to == workflow.StatusWaitlisted && stored.WaitlistedAt != nil && !stored.WaitlistedAt.Equal(now) {
+			t.Errorf("waitlistedAt = %v, want %v", stored.WaitlistedAt, now)
+		}
+	}
+}
+
+func TestMemoryAdmissionDrafts(t *testing.T) {
+	ctx := context.Background()
+	admissions := NewMemoryStore().Admissions
+	create := func() *models.Admission {
+		admission := &models.Admission{StudentID: primitive.NewObjectID(), CourseID: primitive.NewObjectID(), Cycle: "FALL27", Status: workflow.StatusDraft}
+		if err := admissions.Create(ctx, admission); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		return admission
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	admission := create()
+	admission.PersonalDetails.FirstName = "Draft"
+	if err := admissions.UpdateDraft(ctx, admission); err != nil {
+		t.Fatalf("UpdateDraft: %v", err)
+	}
+	submittedAt := now
+	admission.SubmittedAt = &submittedAt
+	admission.PersonalDetails.FirstName = "Test"
+	if err := admissions.UpdateDraft(ctx, admission); err != nil {
+		t.Fatalf("UpdateDraft with submittedAt: %v", err)
+	}
+	if err := admissions.UpdateDraft(ctx, admission); !errors.Is(err, ErrConflict) {
+		t.Errorf("UpdateDraft after submission = %v, want ErrConflict", err)
+	}
+	if err := admissions.Submit(ctx, admission, nil); !errors.Is(err, ErrConflict) {
+		t.Errorf("Submit after submission = %v, want ErrConflict", err)
+	}
+	stored, _ := admissions.FindByID(ctx, admission.ID)
+	if stored.Status != workflow.StatusDraft || stored.SubmittedAt == nil || stored.PersonalDetails.FirstName != "Test" {
+		t.Errorf("stored draft = status %s, submittedAt %v, first name %q", stored.Status, stored.SubmittedAt, stored.PersonalDetails.FirstName)
+	}
+
+	// A draft with nothing to pay is submitted in the same update
+	free := create()
+	free.SubmittedAt = &submittedAt
+	change := &models.StatusChange{From: workflow.StatusDraft, To: workflow.StatusSubmitted, ChangedAt: now}
+	if err := admissions.Submit(ctx, free, change); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	stored, _ = admissions.FindByID(ctx, free.ID)
+	if stored.Status != workflow.StatusSubmitted || stored.SubmittedAt == nil || len(stored.StatusHistory) != 1 {
+		t.Errorf("submitted admission = status %s, submittedAt %v, %d history entries", stored.Status, stored.SubmittedAt, len(stored.StatusHistory))
+	}
+	if err := admissions.Submit(ctx, free, change); !errors.Is(err, ErrConflict) {
+		t.Errorf("second Submit = %v, want ErrConflict", err)
+	}
+	if err := admissions.Submit(ctx, &models.Admission{ID: primitive.NewObjectID()}, change); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Submit(unknown) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryList(t *testing.T) {
+	ctx := context.Background()
+	courses := NewMemoryStore().Courses
+	for _, course := range []models.Course{
+		{Name: "Physics", Seats: 30},
+		{Name: "Computer Science", Seats: 60},
+		{Name: "Chemistry", Seats: 30},
+		{Name: "Mathematics", Seats: 45},
+	} {
+		course := course
+		if err := courses.Create(ctx, &course); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	names := func(page *query.Page[models.Course]) []string {
+		out := []string{}
+		for _, course := range page.Items {
+			out = append(out, course.Name)
+		}
+		return out
+	}
+	tests := []struct {
+		name  string
+		opts  query.Options
+		want  []string
+		total int64
+	}{
+		{
+			name:  "insertion order",
+			opts:  query.Options{},
+			want:  []string{"Physics", "Computer Science", "Chemistry", "Mathematics"},
+			total: 4,
+		},
+		{
+			name:  "sorted by two keys",
+			opts:  query.Options{Sort: []query.Sort{{Field: "seats", Desc: true}, {Field: "name"}}},
+			want:  []string{"Computer Science", "Mathematics", "Chemistry", "Physics"},
+			total: 4,
+		},
+		{
+			name:  "equality",
+			opts:  query.Options{Sort: []query.Sort{{Field: "name"}}}.Where("seats", query.OpEq, 30),
+			want:  []string{"Chemistry", "Physics"},
+			total: 2,
+		},
+		{
+			name:  "range",
+			opts:  query.Options{Sort: []query.Sort{{Field: "seats"}}}.Where("seats", query.OpGte, 40).Where("seats", query.OpLte, 50),
+			want:  []string{"Mathematics"},
+			total: 1,
+		},
+		{
+			name:  "in",
+			opts:  query.Options{}.Where("name", query.OpIn, []any{"Physics", "Mathematics", "Biology"}),
+			want:  []string{"Physics", "Mathematics"},
+			total: 2,
+		},
+		{
+			name:  "not equal",
+			opts:  query.Options{}.Where("seats", query.OpNe, 30),
+			want:  []string{"Computer Science", "Mathematics"},
+			total: 2,
+		},
+		{
+			name:  "search ignores case",
+			opts:  query.Options{Conditions: []query.Condition{{Fields: []string{"name", "description"}, Op: query.OpSearch, Value: "SCIENCE"}}},
+			want:  []string{"Computer Science"},
+			total: 1,
+		},
+		{
+			name:  "page",
+			opts:  query.Options{Sort: []query.Sort{{Field: "name"}}, Limit: 2, Offset: 1},
+			want:  []string{"Computer Science", "Mathematics"},
+			total: 4,
+		},
+		{
+			name:  "offset past the end",
+			opts:  query.Options{Offset: 10},
+			want:  []string{},
+			total: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := courses.List(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			got := names(page)
+			if len(got) != len(tt.want) || page.Total != tt.total {
+				t.Fatalf("List() = %v (total %d), want %v (total %d)", got, page.Total, tt.want, tt.total)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("List() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryCourseSeats(t *testing.T) {
+	ctx := context.Background()
+	courses := NewMemoryStore().Courses
+	course := &models.Course{Name: "Physics", Seats: 2}
+	if err := courses.Create(ctx, course); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- courses.ReserveSeat(ctx, course.ID)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	reserved, full := 0, 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			reserved++
+		case errors.Is(err, ErrNoSeats):
+			full++
+		default:
+			t.Fatalf("ReserveSeat: %v", err)
+		}
+	}
+	if reserved != 2 || full != 3 {
+		t.Errorf("reserved %d and refused %d seats, want 2 and 3", reserved, full)
+	}
+
+	if err := courses.ReleaseSeat(ctx, course.ID); err != nil {
+		t.Fatalf("ReleaseSeat: %v", err)
+	}
+	if err := courses.ReserveSeat(ctx, course.ID); err != nil {
+		t.Errorf("ReserveSeat after a release = %v", err)
+	}
+	if err := courses.ReserveSeat(ctx, primitive.NewObjectID()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ReserveSeat(unknown) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryCycleSeats(t *testing.T) {
+	ctx := context.Background()
+	seats := NewMemoryStore().CycleSeats
+	courseID := primitive.NewObjectID()
+	for i := 0; i < 2; i++ {
+		if err := seats.Reserve(ctx, "FALL27", courseID, "sc", 2); err != nil {
+			t.Fatalf("Reserve: %v", err)
+		}
+	}
+	if err := seats.Reserve(ctx, "FALL27", courseID, "sc", 2); !errors.Is(err, ErrNoSeats) {
+		t.Errorf("Reserve past the limit = %v, want ErrNoSeats", err)
+	}
+	if err := seats.Reserve(ctx, "SPRING28", courseID, "sc", 2); err != nil {
+		t.Errorf("Reserve in another cycle = %v", err)
+	}
+	seats.Release(ctx, "FALL27", courseID, "sc")
+	filled, err := seats.Filled(ctx, "FALL27", courseID)
+	if err != nil || len(filled) != 1 || filled["sc"] != 1 {
+		t.Errorf("Filled = %v, %v, want sc: 1", filled, err)
+	}
+}
+
+func TestMemorySessionRotate(t *testing.T) {
+	ctx := context.Background()
+	sessions := NewMemoryStore().Sessions
+	session := &models.Session{UserID: primitive.NewObjectID(), RefreshTokenHash: "h1", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := sessions.Create(ctx, session); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	now := time.Now()
+	if err := sessions.Rotate(ctx, session.ID, "h1", "h2", now); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if err := sessions.Rotate(ctx, session.ID, "h1", "h3", now); !errors.Is(err, ErrConflict) {
+		t.Errorf("Rotate with a spent hash = %v, want ErrConflict", err)
+	}
+	stored, _ := sessions.FindByID(ctx, session.ID)
+	if stored.RefreshTokenHash != "h2" || len(stored.PreviousTokenHashes) != 1 || stored.PreviousTokenHashes[0] != "h1" {
+		t.Errorf("after Rotate hash %q, previous %v", stored.RefreshTokenHash, stored.PreviousTokenHashes)
+	}
+
+	other := &models.Session{UserID: session.UserID, ExpiresAt: time.Now().Add(time.Hour)}
+	sessions.Create(ctx, other)
+	if err := sessions.RevokeAllForUser(ctx, session.UserID, "logout_all"); err != nil {
+		t.Fatalf("RevokeAllForUser: %v", err)
+	}
+	for _, id := range []primitive.ObjectID{session.ID, other.ID} {
+		stored, _ := sessions.FindByID(ctx, id)
+		if stored.Active(time.Now()) || stored.RevokedReason != "logout_all" {
+			t.Errorf("session %s still active after RevokeAllForUser", id.Hex())
+		}
+	}
+	if err := sessions.Rotate(ctx, session.ID, "h2", "h3", now); !errors.Is(err, ErrConflict) {
+		t.Errorf("Rotate on a revoked session = %v, want ErrConflict", err)
+	}
+}
+
+func TestMemorySessionKeepsRecentHashes(t *testing.T) {
+	ctx := context.Background()
+	sessions := NewMemoryStore().Sessions
+	session := &models.Session{UserID: primitive.NewObjectID(), RefreshTokenHash: "h0", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := sessions.Create(ctx, session); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	rotations := MaxPreviousTokenHashes + 5
+	for i := 0; i < rotations; i++ {
+		if err := sessions.Rotate(ctx, session.ID, fmt.Sprintf("h%d", i), fmt.Sprintf("h%d", i+1), time.Now()); err != nil {
+			t.Fatalf("Rotate %d: %v", i, err)
+		}
+	}
+	stored, _ := sessions.FindByID(ctx, session.ID)
+	previous := stored.PreviousTokenHashes
+	if len(previous) != MaxPreviousTokenHashes {
+		t.Fatalf("session remembers %d hashes, want %d", len(previous), MaxPreviousTokenHashes)
+	}
+	if oldest, newest := previous[0], previous[len(previous)-1]; oldest != "h5" || newest != fmt.Sprintf("h%d", rotations-1) {
+		t.Errorf("remembered hashes run from %s to %s", oldest, newest)
+	}
+}
+
+func TestMemoryTokensConsumeOnce(t *testing.T) {
+	ctx := context.Background()
+	tokens := NewMemoryStore().Tokens
+	userID := primitive.NewObjectID()
+	now := time.Now()
+	tokens.Create(ctx, &models.ActionToken{UserID: userID, Purpose: "reset", TokenHash: "live", ExpiresAt: now.Add(time.Hour)})
+	tokens.Create(ctx, &models.ActionToken{UserID: userID, Purpose: "reset", TokenHash: "old", ExpiresAt: now.Add(-time.Minute)})
+	tokens.Create(ctx, &models.ActionToken{UserID: userID, Purpose: "reset", TokenHash: "other", ExpiresAt: now.Add(time.Hour)})
+
+	tests := []struct {
+		name, purpose, hash string
+		want                error
+	}{
+		{"valid", "reset", "live", nil},
+		{"used", "reset", "live", ErrNotFound},
+		{"expired", "reset", "old", ErrNotFound},
+		{"wrong purpose", "verify", "other", ErrNotFound},
+	}
+	for _, tt := range tests {
+		token, err := tokens.Consume(ctx, tt.purpose, tt.hash, now)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Consume %s = %v, want %v", tt.name, err, tt.want)
+		}
+		if err == nil && token.UsedAt == nil {
+			t.Errorf("Consume %s did not mark the token used", tt.name)
+		}
+	}
+
+	if err := tokens.InvalidateForUser(ctx, userID, "reset"); err != nil {
+		t.Fatalf("InvalidateForUser: %v", err)
+	}
+	if _, err := tokens.Consume(ctx, "reset", "other", now); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Consume after InvalidateForUser = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryInvites(t *testing.T) {
+	ctx := context.Background()
+	invites := NewMemoryStore().Invites
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	newInvite := func(expiresAt time.Time) *models.Invite {
+		invite := &models.Invite{Email: "staff@example.com", Role: "reviewer", ExpiresAt: expiresAt, CreatedAt: now}
+		if err := invites.Create(ctx, invite); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		return invite
+	}
+	userID := primitive.NewObjectID()
+
+	invite := newInvite(now.Add(time.Hour))
+	if err := invites.Accept(ctx, invite.ID, userID, now); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if err := invites.Accept(ctx, invite.ID, userID, now); !errors.Is(err, ErrConflict) {
+		t.Errorf("second Accept = %v, want ErrConflict", err)
+	}
+	if err := invites.Revoke(ctx, invite.ID, now); !errors.Is(err, ErrConflict) {
+		t.Errorf("Revoke after Accept = %v, want ErrConflict", err)
+	}
+	if err := invites.Reopen(ctx, invite.ID); err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	stored, _ := invites.FindByID(ctx, invite.ID)
+	if got := stored.CurrentStatus(now); got != models.InvitePending {
+		t.Errorf("status after Reopen = %s, want pending", got)
+	}
+
+	if err := invites.Revoke(ctx, invite.ID, now); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if err := invites.Accept(ctx, invite.ID, userID, now); !errors.Is(err, ErrConflict) {
+		t.Errorf("Accept after Revoke = %v, want ErrConflict", err)
+	}
+
+	expired := newInvite(now.Add(-time.Minute))
+	if err := invites.Accept(ctx, expired.ID, userID, now); !errors.Is(err, ErrConflict) {
+		t.Errorf("Accept of an expired invite = %v, want ErrConflict", err)
+	}
+}
+
+func TestMemoryPayments(t *testing.T) {
+	ctx := context.Background()
+	payments := NewMemoryStore().Payments
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	admissionID := primitive.NewObjectID()
+	payment := &models.Payment{AdmissionID: admissionID, Purpose: models.PaymentPurposeTuition, Amount: 1000, Status: models.PaymentPending, IntentID: "pi_1"}
+	if err := payments.Create(ctx, payment); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if found, err := payments.FindByIntent(ctx, "pi_1"); err != nil || found.ID != payment.ID {
+		t.Errorf("FindByIntent = %v, %v", found, err)
+	}
+
+	refund := func(key string, amount int64) error {
+		return payments.AddRefund(ctx, payment.ID, models.Refund{Key: key, Amount: amount, CreatedAt: now})
+	}
+	if err := refund("early", 100); !errors.Is(err, ErrConflict) {
+		t.Errorf("refund before capture = %v, want ErrConflict", err)
+	}
+	if err := payments.Capture(ctx, payment.ID, models.Receipt{Number: "R1", IssuedAt: now}); err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if err := payments.Capture(ctx, payment.ID, models.Receipt{Number: "R2", IssuedAt: now}); !errors.Is(err, ErrConflict) {
+		t.Errorf("second Capture = %v, want ErrConflict", err)
+	}
+	if err := payments.Fail(ctx, payment.ID, "late callback", now); !errors.Is(err, ErrConflict) {
+		t.Errorf("Fail after Capture = %v, want ErrConflict", err)
+	}
+
+	second := &models.Payment{AdmissionID: admissionID, Purpose: models.PaymentPurposeTuition, Amount: 1000, Status: models.PaymentPending, IntentID: "pi_2"}
+	if err := payments.Create(ctx, second); err != nil {
+		t.Fatalf("Create second: %v", err)
+	}
+	if err := payments.Capture(ctx, second.ID, models.Receipt{Number: "R3", IssuedAt: now}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Capture of a second payment for the fee = %v, want ErrDuplicate", err)
+	}
+
+	tests := []struct {
+		action string
+		key    string
+		amount int64
+		want   error
+		status string
+	}{
+		{"add", "r1", 400, nil, models.PaymentCaptured},
+		{"add", "r1", 400, ErrDuplicate, models.PaymentCaptured},
+		{"add", "r2", 700, ErrConflict, models.PaymentCaptured},
+		{"add", "r2", 600, nil, models.PaymentCaptured},
+		{"add", "r3", 1, ErrConflict, models.PaymentCaptured},
+		{"cancel", "r2", 0, nil, models.PaymentCaptured},
+		{"cancel", "r2", 0, ErrConflict, models.PaymentCaptured},
+		{"settle", "r1", 0, nil, models.PaymentCaptured},
+		{"settle", "r1", 0, ErrConflict, models.PaymentCaptured},
+		{"cancel", "r1", 0, ErrConflict, models.PaymentCaptured},
+		{"add", "r2", 600, nil, models.PaymentCaptured},
+		{"settle", "r2", 0, nil, models.PaymentRefunded},
+		{"add", "r2", 1, ErrDuplicate, models.PaymentRefunded},
+	}
+	for i, tt := range tests {
+		var err error
+		switch tt.action {
+		case "add":
+			err = refund(tt.key, tt.amount)
+		case "settle":
+			err = payments.SettleRefund(ctx, payment.ID, tt.key, "re_"+tt.key, now)
+		case "cancel":
+			err = payments.CancelRefund(ctx, payment.ID, tt.key)
+		}
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s %d (%s, %d) = %v, want %v", tt.action, i, tt.key, tt.amount, err, tt.want)
+		}
+		stored, _ := payments.FindByID(ctx, payment.ID)
+		if stored.Status != tt.status {
+			t.Errorf("after %s %d status = %s, want %s", tt.action, i, stored.Status, tt.status)
+		}
+	}
+
+	stored, _ := payments.FindByID(ctx, payment.ID)
+	if stored.Refunded != 1000 || len(stored.Refunds) != 2 {
+		t.Fatalf("refunded = %d in %d refunds, want 1000 in 2", stored.Refunded, len(stored.Refunds))
+	}
+	for _, r := range stored.Refunds {
+		if r.Status != models.RefundSucceeded || r.GatewayRef != "re_"+r.Key {
+			t.Errorf("refund %s = %s with ref %q, want succeeded with re_%s", r.Key, r.Status, r.GatewayRef, r.Key)
+		}
+	}
+}
+
+func TestMemoryIdempotencyKeys(t *testing.T) {
+	ctx := context.Background()
+	keys := NewMemoryStore().IdempotencyKeys
+	record := func(user, key string, expiresAt time.Time) *models.IdempotencyRecord {
+		return &models.IdempotencyRecord{UserID: user, Key: key, RequestHash: "h", ExpiresAt: expiresAt}
+	}
+	later := time.Now().Add(time.Hour)
+
+	first, err := keys.Reserve(ctx, record("u1", "k", later))
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if err := keys.Complete(ctx, first.ID, 201, "application/json", []byte(`{}`)); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	existing, err := keys.Reserve(ctx, record("u1", "k", later))
+	if !errors.Is(err, ErrDuplicate) || existing.StatusCode != 201 || string(existing.Body) != `{}` {
+		t.Errorf("Reserve of a used key = %+v, %v, want the stored response and ErrDuplicate", existing, err)
+	}
+	if _, err := keys.Reserve(ctx, record("u2", "k", later)); err != nil {
+		t.Errorf("Reserve of the key by another user = %v", err)
+	}
+
+	expired, _ := keys.Reserve(ctx, record("u1", "old", time.Now().Add(-time.Minute)))
+	if again, err := keys.Reserve(ctx, record("u1", "old", later)); err != nil || again.ID == expired.ID {
+		t.Errorf("Reserve of an expired key = %v, %v, want a new reservation", again, err)
+	}
+
+	released, _ := keys.Reserve(ctx, record("u1", "retry", later))
+	keys.Release(ctx, released.ID)
+	if _, err := keys.Reserve(ctx, record("u1", "retry", later)); err != nil {
+		t.Errorf("Reserve after Release = %v", err)
+	}
+}
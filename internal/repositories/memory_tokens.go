@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+)
+
+type memoryActionTokenRepository struct {
+	table *memoryTable[models.ActionToken]
+}
+
+func (r *memoryActionTokenRepository) Create(ctx context.Context, token *models.ActionToken) error {
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+	return r.table.insert(token.ID, token)
+}
+
+func (r *memoryActionTokenRepository) Consume(ctx context.Context, purpose, tokenHash string, now time.Time) (*models.ActionToken, error) {
+	usable := func(t *models.ActionToken) bool {
+		return t.Purpose == purpose && t.TokenHash == tokenHash && t.UsedAt == nil && now.Before(t.ExpiresAt)
+	}
+	tokens, err := r.table.find(usable)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var consumed models.ActionToken
+	err = r.table.update(tokens[0].ID, func(t *models.ActionToken) error {
+		// Re-check under the write lock in case another caller got there first
+		if !usable(t) {
+			return ErrNotFound
+		}
+		t.UsedAt = &now
+		consumed = *t
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &consumed, nil
+}
+
+func (r *memoryActionTokenRepository) InvalidateForUser(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	tokens, err := r.table.find(func(t *models.ActionToken) bool {
+		return t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil
+	})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, token := range tokens {
+		err := r.table.update(token.ID, func(t *models.ActionToken) error {
+			if t.UsedAt == nil {
+				t.UsedAt = &now
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// NewMongoStore returns repositories backed by the given MongoDB database.
+func NewMongoStore(db *mongo.Database) *Store {
+	return &Store{
+		Students:   &mongoStudentRepository{collection: db.Collection("students")},
+		Courses:    &mongoCourseRepository{collection: db.Collection("courses")},
+		Admissions: &mongoAdmissionRepository{collection: db.Collection("admissions")},
+		Sessions:   &mongoSessionRepository{collection: db.Collection("sessions")},
+		Tokens:     &mongoActionTokenRepository{collection: db.Collection("action_tokens")},
+		Invites:    &mongoInviteRepository{collection: db.Collection("invites")},
+		Documents:  &mongoDocumentRepository{collection: db.Collection("documents")},
+		Audit:      &mongoAuditRepository{collection: db.Collection("audit_events")},
+		AuditQueue: &mongoAuditQueueRepository{collection: db.Collection("audit_queue")},
+		MeritLists: &mongoMeritListRepository{collection: db.Collection("merit_lists")},
+		Cycles:     &mongoCycleRepository{collection: db.Collection("admission_cycles")},
+		CycleSeats: &mongoCycleSeatRepository{collection: db.Collection("cycle_seats")},
+		Payments:   &mongoPaymentRepository{collection: db.Collection("payments")},
+
+		IdempotencyKeys: &mongoIdempotencyRepository{collection: db.Collection("idempotency_keys")},
+	}
+}
+
+// mongoError translates driver errors into the package's sentinel errors.
+func mongoError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return ErrNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return ErrDuplicate
+	default:
+		return err
+	}
+}
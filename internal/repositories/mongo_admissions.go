@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/workflow"
+)
+
+type mongoAdmissionRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoAdmissionRepository) Create(ctx context.Context, admission *models.Admission) error {
+	result, err := r.collection.InsertOne(ctx, admission)
+	if err != nil {
+		return mongoError(err)
+	}
+	admission.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoAdmissionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Admission, error) {
+	var admission models.Admission
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&admission); err != nil {
+		return nil, mongoError(err)
+	}
+	return &admission, nil
+}
+
+func (r *mongoAdmissionRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.Admission], error) {
+	return mongoList[models.Admission](ctx, r.collection, opts)
+}
+
+func (r *mongoAdmissionRepository) Transition(ctx context.Context, id primitive.ObjectID, change models.StatusChange) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":         change.To,
+			"comments":       change.Comments,
+			"seatQuota":      change.SeatQuota,
+			"offerExpiresAt": change.OfferExpiresAt,
+			"waitlistedAt":   waitlistedAt(change),
+			"updatedAt":      change.ChangedAt,
+		},
+		"$push": bson.M{"statusHistory": change},
+	}
+	return r.conditionalUpdate(ctx, id, change.From, update)
+}
+
+func (r *mongoAdmissionRepository) Allocate(ctx context.Context, id primitive.ObjectID, courseID primitive.ObjectID, preferences []models.Preference, change models.StatusChange) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":         change.To,
+			"comments":       change.Comments,
+			"courseId":       courseID,
+			"seatQuota":      change.SeatQuota,
+			"offerExpiresAt": change.OfferExpiresAt,
+			"waitlistedAt":   waitlistedAt(change),
+			"preferences":    preferences,
+			"updatedAt":      change.ChangedAt,
+		},
+		"$push": bson.M{"statusHistory": change},
+	}
+	return r.conditionalUpdate(ctx, id, change.From, update)
+}
+
+func (r *mongoAdmissionRepository) UpdateDraft(ctx context.Context, admission *models.Admission) error {
+	return r.saveDraft(ctx, admission, nil)
+}
+
+func (r *mongoAdmissionRepository) Submit(ctx context.Context, admission *models.Admission, change *models.StatusChange) error {
+	return r.saveDraft(ctx, admission, change)
+}
+
+// saveDraft stores the draft's contents and, when change is not nil, moves
+// it on, provided it is still an unsubmitted draft.
+func (r *mongoAdmissionRepository) saveDraft(ctx context.Context, admission *models.Admission, change *models.StatusChange) error {
+	set := bson.M{
+		"courseId":        admission.CourseID,
+		"personalDetails": admission.PersonalDetails,
+		"academicDetails": admission.AcademicDetails,
+		"documents":       admission.Documents,
+		"eligibility":     admission.Eligibility,
+		"preferences":     admission.Preferences,
+		"category":        admission.Category,
+		"cycle":           admission.Cycle,
+		"applicationFee":  admission.ApplicationFee,
+		"lateFee":         admission.LateFee,
+		"late":            admission.Late,
+		"updatedAt":       admission.UpdatedAt,
+	}
+	if admission.SubmittedAt != nil {
+		set["submittedAt"] = admission.SubmittedAt
+	}
+	update := bson.M{"$set": set}
+	if change != nil {
+		set["status"] = change.To
+		set["comments"] = change.Comments
+		set["updatedAt"] = change.ChangedAt
+		update["$push"] = bson.M{"statusHistory": change}
+	}
+	filter := bson.M{"_id": admission.ID, "status": workflow.StatusDraft, "submittedAt": bson.M{"$exists": false}}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, admission.ID); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+// conditionalUpdate applies update only while the admission is still in
+// status from, returning ErrConflict otherwise.
+func (r *mongoAdmissionRepository) conditionalUpdate(ctx context.Context, id primitive.ObjectID, from string, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": from}, update)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type mongoAuditRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoAuditRepository) Append(ctx context.Context, event *models.AuditEvent) error {
+	_, err := r.collection.InsertOne(ctx, event)
+	return mongoError(err)
+}
+
+func (r *mongoAuditRepository) Last(ctx context.Context) (*models.AuditEvent, error) {
+	var event models.AuditEvent
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+	if err := r.collection.FindOne(ctx, bson.M{}, findOptions).Decode(&event); err != nil {
+		return nil, mongoError(err)
+	}
+	return &event, nil
+}
+
+func (r *mongoAuditRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.AuditEvent], error) {
+	return mongoList[models.AuditEvent](ctx, r.collection, opts)
+}
+
+type mongoAuditQueueRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoAuditQueueRepository) Add(ctx context.Context, pending *models.PendingAuditEvent) error {
+	if pending.ID.IsZero() {
+		pending.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, pending)
+	return mongoError(err)
+}
+
+func (r *mongoAuditQueueRepository) Oldest(ctx context.Context, limit int) ([]models.PendingAuditEvent, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	pending := []models.PendingAuditEvent{}
+	if err := cursor.All(ctx, &pending); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+func (r *mongoAuditQueueRepository) Remove(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
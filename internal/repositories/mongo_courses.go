@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type mongoCourseRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoCourseRepository) Create(ctx context.Context, course *models.Course) error {
+	result, err := r.collection.InsertOne(ctx, course)
+	if err != nil {
+		return mongoError(err)
+	}
+	course.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoCourseRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Course, error) {
+	var course models.Course
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&course); err != nil {
+		return nil, mongoError(err)
+	}
+	return &course, nil
+}
+
+func (r *mongoCourseRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.Course], error) {
+	return mongoList[models.Course](ctx, r.collection, opts)
+}
+
+func (r *mongoCourseRepository) Update(ctx context.Context, course *models.Course) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":                course.Name,
+			"description":         course.Description,
+			"duration":            course.Duration,
+			"seats":               course.Seats,
+			"eligibilityCriteria": course.EligibilityCriteria,
+			"fees":                course.Fees,
+			"updated_at":          course.UpdatedAt,
+		},
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": course.ID}, update)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoCourseRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoCourseRepository) ReserveSeat(ctx context.Context, id primitive.ObjectID) error {
+	// The filter only matches while a seat is free, so concurrent reservations
+	// cannot push seatsFilled past seats.
+	filter := bson.M{
+		"_id":   id,
+		"$expr": bson.M{"$lt": bson.A{bson.M{"$ifNull": bson.A{"$seatsFilled", 0}}, "$seats"}},
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"seatsFilled": 1}})
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrNoSeats
+	}
+	return nil
+}
+
+func (r *mongoCourseRepository) ReleaseSeat(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id, "seatsFilled": bson.M{"$gt": 0}}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"seatsFilled": -1}})
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
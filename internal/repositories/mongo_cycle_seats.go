@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoCycleSeatRepository keeps one counter document per cycle, course and
+// quota. The document ID is derived from all three, so the first reservation
+// can create the counter with an upsert without two writers creating one
+// each.
+type mongoCycleSeatRepository struct {
+	collection *mongo.Collection
+}
+
+type cycleSeatCounter struct {
+	Quota  string `bson:"quota"`
+	Filled int    `bson:"filled"`
+}
+
+func cycleSeatKey(cycle string, courseID primitive.ObjectID, quota string) string {
+	return cycle + ":" + courseID.Hex() + ":" + quota
+}
+
+func (r *mongoCycleSeatRepository) Reserve(ctx context.Context, cycle string, courseID primitive.ObjectID, quota string, limit int) error {
+	if limit <= 0 {
+		return ErrNoSeats
+	}
+	// When the counter is full the filter does not match and the upsert tries
+	// to insert a second document with the same ID, which fails as a duplicate.
+	filter := bson.M{"_id": cycleSeatKey(cycle, courseID, quota), "filled": bson.M{"$lt": limit}}
+	update := bson.M{
+		"$inc":         bson.M{"filled": 1},
+		"$setOnInsert": bson.M{"cycle": cycle, "courseId": courseID, "quota": quota},
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrNoSeats
+	}
+	return err
+}
+
+func (r *mongoCycleSeatRepository) Release(ctx context.Context, cycle string, courseID primitive.ObjectID, quota string) error {
+	filter := bson.M{"_id": cycleSeatKey(cycle, courseID, quota), "filled": bson.M{"$gt": 0}}
+	_, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"filled": -1}})
+	return err
+}
+
+func (r *mongoCycleSeatRepository) Filled(ctx context.Context, cycle string, courseID primitive.ObjectID) (map[string]int, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"cycle": cycle, "courseId": courseID})
+	if err != nil {
+		return nil, err
+	}
+	var counters []cycleSeatCounter
+	if err := cursor.All(ctx, &counters); err != nil {
+		return nil, err
+	}
+
+	filled := map[string]int{}
+	for _, counter := range counters {
+		filled[counter.Quota] = counter.Filled
+	}
+	return filled, nil
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type mongoCycleRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoCycleRepository) Create(ctx context.Context, cycle *models.AdmissionCycle) error {
+	// The unique code index turns a clash into ErrDuplicate
+	result, err := r.collection.InsertOne(ctx, cycle)
+	if err != nil {
+		return mongoError(err)
+	}
+	cycle.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoCycleRepository) FindByCode(ctx context.Context, code string) (*models.AdmissionCycle, error) {
+	var cycle models.AdmissionCycle
+	if err := r.collection.FindOne(ctx, bson.M{"code": code}).Decode(&cycle); err != nil {
+		return nil, mongoError(err)
+	}
+	return &cycle, nil
+}
+
+func (r *mongoCycleRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.AdmissionCycle], error) {
+	return mongoList[models.AdmissionCycle](ctx, r.collection, opts)
+}
+
+func (r *mongoCycleRepository) Update(ctx context.Context, cycle *models.AdmissionCycle) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":           cycle.Name,
+			"opensAt":        cycle.OpensAt,
+			"closesAt":       cycle.ClosesAt,
+			"lateSubmission": cycle.LateSubmission,
+			"courses":        cycle.Courses,
+			"conversions":    cycle.Conversions,
+			"updatedAt":      cycle.UpdatedAt,
+		},
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": cycle.ID}, update)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
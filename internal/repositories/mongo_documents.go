@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"admission-portal-backend/internal/models"
+)
+
+type mongoDocumentRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoDocumentRepository) Create(ctx context.Context, document *models.Document) error {
+	result, err := r.collection.InsertOne(ctx, document)
+	if err != nil {
+		return mongoError(err)
+	}
+	document.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoDocumentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Document, error) {
+	var document models.Document
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&document); err != nil {
+		return nil, mongoError(err)
+	}
+	return &document, nil
+}
+
+func (r *mongoDocumentRepository) ListByOwner(ctx context.Context, ownerID primitive.ObjectID) ([]models.Document, error) {
+	return r.find(ctx, bson.M{"ownerId": ownerID})
+}
+
+func (r *mongoDocumentRepository) ListByAdmission(ctx context.Context, admissionID primitive.ObjectID) ([]models.Document, error) {
+	return r.find(ctx, bson.M{"admissionId": admissionID})
+}
+
+func (r *mongoDocumentRepository) find(ctx context.Context, filter bson.M) ([]models.Document, error) {
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	documents := []models.Document{}
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+func (r *mongoDocumentRepository) AttachToAdmission(ctx context.Context, id, ownerID, admissionID primitive.ObjectID) error {
+	filter := bson.M{
+		"_id":     id,
+		"ownerId": ownerID,
+		"$or": bson.A{
+			bson.M{"admissionId": nil},
+			bson.M{"admissionId": admissionID},
+		},
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"admissionId": admissionID}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		document, err := r.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if document.OwnerID != ownerID {
+			return ErrNotFound
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoDocumentRepository) DetachFromAdmission(ctx context.Context, id, admissionID primitive.ObjectID) error {
+	filter := bson.M{"_id": id, "admissionId": admissionID}
+	_, err := r.collection.UpdateOne(ctx, filter, bson.M{"$unset": bson.M{"admissionId": ""}})
+	return err
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"admission-portal-backend/internal/models"
+)
+
+type mongoIdempotencyRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoIdempotencyRepository) Reserve(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	// One retry covers a stale record the TTL monitor has not removed yet
+	for attempt := 0; attempt < 2; attempt++ {
+		result, err := r.collection.InsertOne(ctx, record)
+		if err == nil {
+			record.ID = result.InsertedID.(primitive.ObjectID)
+			return record, nil
+		}
+		if !errors.Is(mongoError(err), ErrDuplicate) {
+			return nil, err
+		}
+
+		var existing models.IdempotencyRecord
+		filter := bson.M{"userId": record.UserID, "key": record.Key}
+		if err := r.collection.FindOne(ctx, filter).Decode(&existing); err != nil {
+			if errors.Is(mongoError(err), ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if existing.ExpiresAt.After(time.Now()) {
+			return &existing, ErrDuplicate
+		}
+		if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": existing.ID, "expiresAt": existing.ExpiresAt}); err != nil {
+			return nil, err
+		}
+	}
+	return nil, ErrConflict
+}
+
+func (r *mongoIdempotencyRepository) Complete(ctx context.Context, id primitive.ObjectID, statusCode int, contentType string, body []byte) error {
+	update := bson.M{"$set": bson.M{"statusCode": statusCode, "contentType": contentType, "body": body}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoIdempotencyRepository) Release(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type mongoInviteRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoInviteRepository) Create(ctx context.Context, invite *models.Invite) error {
+	result, err := r.collection.InsertOne(ctx, invite)
+	if err != nil {
+		return mongoError(err)
+	}
+	invite.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoInviteRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Invite, error) {
+	var invite models.Invite
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&invite); err != nil {
+		return nil, mongoError(err)
+	}
+	return &invite, nil
+}
+
+func (r *mongoInviteRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.Invite], error) {
+	return mongoList[models.Invite](ctx, r.collection, opts)
+}
+
+// pending matches the invite with id if it can still be used at now.
+func (r *mongoInviteRepository) pending(id primitive.ObjectID, now time.Time) bson.M {
+	return bson.M{"_id": id, "acceptedAt": nil, "revokedAt": nil, "expiresAt": bson.M{"$gt": now}}
+}
+
+func (r *mongoInviteRepository) Accept(ctx context.Context, id, userID primitive.ObjectID, now time.Time) error {
+	update := bson.M{"$set": bson.M{"acceptedAt": now, "userId": userID}}
+	return r.conditionalUpdate(ctx, id, r.pending(id, now), update)
+}
+
+func (r *mongoInviteRepository) Reopen(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$unset": bson.M{"acceptedAt": "", "userId": ""}})
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoInviteRepository) Revoke(ctx context.Context, id primitive.ObjectID, now time.Time) error {
+	return r.conditionalUpdate(ctx, id, r.pending(id, now), bson.M{"$set": bson.M{"revokedAt": now}})
+}
+
+// conditionalUpdate applies update if filter matches, telling a missing
+// invite (ErrNotFound) apart from one that is no longer pending
+// (ErrConflict).
+func (r *mongoInviteRepository) conditionalUpdate(ctx context.Context, id primitive.ObjectID, filter, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
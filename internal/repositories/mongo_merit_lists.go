@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type mongoMeritListRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoMeritListRepository) Create(ctx context.Context, list *models.MeritList) error {
+	// The unique course_cycle_version index turns a clash into ErrDuplicate
+	result, err := r.collection.InsertOne(ctx, list)
+	if err != nil {
+		return mongoError(err)
+	}
+	list.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoMeritListRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.MeritList, error) {
+	var list models.MeritList
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&list); err != nil {
+		return nil, mongoError(err)
+	}
+	return &list, nil
+}
+
+func (r *mongoMeritListRepository) Latest(ctx context.Context, courseID primitive.ObjectID, cycle string) (*models.MeritList, error) {
+	var list models.MeritList
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+	err := r.collection.FindOne(ctx, bson.M{"courseId": courseID, "cycle": cycle}, findOptions).Decode(&list)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+	return &list, nil
+}
+
+func (r *mongoMeritListRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.MeritList], error) {
+	return mongoList[models.MeritList](ctx, r.collection, opts)
+}
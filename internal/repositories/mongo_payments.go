@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type mongoPaymentRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoPaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	result, err := r.collection.InsertOne(ctx, payment)
+	if err != nil {
+		return mongoError(err)
+	}
+	payment.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoPaymentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Payment, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoPaymentRepository) FindByIntent(ctx context.Context, intentID string) (*models.Payment, error) {
+	return r.findOne(ctx, bson.M{"intentId": intentID})
+}
+
+func (r *mongoPaymentRepository) findOne(ctx context.Context, filter bson.M) (*models.Payment, error) {
+	var payment models.Payment
+	if err := r.collection.FindOne(ctx, filter).Decode(&payment); err != nil {
+		return nil, mongoError(err)
+	}
+	return &payment, nil
+}
+
+func (r *mongoPaymentRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.Payment], error) {
+	return mongoList[models.Payment](ctx, r.collection, opts)
+}
+
+func (r *mongoPaymentRepository) Capture(ctx context.Context, id primitive.ObjectID, receipt models.Receipt) error {
+	update := bson.M{"$set": bson.M{
+		"status":     models.PaymentCaptured,
+		"receipt":    receipt,
+		"capturedAt": receipt.IssuedAt,
+		"updatedAt":  receipt.IssuedAt,
+	}}
+	return r.settle(ctx, id, update)
+}
+
+func (r *mongoPaymentRepository) Fail(ctx context.Context, id primitive.ObjectID, reason string, at time.Time) error {
+	update := bson.M{"$set": bson.M{
+		"status":        models.PaymentFailed,
+		"failureReason": reason,
+		"updatedAt":     at,
+	}}
+	return r.settle(ctx, id, update)
+}
+
+// settle applies update only while the payment is still pending. The
+// unique admission_purpose_captured index turns a second capture of the
+// same fee into ErrDuplicate.
+func (r *mongoPaymentRepository) settle(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": models.PaymentPending}, update)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoPaymentRepository) AddRefund(ctx context.Context, id primitive.ObjectID, refund models.Refund) error {
+	refund.Status = models.RefundPending
+	filter := bson.M{
+		"_id":         id,
+		"status":      models.PaymentCaptured,
+		"refunds.key": bson.M{"$ne": refund.Key},
+		"$expr":       bson.M{"$lte": bson.A{bson.M{"$add": bson.A{"$refunded", refund.Amount}}, "$amount"}},
+	}
+	update := bson.M{
+		"$inc":  bson.M{"refunded": refund.Amount},
+		"$push": bson.M{"refunds": refund},
+		"$set":  bson.M{"updatedAt": refund.CreatedAt},
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		payment, err := r.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, existing := range payment.Refunds {
+			if existing.Key == refund.Key {
+				return ErrDuplicate
+			}
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoPaymentRepository) SettleRefund(ctx context.Context, id primitive.ObjectID, key, gatewayRef string, at time.Time) error {
+	filter := bson.M{
+		"_id":     id,
+		"refunds": bson.M{"$elemMatch": bson.M{"key": key, "status": models.RefundPending}},
+	}
+	update := bson.M{"$set": bson.M{
+		"refunds.$.status":     models.RefundSucceeded,
+		"refunds.$.gatewayRef": gatewayRef,
+		"updatedAt":            at,
+	}}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+
+	// Close the payment once all of it has been paid back
+	_, err = r.collection.UpdateOne(ctx, bson.M{
+		"_id":    id,
+		"status": models.PaymentCaptured,
+		"$expr":  bson.M{"$gte": bson.A{"$refunded", "$amount"}},
+	}, bson.M{"$set": bson.M{"status": models.PaymentRefunded}})
+	return mongoError(err)
+}
+
+func (r *mongoPaymentRepository) CancelRefund(ctx context.Context, id primitive.ObjectID, key string) error {
+	payment, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	refund := findRefund(payment, key)
+	if refund == nil || refund.Status != models.RefundPending {
+		return ErrConflict
+	}
+
+	filter := bson.M{
+		"_id":     id,
+		"refunds": bson.M{"$elemMatch": bson.M{"key": key, "status": models.RefundPending}},
+	}
+	update := bson.M{
+		"$inc":  bson.M{"refunded": -refund.Amount},
+		"$pull": bson.M{"refunds": bson.M{"key": key}},
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		// Settled or cancelled since it was read
+		return ErrConflict
+	}
+	return nil
+}
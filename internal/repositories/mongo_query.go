@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"admission-portal-backend/internal/query"
+)
+
+// mongoFilter translates query conditions into a MongoDB filter.
+func mongoFilter(conditions []query.Condition) bson.M {
+	filter := bson.M{}
+	clauses := bson.A{}
+	for _, cond := range conditions {
+		var clause bson.M
+		switch cond.Op {
+		case query.OpNe:
+			clause = bson.M{cond.Field: bson.M{"$ne": cond.Value}}
+		case query.OpIn:
+			clause = bson.M{cond.Field: bson.M{"$in": cond.Value}}
+		case query.OpGte:
+			clause = bson.M{cond.Field: bson.M{"$gte": cond.Value}}
+		case query.OpLte:
+			clause = bson.M{cond.Field: bson.M{"$lte": cond.Value}}
+		case query.OpSearch:
+			pattern := primitive.Regex{Pattern: regexp.QuoteMeta(cond.Value.(string)), Options: "i"}
+			anyField := bson.A{}
+			for _, field := range cond.Fields {
+				anyField = append(anyField, bson.M{field: pattern})
+			}
+			clause = bson.M{"$or": anyField}
+		default:
+			clause = bson.M{cond.Field: cond.Value}
+		}
+		clauses = append(clauses, clause)
+	}
+	if len(clauses) > 0 {
+		filter["$and"] = clauses
+	}
+	return filter
+}
+
+func mongoFindOptions(opts query.Options) *options.FindOptions {
+	findOptions := options.Find()
+	if len(opts.Sort) > 0 {
+		sort := bson.D{}
+		for _, s := range opts.Sort {
+			direction := 1
+			if s.Desc {
+				direction = -1
+			}
+			sort = append(sort, bson.E{Key: s.Field, Value: direction})
+		}
+		findOptions.SetSort(sort)
+	}
+	if opts.Limit > 0 {
+		findOptions.SetLimit(int64(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		findOptions.SetSkip(int64(opts.Offset))
+	}
+	return findOptions
+}
+
+// mongoList runs a paginated query against a collection.
+func mongoList[T any](ctx context.Context, collection *mongo.Collection, opts query.Options) (*query.Page[T], error) {
+	filter := mongoFilter(opts.Conditions)
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := collection.Find(ctx, filter, mongoFindOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items := []T{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return query.NewPage(items, total, opts), nil
+}
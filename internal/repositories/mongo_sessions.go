@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"admission-portal-backend/internal/models"
+)
+
+type mongoSessionRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	result, err := r.collection.InsertOne(ctx, session)
+	if err != nil {
+		return mongoError(err)
+	}
+	session.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoSessionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	var session models.Session
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session); err != nil {
+		return nil, mongoError(err)
+	}
+	return &session, nil
+}
+
+func (r *mongoSessionRepository) Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, usedAt time.Time) error {
+	filter := bson.M{"_id": id, "refreshTokenHash": oldHash, "revokedAt": nil}
+	update := bson.M{
+		"$set":  bson.M{"refreshTokenHash": newHash, "lastUsedAt": usedAt},
+		"$push": bson.M{"previousTokenHashes": bson.M{"$each": bson.A{oldHash}, "$slice": -MaxPreviousTokenHashes}},
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoSessionRepository) Revoke(ctx context.Context, id primitive.ObjectID, reason string) error {
+	update := bson.M{"$set": bson.M{"revokedAt": time.Now(), "revokedReason": reason}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "revokedAt": nil}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		// Already revoked is fine; only report sessions that do not exist.
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *mongoSessionRepository) RevokeAllForUser(ctx context.Context, userID primitive.ObjectID, reason string) error {
+	update := bson.M{"$set": bson.M{"revokedAt": time.Now(), "revokedReason": reason}}
+	_, err := r.collection.UpdateMany(ctx, bson.M{"userId": userID, "revokedAt": nil}, update)
+	return err
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+)
+
+type mongoStudentRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoStudentRepository) Create(ctx context.Context, student *models.Student) error {
+	result, err := r.collection.InsertOne(ctx, student)
+	if err != nil {
+		return mongoError(err)
+	}
+	student.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoStudentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Student, error) {
+	var student models.Student
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&student); err != nil {
+		return nil, mongoError(err)
+	}
+	return &student, nil
+}
+
+func (r *mongoStudentRepository) FindByEmail(ctx context.Context, email string) (*models.Student, error) {
+	var student models.Student
+	if err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&student); err != nil {
+		return nil, mongoError(err)
+	}
+	return &student, nil
+}
+
+func (r *mongoStudentRepository) Update(ctx context.Context, student *models.Student) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": student.ID}, student)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoStudentRepository) List(ctx context.Context, opts query.Options) (*query.Page[models.Student], error) {
+	return mongoList[models.Student](ctx, r.collection, opts)
+}
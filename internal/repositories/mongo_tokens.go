@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"admission-portal-backend/internal/models"
+)
+
+type mongoActionTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoActionTokenRepository) Create(ctx context.Context, token *models.ActionToken) error {
+	result, err := r.collection.InsertOne(ctx, token)
+	if err != nil {
+		return mongoError(err)
+	}
+	token.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoActionTokenRepository) Consume(ctx context.Context, purpose, tokenHash string, now time.Time) (*models.ActionToken, error) {
+	filter := bson.M{
+		"purpose":   purpose,
+		"tokenHash": tokenHash,
+		"usedAt":    nil,
+		"expiresAt": bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"usedAt": now}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var token models.ActionToken
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&token); err != nil {
+		return nil, mongoError(err)
+	}
+	return &token, nil
+}
+
+func (r *mongoActionTokenRepository) InvalidateForUser(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	filter := bson.M{"userId": userID, "purpose": purpose, "usedAt": nil}
+	_, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"usedAt": time.Now()}})
+	return err
+}
@@ -0,0 +1,252 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/workflow"
+)
+
+var (
+	// ErrNotFound is returned when no document matches the lookup.
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicate is returned when a write would violate a uniqueness rule.
+	ErrDuplicate = errors.New("duplicate")
+	// ErrConflict is returned when a conditional write finds the document in
+	// a different state than the caller expected.
+	ErrConflict = errors.New("conflict")
+	// ErrNoSeats is returned when a course has no free seats left.
+	ErrNoSeats = errors.New("no seats available")
+)
+
+type StudentRepository interface {
+	Create(ctx context.Context, student *models.Student) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Student, error)
+	FindByEmail(ctx context.Context, email string) (*models.Student, error)
+	Update(ctx context.Context, student *models.Student) error
+	List(ctx context.Context, opts query.Options) (*query.Page[models.Student], error)
+}
+
+type CourseRepository interface {
+	Create(ctx context.Context, course *models.Course) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Course, error)
+	List(ctx context.Context, opts query.Options) (*query.Page[models.Course], error)
+	Update(ctx context.Context, course *models.Course) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// ReserveSeat atomically takes one seat, or returns ErrNoSeats when the
+	// course is full.
+	ReserveSeat(ctx context.Context, id primitive.ObjectID) error
+	// ReleaseSeat atomically gives back a seat taken by ReserveSeat.
+	ReleaseSeat(ctx context.Context, id primitive.ObjectID) error
+}
+
+type AdmissionRepository interface {
+	// Create returns ErrDuplicate if the student already has an admission
+	// for the course in the same cycle.
+	Create(ctx context.Context, admission *models.Admission) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Admission, error)
+	List(ctx context.Context, opts query.Options) (*query.Page[models.Admission], error)
+	// Transition moves the admission to change.To and appends change to its
+	// status history, provided its status is still change.From. Otherwise it
+	// returns ErrConflict. The admission's seat quota and offer deadline are
+	// replaced by the change's, and its waitlistedAt set when the change puts
+	// it on the waitlist and cleared otherwise.
+	Transition(ctx context.Context, id primitive.ObjectID, change models.StatusChange) error
+	// Allocate is Transition for multi-course applications: it also points the
+	// admission at courseID and stores the updated preferences.
+	Allocate(ctx context.Context, id primitive.ObjectID, courseID primitive.ObjectID, preferences []models.Preference, change models.StatusChange) error
+	// UpdateDraft saves the contents of a draft admission: its details,
+	// documents, courses, category and fees, and SubmittedAt. It returns
+	// ErrConflict unless the stored admission is still an unsubmitted draft.
+	UpdateDraft(ctx context.Context, admission *models.Admission) error
+	// Submit is UpdateDraft for a completed draft, which must have
+	// SubmittedAt set. When change is not nil the admission also moves to
+	// change.To in the same update, so it is never left submitted but still
+	// a draft.
+	Submit(ctx context.Context, admission *models.Admission, change *models.StatusChange) error
+}
+
+// MaxPreviousTokenHashes is how many rotated-away refresh token hashes a
+// session remembers. Replaying one of them revokes the session; older tokens
+// are merely rejected.
+const MaxPreviousTokenHashes = 100
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error)
+	// Rotate replaces the session's refresh token hash, provided the session
+	// is not revoked and its current hash is still oldHash. Otherwise it
+	// returns ErrConflict. oldHash joins the session's previous hashes, of
+	// which only the last MaxPreviousTokenHashes are kept.
+	Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, usedAt time.Time) error
+	Revoke(ctx context.Context, id primitive.ObjectID, reason string) error
+	RevokeAllForUser(ctx context.Context, userID primitive.ObjectID, reason string) error
+}
+
+type InviteRepository interface {
+	Create(ctx context.Context, invite *models.Invite) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Invite, error)
+	List(ctx context.Context, opts query.Options) (*query.Page[models.Invite], error)
+	// Accept marks an invite used by userID, or returns ErrConflict if it
+	// was already used, revoked or had expired at now.
+	Accept(ctx context.Context, id, userID primitive.ObjectID, now time.Time) error
+	// Reopen undoes Accept for when the invitee's account could not be
+	// created after all.
+	Reopen(ctx context.Context, id primitive.ObjectID) error
+	// Revoke cancels an invite, or returns ErrConflict if it is no longer
+	// pending at now.
+	Revoke(ctx context.Context, id primitive.ObjectID, now time.Time) error
+}
+
+type ActionTokenRepository interface {
+	Create(ctx context.Context, token *models.ActionToken) error
+	// Consume marks the unused, unexpired token with the given purpose and
+	// hash as used and returns it. It returns ErrNotFound if there is none.
+	Consume(ctx context.Context, purpose, tokenHash string, now time.Time) (*models.ActionToken, error)
+	// InvalidateForUser marks every outstanding token of a purpose as used.
+	InvalidateForUser(ctx context.Context, userID primitive.ObjectID, purpose string) error
+}
+
+type DocumentRepository interface {
+	Create(ctx context.Context, document *models.Document) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Document, error)
+	ListByOwner(ctx context.Context, ownerID primitive.ObjectID) ([]models.Document, error)
+	ListByAdmission(ctx context.Context, admissionID primitive.ObjectID) ([]models.Document, error)
+	// AttachToAdmission links an owner's document to one of their admissions.
+	// It returns ErrConflict if the document is already linked elsewhere.
+	AttachToAdmission(ctx context.Context, id, ownerID, admissionID primitive.ObjectID) error
+	// DetachFromAdmission undoes AttachToAdmission. Documents not linked to
+	// admissionID are left as they are.
+	DetachFromAdmission(ctx context.Context, id, admissionID primitive.ObjectID) error
+}
+
+type CycleRepository interface {
+	// Create stores a new cycle, or returns ErrDuplicate if its code is taken.
+	Create(ctx context.Context, cycle *models.AdmissionCycle) error
+	FindByCode(ctx context.Context, code string) (*models.AdmissionCycle, error)
+	List(ctx context.Context, opts query.Options) (*query.Page[models.AdmissionCycle], error)
+	Update(ctx context.Context, cycle *models.AdmissionCycle) error
+}
+
+// CycleSeatRepository counts the seats taken in each quota of each course
+// of each admission cycle.
+type CycleSeatRepository interface {
+	// Reserve atomically takes one of the limit seats of the quota, or
+	// returns ErrNoSeats when they are all taken.
+	Reserve(ctx context.Context, cycle string, courseID primitive.ObjectID, quota string, limit int) error
+	// Release gives back a seat taken by Reserve.
+	Release(ctx context.Context, cycle string, courseID primitive.ObjectID, quota string) error
+	// Filled returns how many seats are taken in each quota of the course.
+	Filled(ctx context.Context, cycle string, courseID primitive.ObjectID) (map[string]int, error)
+}
+
+// PaymentRepository stores payments. Status changes are conditional so
+// repeated or racing gateway callbacks settle a payment only once.
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *models.Payment) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Payment, error)
+	FindByIntent(ctx context.Context, intentID string) (*models.Payment, error)
+	List(ctx context.Context, opts query.Options) (*query.Page[models.Payment], error)
+	// Capture marks a pending payment captured and stores its receipt, or
+	// returns ErrConflict if it is no longer pending. It returns
+	// ErrDuplicate if another payment for the same admission and purpose is
+	// already captured, so a fee is only ever collected once.
+	Capture(ctx context.Context, id primitive.ObjectID, receipt models.Receipt) error
+	// Fail marks a pending payment failed, or returns ErrConflict if it is no
+	// longer pending.
+	Fail(ctx context.Context, id primitive.ObjectID, reason string, at time.Time) error
+	// AddRefund reserves a pending refund on a captured payment, counting it
+	// against what is left to pay back. It returns ErrDuplicate if a refund
+	// with the same key exists and ErrConflict if the refund would exceed
+	// what is left.
+	AddRefund(ctx context.Context, id primitive.ObjectID, refund models.Refund) error
+	// SettleRefund marks the pending refund with key succeeded and marks the
+	// payment refunded once nothing is left to pay back. It returns
+	// ErrConflict if there is no such pending refund.
+	SettleRefund(ctx context.Context, id primitive.ObjectID, key, gatewayRef string, at time.Time) error
+	// CancelRefund removes the pending refund with key, giving its amount
+	// back. It returns ErrConflict if there is no such pending refund.
+	CancelRefund(ctx context.Context, id primitive.ObjectID, key string) error
+}
+
+// MeritListRepository stores published merit lists. Lists are immutable, so
+// there is no Update or Delete.
+type MeritListRepository interface {
+	// Create stores a new list version. It returns ErrDuplicate if the course
+	// and cycle already have a list with that version.
+	Create(ctx context.Context, list *models.MeritList) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.MeritList, error)
+	// Latest returns the newest version for a course and cycle, or
+	// ErrNotFound if none has been published.
+	Latest(ctx context.Context, courseID primitive.ObjectID, cycle string) (*models.MeritList, error)
+	List(ctx context.Context, opts query.Options) (*query.Page[models.MeritList], error)
+}
+
+// AuditRepository stores the append-only audit log. There is deliberately no
+// way to change or remove an event.
+type AuditRepository interface {
+	// Append stores event, or returns ErrDuplicate if another event already
+	// has its sequence number.
+	Append(ctx context.Context, event *models.AuditEvent) error
+	// Last returns the event with the highest sequence number, or ErrNotFound
+	// when the log is empty.
+	Last(ctx context.Context) (*models.AuditEvent, error)
+	List(ctx context.Context, opts query.Options) (*query.Page[models.AuditEvent], error)
+}
+
+// AuditQueueRepository holds audit events waiting to be appended to the
+// log, so an event is never dropped when the log is too busy to take it.
+type AuditQueueRepository interface {
+	Add(ctx context.Context, pending *models.PendingAuditEvent) error
+	// Oldest returns up to limit waiting events, oldest first.
+	Oldest(ctx context.Context, limit int) ([]models.PendingAuditEvent, error)
+	Remove(ctx context.Context, id primitive.ObjectID) error
+}
+
+// IdempotencyRepository remembers the Idempotency-Key a user sent with a
+// request and the response it got, so a retried request is answered
+// without being carried out again.
+type IdempotencyRepository interface {
+	// Reserve stores record as the first use of its key. If the user has
+	// already used the key and the record has not expired, it returns the
+	// stored record and ErrDuplicate instead.
+	Reserve(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error)
+	// Complete stores the response to the request a record was reserved for.
+	Complete(ctx context.Context, id primitive.ObjectID, statusCode int, contentType string, body []byte) error
+	// Release deletes a reservation so the key can be used again.
+	Release(ctx context.Context, id primitive.ObjectID) error
+}
+
+// Store groups the repositories used by the API so they can be swapped as a unit.
+type Store struct {
+	Students   StudentRepository
+	Courses    CourseRepository
+	Admissions AdmissionRepository
+	Sessions   SessionRepository
+	Tokens     ActionTokenRepository
+	Invites    InviteRepository
+	Documents  DocumentRepository
+	Audit      AuditRepository
+	AuditQueue AuditQueueRepository
+	MeritLists MeritListRepository
+	Cycles     CycleRepository
+	CycleSeats CycleSeatRepository
+	Payments   PaymentRepository
+
+	IdempotencyKeys IdempotencyRepository
+}
+
+// waitlistedAt is when an admission moved by change joined its course's
+// waitlist, or nil if the change takes it off or never puts it on one.
+func waitlistedAt(change models.StatusChange) *time.Time {
+	if change.To != workflow.StatusWaitlisted {
+		return nil
+	}
+	at := change.ChangedAt
+	return &at
+}
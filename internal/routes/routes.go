@@ -5,34 +5,115 @@ import (
 
 	"admission-portal-backend/internal/controllers"
 	"admission-portal-backend/internal/middlewares"
+	"admission-portal-backend/internal/rbac"
 )
 
-func SetupRoutes(router *gin.Engine) {
+func SetupRoutes(router *gin.Engine, h *controllers.Handler) {
+	router.Use(middlewares.RequestID())
+
 	// Public routes
-	router.POST("/api/students/signup", controllers.Signup)
-	router.POST("/api/students/login", controllers.Login)
-	router.POST("/api/students/create-admin", controllers.CreateAdmin)
+	router.GET("/.well-known/jwks.json", h.JWKS)
+	router.POST("/api/students/signup", h.Signup)
+	router.POST("/api/students/login", h.Login)
+	router.POST("/api/auth/refresh", h.Refresh)
+	router.POST("/api/auth/forgot-password", h.ForgotPassword)
+	router.POST("/api/auth/reset-password", h.ResetPassword)
+	router.GET("/api/auth/verify", h.VerifyEmail)
+	// Staff invites, including the first-run bootstrap invite, are
+	// authenticated by their signed token
+	router.GET("/api/invites", h.GetInvite)
+	router.POST("/api/invites/accept", h.AcceptInvite)
+	// Payment gateway callbacks are authenticated by their signature
+	router.POST("/api/payments/webhook", h.PaymentWebhook)
 
 	// Protected routes
 	authorized := router.Group("/api")
-	authorized.Use(middlewares.AuthMiddleware())
+	authorized.Use(middlewares.AuthMiddleware(h.Sessions))
+	// POSTs sent with an Idempotency-Key replay their first response on retry
+	authorized.Use(middlewares.Idempotency(h.IdempotencyKeys, controllers.MaxRequestBytes()))
 	{
+		// Session routes
+		authorized.POST("/auth/logout", h.Logout)
+		authorized.POST("/auth/logout-all", h.LogoutAll)
+		authorized.POST("/auth/resend-verification", h.ResendVerification)
+
 		// Student routes
-		authorized.GET("/students/me", controllers.GetProfile)
-		authorized.PUT("/students/me", controllers.UpdateProfile)
-		authorized.GET("/students/admins", middlewares.AdminOnly(), controllers.ListAdmins)
+		authorized.GET("/students/me", h.GetProfile)
+		authorized.PUT("/students/me", h.UpdateProfile)
+		authorized.GET("/students/admins", middlewares.RequirePermission(rbac.UsersRead), h.ListAdmins)
 
 		// Course routes
-		authorized.POST("/courses", middlewares.AdminOnly(), controllers.CreateCourse)
-		authorized.GET("/courses", controllers.GetCourses)
-		authorized.GET("/courses/:id", controllers.GetCourse)
-		authorized.PUT("/courses/:id", middlewares.AdminOnly(), controllers.UpdateCourse)
-		authorized.DELETE("/courses/:id", middlewares.AdminOnly(), controllers.DeleteCourse)
+		authorized.POST("/courses", middlewares.RequirePermission(rbac.CoursesWrite), h.CreateCourse)
+		authorized.GET("/courses", h.GetCourses)
+		authorized.GET("/courses/:id", h.GetCourse)
+		authorized.POST("/courses/:id/eligibility-check", h.CheckEligibility)
+		authorized.PUT("/courses/:id", middlewares.RequirePermission(rbac.CoursesWrite), h.UpdateCourse)
+		authorized.DELETE("/courses/:id", middlewares.RequirePermission(rbac.CoursesWrite), h.DeleteCourse)
+
+		// Admission cycle routes
+		authorized.GET("/cycles", h.GetCycles)
+		authorized.GET("/cycles/:code", h.GetCycle)
 
 		// Admission routes
-		authorized.POST("/admissions", controllers.ApplyAdmission)
-		authorized.GET("/admissions", controllers.GetAdmissions)
-		authorized.GET("/admissions/:id", controllers.GetAdmission)
-		authorized.PUT("/admissions/:id", middlewares.AdminOnly(), controllers.UpdateAdmissionStatus)
+		authorized.POST("/admissions", h.ApplyAdmission)
+		authorized.POST("/admissions/drafts", h.CreateDraft)
+		authorized.GET("/admissions", h.GetAdmissions)
+		authorized.GET("/admissions/:id", h.GetAdmission)
+		// Who may make which status change is decided by the workflow transition
+		// table and the caller's permissions
+		authorized.PUT("/admissions/:id", h.UpdateAdmissionStatus)
+		authorized.PATCH("/admissions/:id", h.UpdateDraft)
+		authorized.POST("/admissions/:id/submit", h.SubmitAdmission)
+		authorized.POST("/admissions/:id/withdraw", h.WithdrawAdmission)
+		authorized.POST("/admissions/:id/accept", h.AcceptOffer)
+		authorized.POST("/admissions/:id/decline", h.DeclineOffer)
+		authorized.GET("/admissions/:id/merit", h.GetAdmissionMerit)
+		authorized.GET("/admissions/:id/waitlist", h.GetWaitlistPosition)
+		authorized.POST("/admissions/:id/payments", h.CreatePayment)
+		authorized.GET("/admissions/:id/payments", h.ListAdmissionPayments)
+
+		// Payment routes
+		authorized.GET("/payments/:id", h.GetPayment)
+		authorized.GET("/payments/:id/receipt", h.GetPaymentReceipt)
+		authorized.POST("/payments/:id/fake-checkout", h.CompleteFakeCheckout)
+
+		// Admin review routes
+		admin := authorized.Group("/admin")
+		admin.GET("/admissions", middlewares.RequirePermission(rbac.AdmissionsRead), h.AdminListAdmissions)
+		admin.GET("/admissions/:id", middlewares.RequirePermission(rbac.AdmissionsRead), h.AdminGetAdmission)
+		admin.GET("/waitlists/:courseId", middlewares.RequirePermission(rbac.AdmissionsRead), h.GetCourseWaitlist)
+
+		admin.POST("/cycles", middlewares.RequirePermission(rbac.CyclesWrite), h.CreateCycle)
+		admin.PUT("/cycles/:code", middlewares.RequirePermission(rbac.CyclesWrite), h.UpdateCycle)
+
+		// Merit list routes
+		admin.GET("/merit-lists", middlewares.RequirePermission(rbac.AdmissionsRead), h.ListMeritLists)
+		admin.GET("/merit-lists/:id", middlewares.RequirePermission(rbac.AdmissionsRead), h.GetMeritList)
+		admin.POST("/merit-lists/preview", middlewares.RequirePermission(rbac.AdmissionsReview), h.PreviewMeritList)
+		admin.POST("/merit-lists", middlewares.RequirePermission(rbac.AdmissionsDecide), h.PublishMeritList)
+
+		// Payment administration routes
+		admin.GET("/payments", middlewares.RequirePermission(rbac.FeesRead), h.ListPayments)
+		admin.POST("/payments/:id/refunds", middlewares.RequirePermission(rbac.FeesRefund), h.RefundPayment)
+
+		// Role management routes
+		admin.GET("/roles", middlewares.RequirePermission(rbac.RolesManage), h.ListRoles)
+		admin.PUT("/users/:id/role", middlewares.RequirePermission(rbac.RolesManage), h.AssignRole)
+		admin.DELETE("/users/:id/role", middlewares.RequirePermission(rbac.RolesManage), h.RevokeRole)
+
+		// Staff invite routes
+		admin.POST("/invites", middlewares.RequirePermission(rbac.RolesManage), h.CreateInvite)
+		admin.GET("/invites", middlewares.RequirePermission(rbac.RolesManage), h.ListInvites)
+		admin.DELETE("/invites/:id", middlewares.RequirePermission(rbac.RolesManage), h.RevokeInvite)
+
+		// Audit routes
+		admin.GET("/audit", middlewares.RequirePermission(rbac.AuditRead), h.ListAuditEvents)
+		admin.GET("/audit/verify", middlewares.RequirePermission(rbac.AuditRead), h.VerifyAuditLog)
+
+		// Document routes
+		authorized.POST("/documents", h.UploadDocument)
+		authorized.GET("/documents", h.ListDocuments)
+		authorized.GET("/documents/:id", h.GetDocument)
+		authorized.GET("/documents/:id/download", h.DownloadDocument)
 	}
 }
@@ -0,0 +1,310 @@
+// Package services holds the business operations that span more than one
+// repository, such as moving an admission through its lifecycle while
+// keeping course seat counts in step.
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/mailer"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/quota"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/workflow"
+)
+
+// ErrCourseNotFound is returned when the admission's course no longer exists.
+var ErrCourseNotFound = errors.New("course not found")
+
+// ErrCycleNotFound is returned when the admission's cycle no longer exists.
+var ErrCycleNotFound = errors.New("admission cycle not found")
+
+// ErrPaymentRequired is returned when a transition needs a fee that has not
+// been paid.
+var ErrPaymentRequired = errors.New("payment required")
+
+// ErrNotSubmitted is returned when moving a draft on to submitted, or paying
+// its application fee, before the student has submitted it.
+var ErrNotSubmitted = errors.New("draft has not been submitted")
+
+// Actor identifies who is performing an operation. Role is the user's rbac
+// role; system actions use a zero ID and workflow.RoleSystem.
+type Actor struct {
+	ID   primitive.ObjectID
+	Role string
+}
+
+// SystemActor is used for changes the server makes on its own.
+var SystemActor = Actor{Role: workflow.RoleSystem}
+
+type AdmissionService struct {
+	Admissions repositories.AdmissionRepository
+	Courses    repositories.CourseRepository
+	Cycles     repositories.CycleRepository
+	CycleSeats repositories.CycleSeatRepository
+	Payments   repositories.PaymentRepository
+	Students   repositories.StudentRepository
+	// OfferWindow is how long applicants have to answer an offer. Offers
+	// never expire when it is zero.
+	OfferWindow time.Duration
+	// Mailer and Audit, when set, notify applicants of offers made from the
+	// waitlist and record the changes the service makes on its own.
+	Mailer mailer.Mailer
+	Audit  *audit.Recorder
+}
+
+func NewAdmissionService(store *repositories.Store) *AdmissionService {
+	return &AdmissionService{
+		Admissions: store.Admissions,
+		Courses:    store.Courses,
+		Cycles:     store.Cycles,
+		CycleSeats: store.CycleSeats,
+		Payments:   store.Payments,
+		Students:   store.Students,
+
+		OfferWindow: DefaultOfferWindow,
+	}
+}
+
+// reserveSeat takes a seat in courseID for admission and returns the quota
+// it came from. Admissions in a cycle fill the cycle's seat matrix for the
+// course, trying the applicant's category quota before the general one;
+// older ones count against the course's own counter and have no quota.
+func (s *AdmissionService) reserveSeat(ctx context.Context, admission *models.Admission, courseID primitive.ObjectID) (string, error) {
+	if admission.Cycle == "" {
+		return "", s.Courses.ReserveSeat(ctx, courseID)
+	}
+	course, err := s.Courses.FindByID(ctx, courseID)
+	if err != nil {
+		return "", err
+	}
+	cycle, err := s.Cycles.FindByCode(ctx, admission.Cycle)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return "", ErrCycleNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	filled, err := s.CycleSeats.Filled(ctx, admission.Cycle, courseID)
+	if err != nil {
+		return "", err
+	}
+
+	matrix := quota.Matrix(cycle, course, filled, time.Now())
+	for _, bucket := range quota.Buckets(admission.Category) {
+		err := s.CycleSeats.Reserve(ctx, admission.Cycle, courseID, bucket, quota.Limit(matrix, bucket))
+		if !errors.Is(err, repositories.ErrNoSeats) {
+			return bucket, err
+		}
+	}
+	return "", repositories.ErrNoSeats
+}
+
+// releaseSeat gives back a seat taken by reserveSeat from seatQuota.
+func (s *AdmissionService) releaseSeat(ctx context.Context, admission *models.Admission, courseID primitive.ObjectID, seatQuota string) error {
+	if admission.Cycle == "" {
+		return s.Courses.ReleaseSeat(ctx, courseID)
+	}
+	return s.CycleSeats.Release(ctx, admission.Cycle, courseID, quota.Normalize(seatQuota))
+}
+
+// holdsSeat reports whether an admission in the given status occupies one of
+// its course's seats. Legacy statuses never reserved one, so they do not;
+// migration 8 relabels legacy approvals as offers and counts their seats.
+func holdsSeat(status string) bool {
+	switch status {
+	case workflow.StatusOffered, workflow.StatusAccepted, workflow.StatusEnrolled:
+		return true
+	}
+	return false
+}
+
+// checkTransition applies the workflow table to the actor and, for staff,
+// requires the permission the target status calls for.
+func checkTransition(from, to string, actor Actor) error {
+	if err := workflow.Check(from, to, rbac.WorkflowRole(actor.Role)); err != nil {
+		return err
+	}
+	if perm, ok := rbac.TransitionPermission(to); ok && rbac.IsStaff(actor.Role) && !rbac.Can(actor.Role, perm) {
+		return workflow.ErrRoleNotAllowed
+	}
+	return nil
+}
+
+// Submit locks a completed draft, whose SubmittedAt must be set, on behalf
+// of actor. With no application fee due it moves to submitted in the same
+// update; otherwise it stays a draft until the fee is paid.
+func (s *AdmissionService) Submit(ctx context.Context, admission *models.Admission, actor Actor) error {
+	if admission.SubmittedAt == nil {
+		return ErrNotSubmitted
+	}
+	if admission.ApplicationFee+admission.LateFee > 0 {
+		return s.Admissions.Submit(ctx, admission, nil)
+	}
+	if err := checkTransition(admission.Status, workflow.StatusSubmitted, actor); err != nil {
+		return err
+	}
+	change := &models.StatusChange{
+		From:      admission.Status,
+		To:        workflow.StatusSubmitted,
+		ChangedBy: actor.ID,
+		Role:      actor.Role,
+		ChangedAt: *admission.SubmittedAt,
+	}
+	return s.Admissions.Submit(ctx, admission, change)
+}
+
+// Transition moves admission to the requested status on behalf of actor and
+// returns the status it actually ended up in.
+//
+// Moving into a seat-holding status reserves a seat first. When the course is
+// full, an offer is turned into a waitlist entry if the actor may waitlist the
+// application; otherwise repositories.ErrNoSeats is returned. Leaving a
+// seat-holding status releases the seat. Submitting needs the application
+// fee and enrolling needs tuition to be paid first, or ErrPaymentRequired is
+// returned. Offers must be answered within OfferWindow; answering later
+// expires the offer and returns ErrOfferExpired. A freed seat is offered to
+// the course's waitlist. Offers on multi-course applications are allocated
+// across their preferences instead; see allocate.
+func (s *AdmissionService) Transition(ctx context.Context, admission *models.Admission, to string, actor Actor, comments string) (string, error) {
+	if err := checkTransition(admission.Status, to, actor); err != nil {
+		return "", err
+	}
+	if to == workflow.StatusSubmitted && admission.SubmittedAt == nil {
+		return "", ErrNotSubmitted
+	}
+	if err := s.checkPayment(ctx, admission, to); err != nil {
+		return "", err
+	}
+	if err := s.checkOfferDeadline(ctx, admission, to); err != nil {
+		return "", err
+	}
+	if to == workflow.StatusOffered && len(admission.Preferences) > 0 {
+		return s.allocate(ctx, admission, actor, comments)
+	}
+
+	reserved, seatQuota := false, admission.SeatQuota
+	if holdsSeat(to) && !holdsSeat(admission.Status) {
+		bucket, err := s.reserveSeat(ctx, admission, admission.CourseID)
+		switch {
+		case err == nil:
+			reserved, seatQuota = true, bucket
+		case errors.Is(err, repositories.ErrNoSeats) && to == workflow.StatusOffered &&
+			checkTransition(admission.Status, workflow.StatusWaitlisted, actor) == nil:
+			to = workflow.StatusWaitlisted
+			if comments == "" {
+				comments = "No seats available, moved to waitlist"
+			}
+		case errors.Is(err, repositories.ErrNotFound):
+			return "", ErrCourseNotFound
+		default:
+			return "", err
+		}
+	}
+
+	if !holdsSeat(to) {
+		seatQuota = ""
+	}
+
+	now := time.Now()
+	change := models.StatusChange{
+		From:           admission.Status,
+		To:             to,
+		ChangedBy:      actor.ID,
+		Role:           actor.Role,
+		Comments:       comments,
+		SeatQuota:      seatQuota,
+		OfferExpiresAt: s.offerDeadline(to, now),
+		ChangedAt:      now,
+	}
+	if err := s.Admissions.Transition(ctx, admission.ID, change); err != nil {
+		if reserved {
+			// Give the seat back; the admission never moved.
+			_ = s.releaseSeat(ctx, admission, admission.CourseID, seatQuota)
+		}
+		return "", err
+	}
+
+	if holdsSeat(admission.Status) && !holdsSeat(to) {
+		err := s.releaseSeat(ctx, admission, admission.CourseID, admission.SeatQuota)
+		switch {
+		case err == nil:
+			s.fillFreedSeat(ctx, admission.Cycle, admission.CourseID)
+		case !errors.Is(err, repositories.ErrNotFound):
+			log.Printf("Error releasing seat for admission %s: %v", admission.ID.Hex(), err)
+		}
+	}
+	return to, nil
+}
+
+// allocate makes an offer on a multi-course application. The seat goes to
+// the highest-ranked preference the applicant is eligible for that still
+// has room, and every preference's status is updated to show the outcome.
+// When all of them are full the application is waitlisted if the actor may
+// do so; otherwise repositories.ErrNoSeats is returned.
+func (s *AdmissionService) allocate(ctx context.Context, admission *models.Admission, actor Actor, comments string) (string, error) {
+	preferences := append([]models.Preference{}, admission.Preferences...)
+	allocated, seatQuota := -1, ""
+	for i := range preferences {
+		preference := &preferences[i]
+		if preference.Status == models.PreferenceIneligible {
+			continue
+		}
+		if allocated >= 0 {
+			preference.Status = models.PreferenceNotReached
+			continue
+		}
+		bucket, err := s.reserveSeat(ctx, admission, preference.CourseID)
+		switch {
+		case err == nil:
+			preference.Status = models.PreferenceAllocated
+			allocated, seatQuota = i, bucket
+		case errors.Is(err, repositories.ErrNoSeats):
+			preference.Status = models.PreferenceNoSeats
+		case errors.Is(err, repositories.ErrNotFound):
+			preference.Status = models.PreferenceUnavailable
+		default:
+			return "", err
+		}
+	}
+
+	to, courseID := workflow.StatusOffered, admission.CourseID
+	if allocated >= 0 {
+		courseID = preferences[allocated].CourseID
+	} else {
+		if checkTransition(admission.Status, workflow.StatusWaitlisted, actor) != nil {
+			return "", repositories.ErrNoSeats
+		}
+		to = workflow.StatusWaitlisted
+		if comments == "" {
+			comments = "No seats available in any preferred course, moved to waitlist"
+		}
+	}
+
+	now := time.Now()
+	change := models.StatusChange{
+		From:           admission.Status,
+		To:             to,
+		ChangedBy:      actor.ID,
+		Role:           actor.Role,
+		Comments:       comments,
+		SeatQuota:      seatQuota,
+		OfferExpiresAt: s.offerDeadline(to, now),
+		ChangedAt:      now,
+	}
+	if err := s.Admissions.Allocate(ctx, admission.ID, courseID, preferences, change); err != nil {
+		if allocated >= 0 {
+			// Give the seat back; the admission never moved.
+			_ = s.releaseSeat(ctx, admission, courseID, seatQuota)
+		}
+		return "", err
+	}
+	return to, nil
+}
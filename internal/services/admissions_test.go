@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/workflow"
+)
+
+var (
+	officer  = Actor{ID: primitive.NewObjectID(), Role: rbac.RoleAdmissionsOfficer}
+	reviewer = Actor{ID: primitive.NewObjectID(), Role: rbac.RoleReviewer}
+)
+
+func newTestService(t *testing.T) (*AdmissionService, *repositories.Store) {
+	t.Helper()
+	store := repositories.NewMemoryStore()
+	return NewAdmissionService(store), store
+}
+
+func addCourse(t *testing.T, store *repositories.Store, seats int) *models.Course {
+	t.Helper()
+	course := &models.Course{Name: "Physics", Seats: seats}
+	if err := store.Courses.Create(context.Background(), course); err != nil {
+		t.Fatalf("creating course: %v", err)
+	}
+	return course
+}
+
+// addAdmission stores an application to course already in status. Anything
+// past a draft counts as submitted, and waitlisted ones join the back of the
+// line.
+func addAdmission(t *testing.T, store *repositories.Store, course *models.Course, status string) *models.Admission {
+	t.Helper()
+	now := time.Now()
+	admission := &models.Admission{
+		StudentID: primitive.NewObjectID(),
+		CourseID:  course.ID,
+		Status:    status,
+		CreatedAt: now,
+	}
+	if status != workflow.StatusDraft {
+		admission.SubmittedAt = &now
+	}
+	if status == workflow.StatusWaitlisted {
+		admission.WaitlistedAt = &now
+	}
+	if err := store.Admissions.Create(context.Background(), admission); err != nil {
+		t.Fatalf("creating admission: %v", err)
+	}
+	return admission
+}
+
+func reload(t *testing.T, store *repositories.Store, admission *models.Admission) *models.Admission {
+	t.Helper()
+	stored, err := store.Admissions.FindByID(context.Background(), admission.ID)
+	if err != nil {
+		t.Fatalf("loading admission: %v", err)
+	}
+	return stored
+}
+
+func TestTransition(t *testing.T) {
+	student := Actor{ID: primitive.NewObjectID(), Role: rbac.RoleStudent}
+	tests := []struct {
+		name     string
+		from, to string
+		actor    Actor
+		want     error
+	}{
+		{"staff reviews", workflow.StatusSubmitted, workflow.StatusUnderReview, reviewer, nil},
+		{"student withdraws", workflow.StatusUnderReview, workflow.StatusWithdrawn, student, nil},
+		{"legacy pending is reviewed", "pending", workflow.StatusUnderReview, reviewer, nil},
+		{"student cannot review", workflow.StatusSubmitted, workflow.StatusUnderReview, student, workflow.ErrRoleNotAllowed},
+		{"reviewer cannot offer", workflow.StatusUnderReview, workflow.StatusOffered, reviewer, workflow.ErrRoleNotAllowed},
+		{"no skipping to enrolled", workflow.StatusSubmitted, workflow.StatusEnrolled, officer, workflow.ErrIllegalTransition},
+		{"terminal status", workflow.StatusRejected, workflow.StatusUnderReview, officer, workflow.ErrIllegalTransition},
+		{"unknown status", workflow.StatusSubmitted, "approved", officer, workflow.ErrUnknownStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store := newTestService(t)
+			admission := addAdmission(t, store, addCourse(t, store, 10), tt.from)
+
+			got, err := s.Transition(context.Background(), admission, tt.to, tt.actor, "comment")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Transition = %v, want %v", err, tt.want)
+			}
+			stored := reload(t, store, admission)
+			if tt.want != nil {
+				if stored.Status != tt.from || len(stored.StatusHistory) != 0 {
+					t.Errorf("refused transition changed the admission to %s with %d history entries", stored.Status, len(stored.StatusHistory))
+				}
+				return
+			}
+			if got != tt.to || stored.Status != tt.to {
+				t.Errorf("Transition = %s, stored status %s, want %s", got, stored.Status, tt.to)
+			}
+			if len(stored.StatusHistory) != 1 {
+				t.Fatalf("%d history entries, want 1", len(stored.StatusHistory))
+			}
+			change := stored.StatusHistory[0]
+			if change.From != tt.from || change.To != tt.to || change.ChangedBy != tt.actor.ID || change.Role != tt.actor.Role || change.Comments != "comment" {
+				t.Errorf("history entry = %+v", change)
+			}
+		})
+	}
+}
+
+func TestTransitionRefusesStaleStatus(t *testing.T) {
+	s, store := newTestService(t)
+	admission := addAdmission(t, store, addCourse(t, store, 10), workflow.StatusSubmitted)
+	stale := *admission
+	if _, err := s.Transition(context.Background(), admission, workflow.StatusUnderReview, reviewer, ""); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if _, err := s.Transition(context.Background(), &stale, workflow.StatusRejected, reviewer, ""); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("Transition from a stale copy = %v, want ErrConflict", err)
+	}
+	if stored := reload(t, store, admission); stored.Status != workflow.StatusUnderReview {
+		t.Errorf("status = %s, want under_review", stored.Status)
+	}
+}
+
+func TestTransitionNeedsSubmittedDraft(t *testing.T) {
+	s, store := newTestService(t)
+	admission := addAdmission(t, store, addCourse(t, store, 10), workflow.StatusDraft)
+	if _, err := s.Transition(context.Background(), admission, workflow.StatusSubmitted, SystemActor, ""); !errors.Is(err, ErrNotSubmitted) {
+		t.Errorf("Transition of an unsubmitted draft = %v, want ErrNotSubmitted", err)
+	}
+}
+
+func seatsFilled(t *testing.T, store *repositories.Store, course *models.Course) int {
+	t.Helper()
+	stored, err := store.Courses.FindByID(context.Background(), course.ID)
+	if err != nil {
+		t.Fatalf("loading course: %v", err)
+	}
+	return stored.SeatsFilled
+}
+
+func TestTransitionReservesAndReleasesSeats(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestService(t)
+	course := addCourse(t, store, 1)
+	first := addAdmission(t, store, course, workflow.StatusUnderReview)
+	second := addAdmission(t, store, course, workflow.StatusUnderReview)
+
+	if to, err := s.Transition(ctx, first, workflow.StatusOffered, officer, ""); err != nil || to != workflow.StatusOffered {
+		t.Fatalf("offering the last seat = %s, %v", to, err)
+	}
+	if n := seatsFilled(t, store, course); n != 1 {
+		t.Errorf("seatsFilled = %d after an offer, want 1", n)
+	}
+
+	to, err := s.Transition(ctx, second, workflow.StatusOffered, officer, "")
+	if err != nil || to != workflow.StatusWaitlisted {
+		t.Fatalf("offering a seat in a full course = %s, %v, want waitlisted", to, err)
+	}
+	if stored := reload(t, store, second); stored.StatusHistory[0].Comments == "" {
+		t.Error("moving to the waitlist left no comment")
+	}
+
+	third := addAdmission(t, store, course, workflow.StatusWaitlisted)
+	if _, err := s.Transition(ctx, third, workflow.StatusOffered, SystemActor, ""); !errors.Is(err, repositories.ErrNoSeats) {
+		t.Errorf("offering from the waitlist with no seat = %v, want ErrNoSeats", err)
+	}
+
+	first = reload(t, store, first)
+	if _, err := s.Transition(ctx, first, workflow.StatusRejected, officer, ""); err != nil {
+		t.Fatalf("rejecting an offer: %v", err)
+	}
+	// The freed seat goes to the first waitlisted application
+	if n := seatsFilled(t, store, course); n != 1 {
+		t.Errorf("seatsFilled = %d after the seat was passed on, want 1", n)
+	}
+	if stored := reload(t, store, second); stored.Status != workflow.StatusOffered {
+		t.Errorf("first on the waitlist is %s, want offered", stored.Status)
+	}
+}
+
+func TestTransitionCourseNotFound(t *testing.T) {
+	s, store := newTestService(t)
+	course := addCourse(t, store, 1)
+	admission := addAdmission(t, store, course, workflow.StatusUnderReview)
+	if err := store.Courses.Delete(context.Background(), course.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Transition(context.Background(), admission, workflow.StatusOffered, officer, ""); !errors.Is(err, ErrCourseNotFound) {
+		t.Errorf("offering a seat in a deleted course = %v, want ErrCourseNotFound", err)
+	}
+}
+
+func TestTransitionLegacyApproval(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestService(t)
+	course := addCourse(t, store, 5)
+
+	// Approved before seats were counted, so it never reserved one
+	rejected := addAdmission(t, store, course, "approved")
+	if _, err := s.Transition(ctx, rejected, workflow.StatusRejected, officer, ""); err != nil {
+		t.Fatalf("rejecting a legacy approval: %v", err)
+	}
+	if n := seatsFilled(t, store, course); n != 0 {
+		t.Errorf("seatsFilled = %d after rejecting a legacy approval, want 0", n)
+	}
+
+	accepted := addAdmission(t, store, course, "approved")
+	student := Actor{ID: accepted.StudentID, Role: rbac.RoleStudent}
+	if _, err := s.Transition(ctx, accepted, workflow.StatusAccepted, student, ""); err != nil {
+		t.Fatalf("accepting a legacy approval: %v", err)
+	}
+	if n := seatsFilled(t, store, course); n != 1 {
+		t.Errorf("seatsFilled = %d after accepting a legacy approval, want 1", n)
+	}
+}
+
+func TestTransitionFillsCategoryQuotaFirst(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestService(t)
+	course := addCourse(t, store, 2)
+	now := time.Now()
+	cycle := &models.AdmissionCycle{
+		Code:     "fall-2025",
+		OpensAt:  now.Add(-time.Hour),
+		ClosesAt: now.Add(time.Hour),
+		Courses:  []models.CycleCourse{{CourseID: course.ID, Quotas: []models.Quota{{Category: "sc", Seats: 1}}}},
+	}
+	if err := store.Cycles.Create(ctx, cycle); err != nil {
+		t.Fatal(err)
+	}
+	apply := func(category string) *models.Admission {
+		admission := &models.Admission{
+			StudentID:   primitive.NewObjectID(),
+			CourseID:    course.ID,
+			Cycle:       cycle.Code,
+			Category:    category,
+			Status:      workflow.StatusUnderReview,
+			SubmittedAt: &now,
+		}
+		if err := store.Admissions.Create(ctx, admission); err != nil {
+			t.Fatal(err)
+		}
+		return admission
+	}
+
+	// sc applicants take the sc seat, then compete for general ones
+	for _, want := range []string{"sc", "general"} {
+		admission := apply("sc")
+		if _, err := s.Transition(ctx, admission, workflow.StatusOffered, officer, ""); err != nil {
+			t.Fatalf("offering: %v", err)
+		}
+		if stored := reload(t, store, admission); stored.SeatQuota != want {
+			t.Errorf("seat taken from the %q quota, want %s", stored.SeatQuota, want)
+		}
+	}
+	if to, err := s.Transition(ctx, apply(""), workflow.StatusOffered, officer, ""); err != nil || to != workflow.StatusWaitlisted {
+		t.Errorf("offering with every quota full = %s, %v, want waitlisted", to, err)
+	}
+	filled, err := store.CycleSeats.Filled(ctx, cycle.Code, course.ID)
+	if err != nil || filled["sc"] != 1 || filled["general"] != 1 {
+		t.Errorf("filled seats = %v, %v, want one in each quota", filled, err)
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	ctx := context.Background()
+	student := Actor{ID: primitive.NewObjectID(), Role: rbac.RoleStudent}
+	tests := []struct {
+		name   string
+		fee    float64
+		actor  Actor
+		want   error
+		status string
+	}{
+		{"no fee due", 0, student, nil, workflow.StatusSubmitted},
+		{"fee due", 50, student, nil, workflow.StatusDraft},
+		{"staff cannot submit", 0, reviewer, workflow.ErrRoleNotAllowed, workflow.StatusDraft},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store := newTestService(t)
+			admission := addAdmission(t, store, addCourse(t, store, 10), workflow.StatusDraft)
+			now := time.Now()
+			admission.SubmittedAt, admission.ApplicationFee = &now, tt.fee
+
+			if err := s.Submit(ctx, admission, tt.actor); !errors.Is(err, tt.want) {
+				t.Fatalf("Submit = %v, want %v", err, tt.want)
+			}
+			stored := reload(t, store, admission)
+			if stored.Status != tt.status {
+				t.Errorf("status = %s, want %s", stored.Status, tt.status)
+			}
+			// A refused submission leaves the draft editable
+			if submitted := stored.SubmittedAt != nil; submitted != (tt.want == nil) {
+				t.Errorf("submittedAt = %v after Submit returned %v", stored.SubmittedAt, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/workflow"
+)
+
+// addPreferenceAdmission stores a multi-course application in status that
+// ranks courses in order, all still pending.
+func addPreferenceAdmission(t *testing.T, store *repositories.Store, status string, courses ...*models.Course) *models.Admission {
+	t.Helper()
+	now := time.Now()
+	admission := &models.Admission{
+		StudentID:   primitive.NewObjectID(),
+		CourseID:    courses[0].ID,
+		Status:      status,
+		SubmittedAt: &now,
+		CreatedAt:   now,
+	}
+	for i, course := range courses {
+		admission.Preferences = append(admission.Preferences, models.Preference{Rank: i + 1, CourseID: course.ID, Status: models.PreferencePending})
+	}
+	if err := store.Admissions.Create(context.Background(), admission); err != nil {
+		t.Fatalf("creating admission: %v", err)
+	}
+	return admission
+}
+
+func preferenceStatuses(admission *models.Admission) []string {
+	statuses := []string{}
+	for _, preference := range admission.Preferences {
+		statuses = append(statuses, preference.Status)
+	}
+	return statuses
+}
+
+func TestAllocateOffersHighestPreferenceWithRoom(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestService(t)
+	full, ineligible, open, lower := addCourse(t, store, 1), addCourse(t, store, 5), addCourse(t, store, 5), addCourse(t, store, 5)
+	if err := store.Courses.ReserveSeat(ctx, full.ID); err != nil {
+		t.Fatal(err)
+	}
+	admission := addPreferenceAdmission(t, store, workflow.StatusUnderReview, full, ineligible, open, lower)
+	admission.Preferences[1].Status = models.PreferenceIneligible
+
+	to, err := s.Transition(ctx, admission, workflow.StatusOffered, officer, "")
+	if err != nil || to != workflow.StatusOffered {
+		t.Fatalf("Transition = %s, %v, want offered", to, err)
+	}
+	stored := reload(t, store, admission)
+	if stored.CourseID != open.ID {
+		t.Errorf("offered a seat in course %s, want the third preference", stored.CourseID.Hex())
+	}
+	want := []string{models.PreferenceNoSeats, models.PreferenceIneligible, models.PreferenceAllocated, models.PreferenceNotReached}
+	if got := preferenceStatuses(stored); !equalStrings(got, want) {
+		t.Errorf("preference statuses = %v, want %v", got, want)
+	}
+	for course, filled := range map[*models.Course]int{full: 1, ineligible: 0, open: 1, lower: 0} {
+		if n := seatsFilled(t, store, course); n != filled {
+			t.Errorf("course %s has %d seats filled, want %d", course.ID.Hex(), n, filled)
+		}
+	}
+
+	// The seat is given back to the allocated course, not the first choice
+	if _, err := s.Transition(ctx, stored, workflow.StatusRejected, officer, ""); err != nil {
+		t.Fatalf("rejecting the offer: %v", err)
+	}
+	if n := seatsFilled(t, store, open); n != 0 {
+		t.Errorf("allocated course has %d seats filled after rejection, want 0", n)
+	}
+}
+
+func TestAllocateWaitlistsWhenEveryPreferenceIsFull(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestService(t)
+	first, second := addCourse(t, store, 0), addCourse(t, store, 5)
+	if err := store.Courses.Delete(ctx, second.ID); err != nil {
+		t.Fatal(err)
+	}
+	admission := addPreferenceAdmission(t, store, workflow.StatusUnderReview, first, second)
+
+	to, err := s.Transition(ctx, admission, workflow.StatusOffered, officer, "")
+	if err != nil || to != workflow.StatusWaitlisted {
+		t.Fatalf("Transition = %s, %v, want waitlisted", to, err)
+	}
+	stored := reload(t, store, admission)
+	want := []string{models.PreferenceNoSeats, models.PreferenceUnavailable}
+	if got := preferenceStatuses(stored); !equalStrings(got, want) {
+		t.Errorf("preference statuses = %v, want %v", got, want)
+	}
+	if stored.CourseID != first.ID || stored.StatusHistory[0].Comments == "" {
+		t.Errorf("waitlisted on %s with comment %q, want the first preference and a comment", stored.CourseID.Hex(), stored.StatusHistory[0].Comments)
+	}
+
+	// The system cannot waitlist, so promoting with no room fails instead
+	if _, err := s.Transition(ctx, stored, workflow.StatusOffered, SystemActor, ""); !errors.Is(err, repositories.ErrNoSeats) {
+		t.Errorf("promoting with no room = %v, want ErrNoSeats", err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/merit"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/repositories"
+)
+
+// maxPublishAttempts bounds the retries when two admins publish the same
+// course and cycle at once and race for the next version number.
+const maxPublishAttempts = 3
+
+type MeritService struct {
+	Admissions repositories.AdmissionRepository
+	Courses    repositories.CourseRepository
+	MeritLists repositories.MeritListRepository
+	Cycles     repositories.CycleRepository
+}
+
+func NewMeritService(store *repositories.Store) *MeritService {
+	return &MeritService{
+		Admissions: store.Admissions,
+		Courses:    store.Courses,
+		MeritLists: store.MeritLists,
+		Cycles:     store.Cycles,
+	}
+}
+
+// Build ranks the current applicants to a course in a cycle without storing
+// anything. The returned list has no ID or version.
+func (s *MeritService) Build(ctx context.Context, courseID primitive.ObjectID, cycle string, weights models.MeritWeights) (*models.MeritList, error) {
+	if err := merit.Validate(weights); err != nil {
+		return nil, err
+	}
+	course, err := s.Courses.FindByID(ctx, courseID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrCourseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	intake, err := s.Cycles.FindByCode(ctx, cycle)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrCycleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.candidates(ctx, courseID, cycle)
+	if err != nil {
+		return nil, err
+	}
+
+	seats := intake.SeatsFor(course)
+	return &models.MeritList{
+		CourseID: courseID,
+		Cycle:    cycle,
+		Weights:  weights,
+		Seats:    seats,
+		Entries:  merit.Rank(candidates, weights, seats),
+	}, nil
+}
+
+// Publish builds the list and stores it as the next version for the course
+// and cycle.
+func (s *MeritService) Publish(ctx context.Context, courseID primitive.ObjectID, cycle string, weights models.MeritWeights, actor Actor) (*models.MeritList, error) {
+	list, err := s.Build(ctx, courseID, cycle, weights)
+	if err != nil {
+		return nil, err
+	}
+	list.PublishedBy = actor.ID
+
+	for attempt := 0; attempt < maxPublishAttempts; attempt++ {
+		list.Version = 1
+		latest, err := s.MeritLists.Latest(ctx, courseID, cycle)
+		switch {
+		case err == nil:
+			list.Version = latest.Version + 1
+		case !errors.Is(err, repositories.ErrNotFound):
+			return nil, err
+		}
+		list.PublishedAt = time.Now()
+
+		err = s.MeritLists.Create(ctx, list)
+		if !errors.Is(err, repositories.ErrDuplicate) {
+			return list, err
+		}
+	}
+	return nil, repositories.ErrConflict
+}
+
+// candidates returns the open applications in cycle competing for courseID:
+// those made to it directly and multi-course applications that rank it,
+// unless the applicant is ineligible for it.
+func (s *MeritService) candidates(ctx context.Context, courseID primitive.ObjectID, cycle string) ([]models.Admission, error) {
+	inCycle := query.Options{}.Where("cycle", query.OpEq, cycle)
+	direct, err := s.Admissions.List(ctx, inCycle.Where("courseId", query.OpEq, courseID))
+	if err != nil {
+		return nil, err
+	}
+	ranked, err := s.Admissions.List(ctx, inCycle.Where("preferences.courseId", query.OpEq, courseID))
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []models.Admission{}
+	seen := map[primitive.ObjectID]bool{}
+	for _, admission := range append(direct.Items, ranked.Items...) {
+		if seen[admission.ID] || !merit.IsCandidate(admission.Status) || ineligibleFor(&admission, courseID) {
+			continue
+		}
+		seen[admission.ID] = true
+		candidates = append(candidates, admission)
+	}
+	return candidates, nil
+}
+
+func ineligibleFor(admission *models.Admission, courseID primitive.ObjectID) bool {
+	for _, preference := range admission.Preferences {
+		if preference.CourseID == courseID {
+			return preference.Status == models.PreferenceIneligible
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/merit"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/workflow"
+)
+
+const testCycle = "2025-fall"
+
+// addCycle opens testCycle for courses.
+func addCycle(t *testing.T, store *repositories.Store, courses ...*models.Course) {
+	t.Helper()
+	now := time.Now()
+	cycle := &models.AdmissionCycle{Code: testCycle, Name: "Fall 2025", OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(time.Hour)}
+	for _, course := range courses {
+		cycle.Courses = append(cycle.Courses, models.CycleCourse{CourseID: course.ID})
+	}
+	if err := store.Cycles.Create(context.Background(), cycle); err != nil {
+		t.Fatalf("creating cycle: %v", err)
+	}
+}
+
+// addApplicant stores an application to course in testCycle.
+func addApplicant(t *testing.T, store *repositories.Store, course *models.Course, status string, percentage float64, preferences ...models.Preference) *models.Admission {
+	t.Helper()
+	admission := &models.Admission{
+		StudentID:       primitive.NewObjectID(),
+		CourseID:        course.ID,
+		Cycle:           testCycle,
+		Status:          status,
+		Preferences:     preferences,
+		AcademicDetails: models.AcademicDetails{Percentage: percentage},
+		CreatedAt:       time.Now(),
+	}
+	if err := store.Admissions.Create(context.Background(), admission); err != nil {
+		t.Fatalf("creating admission: %v", err)
+	}
+	return admission
+}
+
+func TestMeritBuildPicksCandidates(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	s := NewMeritService(store)
+	course, other := addCourse(t, store, 1), addCourse(t, store, 5)
+	addCycle(t, store, course, other)
+
+	direct := addApplicant(t, store, course, workflow.StatusSubmitted, 70)
+	ranking := addApplicant(t, store, other, workflow.StatusUnderReview, 90,
+		models.Preference{Rank: 1, CourseID: other.ID, Status: models.PreferencePending},
+		models.Preference{Rank: 2, CourseID: course.ID, Status: models.PreferencePending})
+	addApplicant(t, store, course, workflow.StatusDraft, 99)
+	addApplicant(t, store, course, workflow.StatusWithdrawn, 99)
+	addApplicant(t, store, other, workflow.StatusSubmitted, 99,
+		models.Preference{Rank: 1, CourseID: course.ID, Status: models.PreferenceIneligible})
+
+	list, err := s.Build(context.Background(), course.ID, testCycle, merit.DefaultWeights)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(list.Entries) != 2 || list.Entries[0].AdmissionID != ranking.ID || list.Entries[1].AdmissionID != direct.ID {
+		t.Fatalf("entries = %+v, want the ranking application then the direct one", list.Entries)
+	}
+	if list.Seats != 1 || !list.Entries[0].WithinCutoff || list.Entries[1].WithinCutoff {
+		t.Errorf("cutoff after %d seats: %+v", list.Seats, list.Entries)
+	}
+}
+
+func TestMeritBuildErrors(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	s := NewMeritService(store)
+	course := addCourse(t, store, 1)
+	ctx := context.Background()
+
+	if _, err := s.Build(ctx, course.ID, testCycle, models.MeritWeights{}); !errors.Is(err, merit.ErrNoWeights) {
+		t.Errorf("Build without weights = %v, want ErrNoWeights", err)
+	}
+	if _, err := s.Build(ctx, primitive.NewObjectID(), testCycle, merit.DefaultWeights); !errors.Is(err, ErrCourseNotFound) {
+		t.Errorf("Build for a missing course = %v, want ErrCourseNotFound", err)
+	}
+	if _, err := s.Build(ctx, course.ID, testCycle, merit.DefaultWeights); !errors.Is(err, ErrCycleNotFound) {
+		t.Errorf("Build for a missing cycle = %v, want ErrCycleNotFound", err)
+	}
+}
+
+func TestMeritPublishNumbersVersions(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	s := NewMeritService(store)
+	course := addCourse(t, store, 1)
+	addCycle(t, store, course)
+	addApplicant(t, store, course, workflow.StatusSubmitted, 70)
+
+	for version := 1; version <= 2; version++ {
+		list, err := s.Publish(ctx, course.ID, testCycle, merit.DefaultWeights, officer)
+		if err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+		if list.Version != version || list.ID.IsZero() || list.PublishedBy != officer.ID {
+			t.Errorf("published version %d with ID %s by %s, want version %d", list.Version, list.ID.Hex(), list.PublishedBy.Hex(), version)
+		}
+	}
+	latest, err := store.MeritLists.Latest(ctx, course.ID, testCycle)
+	if err != nil || latest.Version != 2 {
+		t.Errorf("Latest = %v, %v, want version 2", latest, err)
+	}
+}
+
+// racingMeritLists lets another publisher take the next version first.
+type racingMeritLists struct {
+	repositories.MeritListRepository
+	races int
+}
+
+func (r *racingMeritLists) Create(ctx context.Context, list *models.MeritList) error {
+	if r.races > 0 {
+		r.races--
+		rival := *list
+		if err := r.MeritListRepository.Create(ctx, &rival); err != nil {
+			return err
+		}
+	}
+	return r.MeritListRepository.Create(ctx, list)
+}
+
+func TestMeritPublishRetriesLostRace(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	s := NewMeritService(store)
+	course := addCourse(t, store, 1)
+	addCycle(t, store, course)
+
+	s.MeritLists = &racingMeritLists{MeritListRepository: store.MeritLists, races: 1}
+	list, err := s.Publish(ctx, course.ID, testCycle, merit.DefaultWeights, officer)
+	if err != nil || list.Version != 2 {
+		t.Fatalf("Publish after losing a race = %v, %v, want version 2", list, err)
+	}
+
+	s.MeritLists = &racingMeritLists{MeritListRepository: store.MeritLists, races: maxPublishAttempts}
+	if _, err := s.Publish(ctx, course.ID, testCycle, merit.DefaultWeights, officer); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("Publish losing every race = %v, want ErrConflict", err)
+	}
+}
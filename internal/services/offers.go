@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/workflow"
+)
+
+// DefaultOfferWindow is how long an applicant has to answer an offer unless
+// AdmissionService.OfferWindow says otherwise.
+const DefaultOfferWindow = 7 * 24 * time.Hour
+
+// ErrOfferExpired is returned when answering an offer after its deadline.
+// The offer is expired and its seat given up as a side effect.
+var ErrOfferExpired = errors.New("offer has expired")
+
+// offerDeadline returns the deadline for answering an offer made at now, or
+// nil when the move to status to makes no offer or offers never expire.
+func (s *AdmissionService) offerDeadline(to string, now time.Time) *time.Time {
+	if to != workflow.StatusOffered || s.OfferWindow <= 0 {
+		return nil
+	}
+	deadline := now.Add(s.OfferWindow)
+	return &deadline
+}
+
+// checkOfferDeadline refuses to accept or decline an offer past its
+// deadline, expiring the offer instead.
+func (s *AdmissionService) checkOfferDeadline(ctx context.Context, admission *models.Admission, to string) error {
+	if workflow.Normalize(admission.Status) != workflow.StatusOffered || admission.OfferExpiresAt == nil {
+		return nil
+	}
+	if to != workflow.StatusAccepted && to != workflow.StatusDeclined {
+		return nil
+	}
+	if time.Now().Before(*admission.OfferExpiresAt) {
+		return nil
+	}
+	if err := s.expire(ctx, admission); err != nil && !errors.Is(err, repositories.ErrConflict) {
+		return err
+	}
+	return ErrOfferExpired
+}
+
+// expire ends an unanswered offer, freeing its seat.
+func (s *AdmissionService) expire(ctx context.Context, admission *models.Admission) error {
+	if _, err := s.Transition(ctx, admission, workflow.StatusExpired, SystemActor, "Offer expired without an answer"); err != nil {
+		return err
+	}
+	_, err := s.recordSystemChange(ctx, audit.ActionAdmissionTransition, admission)
+	return err
+}
+
+// ExpireOffers expires every offer whose deadline is before now and returns
+// how many it expired.
+func (s *AdmissionService) ExpireOffers(ctx context.Context, now time.Time) (int, error) {
+	opts := query.Options{Limit: query.MaxLimit, Sort: []query.Sort{{Field: "offerExpiresAt"}}}.
+		Where("status", query.OpEq, workflow.StatusOffered).
+		Where("offerExpiresAt", query.OpLte, now)
+	expired := 0
+	for {
+		page, err := s.Admissions.List(ctx, opts)
+		if err != nil {
+			return expired, err
+		}
+		progress := false
+		for i := range page.Items {
+			err := s.expire(ctx, &page.Items[i])
+			switch {
+			case err == nil:
+				expired++
+				progress = true
+			case errors.Is(err, repositories.ErrConflict):
+				// Answered while we were looking at it
+			default:
+				return expired, err
+			}
+		}
+		if !progress || len(page.Items) < opts.Limit {
+			return expired, nil
+		}
+	}
+}
+
+// RunOfferExpiry expires overdue offers every interval until ctx is done.
+func (s *AdmissionService) RunOfferExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if n, err := s.ExpireOffers(ctx, now); err != nil {
+				log.Printf("Error expiring offers: %v", err)
+			} else if n > 0 {
+				log.Printf("Expired %d unanswered offers", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/workflow"
+)
+
+// offer makes an offer on a new application to course and returns it as
+// stored.
+func offer(t *testing.T, s *AdmissionService, store *repositories.Store, course *models.Course) *models.Admission {
+	t.Helper()
+	admission := addAdmission(t, store, course, workflow.StatusUnderReview)
+	if to, err := s.Transition(context.Background(), admission, workflow.StatusOffered, officer, ""); err != nil || to != workflow.StatusOffered {
+		t.Fatalf("making an offer = %s, %v", to, err)
+	}
+	return reload(t, store, admission)
+}
+
+func TestOfferDeadline(t *testing.T) {
+	s, store := newTestService(t)
+	course := addCourse(t, store, 10)
+
+	before := time.Now().Truncate(time.Millisecond)
+	admission := offer(t, s, store, course)
+	if admission.OfferExpiresAt == nil || admission.OfferExpiresAt.Before(before.Add(DefaultOfferWindow)) || admission.OfferExpiresAt.After(time.Now().Add(DefaultOfferWindow)) {
+		t.Errorf("offerExpiresAt = %v, want %s after the offer", admission.OfferExpiresAt, DefaultOfferWindow)
+	}
+
+	s.OfferWindow = 0
+	if admission := offer(t, s, store, course); admission.OfferExpiresAt != nil {
+		t.Errorf("offerExpiresAt = %v with no offer window, want none", admission.OfferExpiresAt)
+	}
+}
+
+func TestAnsweringExpiredOffer(t *testing.T) {
+	ctx := context.Background()
+	student := Actor{Role: rbac.RoleStudent}
+	for _, to := range []string{workflow.StatusAccepted, workflow.StatusDeclined} {
+		t.Run(to, func(t *testing.T) {
+			s, store := newTestService(t)
+			course := addCourse(t, store, 1)
+			s.OfferWindow = time.Millisecond
+			admission := offer(t, s, store, course)
+			time.Sleep(2 * time.Millisecond)
+
+			if _, err := s.Transition(ctx, admission, to, student, ""); !errors.Is(err, ErrOfferExpired) {
+				t.Fatalf("answering an expired offer = %v, want ErrOfferExpired", err)
+			}
+			if stored := reload(t, store, admission); stored.Status != workflow.StatusExpired || stored.OfferExpiresAt != nil {
+				t.Errorf("admission = %s with deadline %v, want expired with none", stored.Status, stored.OfferExpiresAt)
+			}
+			if n := seatsFilled(t, store, course); n != 0 {
+				t.Errorf("seatsFilled = %d after the offer expired, want 0", n)
+			}
+		})
+	}
+
+	// Answering in time is unaffected
+	s, store := newTestService(t)
+	admission := offer(t, s, store, addCourse(t, store, 1))
+	if to, err := s.Transition(ctx, admission, workflow.StatusAccepted, student, ""); err != nil || to != workflow.StatusAccepted {
+		t.Errorf("accepting in time = %s, %v", to, err)
+	}
+}
+
+func TestExpireOffers(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestService(t)
+	s.Audit = audit.NewRecorder(store.Audit, store.AuditQueue)
+	course := addCourse(t, store, 10)
+	overdue := []*models.Admission{offer(t, s, store, course), offer(t, s, store, course)}
+	s.OfferWindow = 2 * DefaultOfferWindow
+	current := offer(t, s, store, course)
+
+	n, err := s.ExpireOffers(ctx, time.Now().Add(DefaultOfferWindow+time.Minute))
+	if err != nil || n != 2 {
+		t.Fatalf("ExpireOffers = %d, %v, want 2", n, err)
+	}
+	for _, admission := range overdue {
+		if stored := reload(t, store, admission); stored.Status != workflow.StatusExpired {
+			t.Errorf("overdue offer is %s, want expired", stored.Status)
+		}
+	}
+	if stored := reload(t, store, current); stored.Status != workflow.StatusOffered {
+		t.Errorf("offer still open is %s, want offered", stored.Status)
+	}
+	if n := seatsFilled(t, store, course); n != 1 {
+		t.Errorf("seatsFilled = %d, want 1", n)
+	}
+	page, err := store.Audit.List(ctx, query.Options{})
+	if err != nil || page.Total != 2 {
+		t.Errorf("%d audit events for the expiries, want 2 (%v)", page.Total, err)
+	}
+
+	// Nothing is left to expire
+	if n, err := s.ExpireOffers(ctx, time.Now().Add(DefaultOfferWindow+time.Minute)); err != nil || n != 0 {
+		t.Errorf("second ExpireOffers = %d, %v, want 0", n, err)
+	}
+}
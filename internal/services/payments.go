@@ -0,0 +1,432 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/payments"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/workflow"
+)
+
+var (
+	// ErrNothingDue is returned when a payment is requested for fees that
+	// add up to zero.
+	ErrNothingDue = errors.New("nothing to pay")
+	// ErrAlreadyPaid is returned when the fee has already been paid.
+	ErrAlreadyPaid = errors.New("fee already paid")
+	// ErrPaymentNotDue is returned when the admission is not at the stage
+	// where the fee is collected.
+	ErrPaymentNotDue = errors.New("fee is not due at this stage")
+	// ErrNotRefundable is returned when refunding a payment that was never
+	// captured or has been fully refunded.
+	ErrNotRefundable = errors.New("payment cannot be refunded")
+	// ErrRefundPending is returned when the gateway's answer to a refund
+	// is unknown. The refund stays reserved; repeating the request with the
+	// same key finishes it.
+	ErrRefundPending = errors.New("refund outcome unknown")
+)
+
+// paymentStage is the status an admission must be in to pay for purpose.
+var paymentStage = map[string]string{
+	models.PaymentPurposeApplication: workflow.StatusDraft,
+	models.PaymentPurposeTuition:     workflow.StatusAccepted,
+}
+
+// checkPayment returns ErrPaymentRequired when moving admission to status
+// to needs a fee that has not been paid: the application fee to submit and
+// tuition to enrol.
+func (s *AdmissionService) checkPayment(ctx context.Context, admission *models.Admission, to string) error {
+	var purpose string
+	switch to {
+	case workflow.StatusSubmitted:
+		purpose = models.PaymentPurposeApplication
+	case workflow.StatusEnrolled:
+		purpose = models.PaymentPurposeTuition
+	default:
+		return nil
+	}
+
+	lines, err := s.FeeLines(ctx, admission, purpose)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	paid, err := s.paid(ctx, admission.ID, purpose)
+	if err != nil {
+		return err
+	}
+	if !paid {
+		return ErrPaymentRequired
+	}
+	return nil
+}
+
+// FeeLines returns the fees admission owes for purpose, leaving out those
+// that are zero. The application fee was fixed when the application was
+// made; tuition is taken from the course as it is now.
+func (s *AdmissionService) FeeLines(ctx context.Context, admission *models.Admission, purpose string) ([]models.PaymentLine, error) {
+	lines := []models.PaymentLine{}
+	add := func(description string, amount float64) {
+		if minor := payments.MinorUnits(amount); minor > 0 {
+			lines = append(lines, models.PaymentLine{Description: description, Amount: minor})
+		}
+	}
+
+	switch purpose {
+	case models.PaymentPurposeApplication:
+		add("Application fee", admission.ApplicationFee)
+		add("Late submission fee", admission.LateFee)
+	case models.PaymentPurposeTuition:
+		course, err := s.Courses.FindByID(ctx, admission.CourseID)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrCourseNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		add("Tuition fee", course.Fees.TuitionFee)
+		add("Other fees", course.Fees.OtherFees)
+	}
+	return lines, nil
+}
+
+// paid reports whether admission has a captured payment for purpose.
+func (s *AdmissionService) paid(ctx context.Context, admissionID primitive.ObjectID, purpose string) (bool, error) {
+	opts := query.Options{Limit: 1}.
+		Where("admissionId", query.OpEq, admissionID).
+		Where("purpose", query.OpEq, purpose).
+		Where("status", query.OpEq, models.PaymentCaptured)
+	page, err := s.Payments.List(ctx, opts)
+	if err != nil {
+		return false, err
+	}
+	return page.Total > 0, nil
+}
+
+// PaymentService collects admission fees through the payment gateway and
+// moves admissions on once their fee clears.
+type PaymentService struct {
+	Payments   repositories.PaymentRepository
+	Admissions repositories.AdmissionRepository
+	Students   repositories.StudentRepository
+	Courses    repositories.CourseRepository
+	Gateway    payments.PaymentGateway
+
+	AdmissionService *AdmissionService
+}
+
+func NewPaymentService(store *repositories.Store, gateway payments.PaymentGateway, admissions *AdmissionService) *PaymentService {
+	return &PaymentService{
+		Payments:         store.Payments,
+		Admissions:       store.Admissions,
+		Students:         store.Students,
+		Courses:          store.Courses,
+		Gateway:          gateway,
+		AdmissionService: admissions,
+	}
+}
+
+// CreateIntent starts paying the fee for purpose on admission. If a payment
+// for it is already waiting for the payer, that one is returned instead and
+// created is false.
+func (s *PaymentService) CreateIntent(ctx context.Context, admission *models.Admission, purpose string) (payment *models.Payment, created bool, err error) {
+	if stage, ok := paymentStage[purpose]; !ok || workflow.Normalize(admission.Status) != stage {
+		return nil, false, ErrPaymentNotDue
+	}
+	if purpose == models.PaymentPurposeApplication && admission.SubmittedAt == nil {
+		return nil, false, ErrNotSubmitted
+	}
+	lines, err := s.AdmissionService.FeeLines(ctx, admission, purpose)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(lines) == 0 {
+		return nil, false, ErrNothingDue
+	}
+
+	opts := query.Options{}.
+		Where("admissionId", query.OpEq, admission.ID).
+		Where("purpose", query.OpEq, purpose).
+		Where("status", query.OpIn, []any{models.PaymentPending, models.PaymentCaptured})
+	existing, err := s.Payments.List(ctx, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	for i := range existing.Items {
+		if existing.Items[i].Status == models.PaymentCaptured {
+			return nil, false, ErrAlreadyPaid
+		}
+	}
+	if len(existing.Items) > 0 {
+		return &existing.Items[0], false, nil
+	}
+
+	var total int64
+	for _, line := range lines {
+		total += line.Amount
+	}
+	now := time.Now()
+	payment = &models.Payment{
+		ID:          primitive.NewObjectID(),
+		AdmissionID: admission.ID,
+		StudentID:   admission.StudentID,
+		Purpose:     purpose,
+		Amount:      total,
+		Currency:    payments.Currency(),
+		Lines:       lines,
+		Status:      models.PaymentPending,
+		Gateway:     s.Gateway.Name(),
+		Refunds:     []models.Refund{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	intent, err := s.Gateway.CreateIntent(ctx, payments.IntentRequest{
+		Amount:      total,
+		Currency:    payment.Currency,
+		Reference:   payment.ID.Hex(),
+		Description: strings.ReplaceAll(purpose, "_", " ") + " for admission " + admission.ID.Hex(),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	payment.IntentID, payment.ClientSecret = intent.ID, intent.ClientSecret
+
+	if err := s.Payments.Create(ctx, payment); err != nil {
+		return nil, false, err
+	}
+	return payment, true, nil
+}
+
+// HandleWebhook verifies and applies a gateway callback. Callbacks may be
+// repeated or arrive out of order; each payment is settled only once.
+func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, header http.Header) (*models.Payment, error) {
+	event, err := s.Gateway.ParseWebhook(payload, header)
+	if err != nil {
+		return nil, err
+	}
+	payment, err := s.Payments.FindByIntent(ctx, event.IntentID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch event.Type {
+	case payments.EventAuthorized:
+		err = s.capture(ctx, payment)
+	case payments.EventFailed:
+		err = s.Payments.Fail(ctx, payment.ID, event.Reason, time.Now())
+		if errors.Is(err, repositories.ErrConflict) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.Payments.FindByID(ctx, payment.ID)
+}
+
+// capture collects an authorized payment, issues its receipt and moves the
+// admission on.
+func (s *PaymentService) capture(ctx context.Context, payment *models.Payment) error {
+	switch payment.Status {
+	case models.PaymentCaptured:
+		// A repeated callback; make sure the admission moved on
+		s.advance(ctx, payment)
+		return nil
+	case models.PaymentPending:
+	default:
+		return nil
+	}
+
+	// A second payment for a fee already paid is left uncaptured, so the
+	// payer's authorization lapses instead of charging them twice
+	paid, err := s.AdmissionService.paid(ctx, payment.AdmissionID, payment.Purpose)
+	if err != nil {
+		return err
+	}
+	if paid {
+		err := s.Payments.Fail(ctx, payment.ID, "fee already paid by another payment", time.Now())
+		if errors.Is(err, repositories.ErrConflict) {
+			return nil
+		}
+		return err
+	}
+
+	if err := s.Gateway.Capture(ctx, payment.IntentID, "capture-"+payment.ID.Hex()); err != nil {
+		return err
+	}
+	receipt, err := s.receipt(ctx, payment, time.Now())
+	if err != nil {
+		return err
+	}
+	err = s.Payments.Capture(ctx, payment.ID, *receipt)
+	switch {
+	case errors.Is(err, repositories.ErrDuplicate):
+		// Another payment for the fee was captured since the check above;
+		// this one was collected too, so pay it straight back
+		return s.refundDuplicate(ctx, payment)
+	case err != nil && !errors.Is(err, repositories.ErrConflict):
+		return err
+	}
+	s.advance(ctx, payment)
+	return nil
+}
+
+// refundDuplicate pays back a payment collected for a fee another payment
+// had already paid, and marks it failed.
+func (s *PaymentService) refundDuplicate(ctx context.Context, payment *models.Payment) error {
+	if _, err := s.Gateway.Refund(ctx, payment.IntentID, payment.Amount, "refund-"+payment.ID.Hex()+"-duplicate"); err != nil {
+		return err
+	}
+	err := s.Payments.Fail(ctx, payment.ID, "fee already paid by another payment; refunded", time.Now())
+	if errors.Is(err, repositories.ErrConflict) {
+		return nil
+	}
+	return err
+}
+
+func (s *PaymentService) receipt(ctx context.Context, payment *models.Payment, now time.Time) (*models.Receipt, error) {
+	receipt := &models.Receipt{
+		Number:   "RCPT-" + now.UTC().Format("20060102") + "-" + strings.ToUpper(payment.ID.Hex()),
+		IssuedAt: now,
+		Lines:    payment.Lines,
+		Total:    payment.Amount,
+		Currency: payment.Currency,
+	}
+	if student, err := s.Students.FindByID(ctx, payment.StudentID); err == nil {
+		receipt.StudentName, receipt.StudentEmail = student.Name, student.Email
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, err
+	}
+	admission, err := s.Admissions.FindByID(ctx, payment.AdmissionID)
+	if err != nil {
+		return nil, err
+	}
+	if course, err := s.Courses.FindByID(ctx, admission.CourseID); err == nil {
+		receipt.CourseName = course.Name
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// advance submits the admission once its application fee is paid, or
+// enrols it once tuition is paid. Failures are logged; staff can still
+// make the move by hand.
+func (s *PaymentService) advance(ctx context.Context, payment *models.Payment) {
+	admission, err := s.Admissions.FindByID(ctx, payment.AdmissionID)
+	if err != nil {
+		log.Printf("Error loading admission %s after payment %s: %v", payment.AdmissionID.Hex(), payment.ID.Hex(), err)
+		return
+	}
+
+	var to, comments string
+	switch {
+	case payment.Purpose == models.PaymentPurposeApplication && admission.Status == workflow.StatusDraft:
+		to, comments = workflow.StatusSubmitted, "Application fee paid"
+	case payment.Purpose == models.PaymentPurposeTuition && admission.Status == workflow.StatusAccepted:
+		to, comments = workflow.StatusEnrolled, "Tuition paid"
+	default:
+		return
+	}
+	if _, err := s.AdmissionService.Transition(ctx, admission, to, SystemActor, comments); err != nil {
+		log.Printf("Error moving admission %s to %s after payment %s: %v", admission.ID.Hex(), to, payment.ID.Hex(), err)
+	}
+}
+
+// Refund pays back amount of a captured payment. key makes the request
+// idempotent: repeating it returns the original refund, with replayed set,
+// instead of paying out again. The refund is reserved on the payment before
+// the gateway is asked to pay it, so concurrent refunds cannot exceed what
+// was captured.
+func (s *PaymentService) Refund(ctx context.Context, payment *models.Payment, amount int64, reason, key string, actor Actor) (refund *models.Refund, replayed bool, err error) {
+	if existing := findRefund(payment, key); existing != nil {
+		if existing.Status != models.RefundPending {
+			return existing, true, nil
+		}
+		// An earlier attempt did not hear back from the gateway
+		return s.payRefund(ctx, payment, existing)
+	}
+	if payment.Status != models.PaymentCaptured {
+		return nil, false, ErrNotRefundable
+	}
+	if amount <= 0 || payment.Refunded+amount > payment.Amount {
+		return nil, false, payments.ErrRefundTooLarge
+	}
+
+	refund = &models.Refund{
+		ID:         primitive.NewObjectID(),
+		Key:        key,
+		Amount:     amount,
+		Reason:     reason,
+		RefundedBy: actor.ID,
+		CreatedAt:  time.Now(),
+	}
+	err = s.Payments.AddRefund(ctx, payment.ID, *refund)
+	switch {
+	case errors.Is(err, repositories.ErrDuplicate):
+		// A concurrent request with the same key got there first
+		current, err := s.Payments.FindByID(ctx, payment.ID)
+		if err != nil {
+			return nil, false, err
+		}
+		return s.Refund(ctx, current, amount, reason, key, actor)
+	case errors.Is(err, repositories.ErrConflict):
+		return nil, false, payments.ErrRefundTooLarge
+	case err != nil:
+		return nil, false, err
+	}
+	return s.payRefund(ctx, payment, refund)
+}
+
+// payRefund asks the gateway to pay a reserved refund and settles it. The
+// reservation is given back if the gateway refuses the refund, and kept if
+// its answer is unknown, since the money may have been paid.
+func (s *PaymentService) payRefund(ctx context.Context, payment *models.Payment, refund *models.Refund) (*models.Refund, bool, error) {
+	gatewayRef, err := s.Gateway.Refund(ctx, payment.IntentID, refund.Amount, "refund-"+payment.ID.Hex()+"-"+refund.Key)
+	switch {
+	case errors.Is(err, payments.ErrRefundTooLarge), errors.Is(err, payments.ErrNotAuthorized), errors.Is(err, payments.ErrIntentNotFound):
+		if err := s.Payments.CancelRefund(ctx, payment.ID, refund.Key); err != nil && !errors.Is(err, repositories.ErrConflict) {
+			log.Printf("Error cancelling refund %s of payment %s: %v", refund.Key, payment.ID.Hex(), err)
+		}
+		return nil, false, err
+	case err != nil:
+		log.Printf("Error refunding payment %s: %v", payment.ID.Hex(), err)
+		return nil, false, ErrRefundPending
+	}
+
+	err = s.Payments.SettleRefund(ctx, payment.ID, refund.Key, gatewayRef, time.Now())
+	if errors.Is(err, repositories.ErrConflict) {
+		// A concurrent retry settled it first
+		current, err := s.Payments.FindByID(ctx, payment.ID)
+		if err != nil {
+			return nil, false, err
+		}
+		return findRefund(current, refund.Key), true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	settled := *refund
+	settled.Status, settled.GatewayRef = models.RefundSucceeded, gatewayRef
+	return &settled, false, nil
+}
+
+func findRefund(payment *models.Payment, key string) *models.Refund {
+	for i := range payment.Refunds {
+		if payment.Refunds[i].Key == key {
+			return &payment.Refunds[i]
+		}
+	}
+	return nil
+}
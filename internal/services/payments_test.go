@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/payments"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/workflow"
+)
+
+// flakyGateway fails refunds with refundErr while it is set.
+type flakyGateway struct {
+	*payments.FakeGateway
+	refundErr error
+}
+
+func (g *flakyGateway) Refund(ctx context.Context, intentID string, amount int64, idempotencyKey string) (string, error) {
+	if g.refundErr != nil {
+		return "", g.refundErr
+	}
+	return g.FakeGateway.Refund(ctx, intentID, amount, idempotencyKey)
+}
+
+// newPaymentService returns a service over a fake gateway and an accepted
+// admission owing 1000 in tuition.
+func newPaymentService(t *testing.T) (*PaymentService, *flakyGateway, *repositories.Store, *models.Admission) {
+	t.Helper()
+	admissions, store := newTestService(t)
+	gateway := &flakyGateway{FakeGateway: payments.NewFakeGateway([]byte("secret"))}
+	course := &models.Course{Name: "Physics", Seats: 10, Fees: models.Fees{TuitionFee: 10}}
+	if err := store.Courses.Create(context.Background(), course); err != nil {
+		t.Fatalf("creating course: %v", err)
+	}
+	admission := addAdmission(t, store, course, workflow.StatusAccepted)
+	return NewPaymentService(store, gateway, admissions), gateway, store, admission
+}
+
+// pay starts a payment for the admission's tuition and has the payer
+// authorize it.
+func pay(t *testing.T, s *PaymentService, gateway *flakyGateway, admission *models.Admission) *models.Payment {
+	t.Helper()
+	ctx := context.Background()
+	payment, _, err := s.CreateIntent(ctx, admission, models.PaymentPurposeTuition)
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	payload, header, err := gateway.Complete(payment.IntentID, true)
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	payment, err = s.HandleWebhook(ctx, payload, header)
+	if err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	return payment
+}
+
+func TestRefundReservesBeforePaying(t *testing.T) {
+	ctx := context.Background()
+	s, gateway, store, admission := newPaymentService(t)
+	payment := pay(t, s, gateway, admission)
+	if payment.Status != models.PaymentCaptured || payment.Amount != 1000 {
+		t.Fatalf("payment = %s for %d, want captured for 1000", payment.Status, payment.Amount)
+	}
+	reload := func() *models.Payment {
+		stored, err := store.Payments.FindByID(ctx, payment.ID)
+		if err != nil {
+			t.Fatalf("loading payment: %v", err)
+		}
+		return stored
+	}
+
+	// The gateway's answer is lost: the refund stays reserved
+	gateway.refundErr = errors.New("connection reset")
+	if _, _, err := s.Refund(ctx, payment, 600, "", "k1", officer); !errors.Is(err, ErrRefundPending) {
+		t.Fatalf("Refund with no answer = %v, want ErrRefundPending", err)
+	}
+	gateway.refundErr = nil
+	if _, _, err := s.Refund(ctx, reload(), 600, "", "k2", officer); !errors.Is(err, payments.ErrRefundTooLarge) {
+		t.Errorf("Refund past the reservation = %v, want ErrRefundTooLarge", err)
+	}
+
+	// Repeating the key finishes it
+	refund, replayed, err := s.Refund(ctx, reload(), 600, "", "k1", officer)
+	if err != nil || replayed {
+		t.Fatalf("resumed Refund = %v, replayed %v", err, replayed)
+	}
+	if refund.Status != models.RefundSucceeded || refund.GatewayRef == "" {
+		t.Errorf("resumed refund = %s with ref %q, want succeeded with a ref", refund.Status, refund.GatewayRef)
+	}
+	again, replayed, err := s.Refund(ctx, reload(), 600, "", "k1", officer)
+	if err != nil || !replayed || again.ID != refund.ID {
+		t.Errorf("repeated Refund = %v, %v, replayed %v, want the first refund replayed", again, err, replayed)
+	}
+
+	// A refund the gateway refuses gives its reservation back
+	gateway.refundErr = payments.ErrNotAuthorized
+	if _, _, err := s.Refund(ctx, reload(), 400, "", "k3", officer); !errors.Is(err, payments.ErrNotAuthorized) {
+		t.Fatalf("refused Refund = %v, want ErrNotAuthorized", err)
+	}
+	if stored := reload(); stored.Refunded != 600 || len(stored.Refunds) != 1 {
+		t.Errorf("after a refused refund %d is refunded in %d refunds, want 600 in 1", stored.Refunded, len(stored.Refunds))
+	}
+	gateway.refundErr = nil
+	if _, _, err := s.Refund(ctx, reload(), 400, "", "k3", officer); err != nil {
+		t.Fatalf("Refund of the rest: %v", err)
+	}
+	if stored := reload(); stored.Status != models.PaymentRefunded || stored.Refunded != 1000 {
+		t.Errorf("payment = %s with %d refunded, want refunded with 1000", stored.Status, stored.Refunded)
+	}
+}
+
+// racingPayments lets another payment for the same fee be captured just
+// before the one being captured.
+type racingPayments struct {
+	repositories.PaymentRepository
+	rival *models.Payment
+}
+
+func (r *racingPayments) Capture(ctx context.Context, id primitive.ObjectID, receipt models.Receipt) error {
+	if rival := r.rival; rival != nil {
+		r.rival = nil
+		if err := r.PaymentRepository.Capture(ctx, rival.ID, receipt); err != nil {
+			return err
+		}
+	}
+	return r.PaymentRepository.Capture(ctx, id, receipt)
+}
+
+func TestCaptureRefundsLosingPayment(t *testing.T) {
+	ctx := context.Background()
+	s, gateway, store, admission := newPaymentService(t)
+	payment, _, err := s.CreateIntent(ctx, admission, models.PaymentPurposeTuition)
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	// A second tab started its own payment before the first was stored
+	rival := *payment
+	rival.ID, rival.IntentID = primitive.NewObjectID(), ""
+	if err := store.Payments.Create(ctx, &rival); err != nil {
+		t.Fatalf("creating rival payment: %v", err)
+	}
+
+	s.Payments = &racingPayments{PaymentRepository: store.Payments, rival: &rival}
+	payload, header, _ := gateway.Complete(payment.IntentID, true)
+	lost, err := s.HandleWebhook(ctx, payload, header)
+	if err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if lost.Status != models.PaymentFailed || lost.FailureReason == "" {
+		t.Errorf("losing payment = %s (%q), want failed", lost.Status, lost.FailureReason)
+	}
+	if _, err := gateway.Refund(ctx, payment.IntentID, 1, "probe"); !errors.Is(err, payments.ErrRefundTooLarge) {
+		t.Errorf("losing payment was not refunded in full: probe refund = %v", err)
+	}
+	if won, _ := store.Payments.FindByID(ctx, rival.ID); won.Status != models.PaymentCaptured {
+		t.Errorf("winning payment = %s, want captured", won.Status)
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/mailer"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/repositories"
+	"admission-portal-backend/internal/workflow"
+)
+
+// ErrNotWaitlisted is returned when asking for the waitlist position of an
+// admission that is not on a waitlist.
+var ErrNotWaitlisted = errors.New("admission is not waitlisted")
+
+// waitlistOrder ranks a waitlist: first come, first served. Admissions
+// waitlisted before the order was kept sort by when they applied.
+var waitlistOrder = []query.Sort{{Field: "waitlistedAt"}, {Field: "createdAt"}, {Field: "_id"}}
+
+// Waitlist returns the admissions waiting for a seat in courseID in cycle,
+// first in line first.
+func (s *AdmissionService) Waitlist(ctx context.Context, cycle string, courseID primitive.ObjectID) ([]models.Admission, error) {
+	opts := query.Options{Sort: waitlistOrder}.
+		Where("status", query.OpEq, workflow.StatusWaitlisted).
+		Where("courseId", query.OpEq, courseID)
+	if cycle != "" {
+		opts = opts.Where("cycle", query.OpEq, cycle)
+	}
+	page, err := s.Admissions.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// WaitlistPosition returns admission's place on its course's waitlist,
+// counting from 1, and how many are waiting in all.
+func (s *AdmissionService) WaitlistPosition(ctx context.Context, admission *models.Admission) (int, int, error) {
+	if workflow.Normalize(admission.Status) != workflow.StatusWaitlisted {
+		return 0, 0, ErrNotWaitlisted
+	}
+	waitlist, err := s.Waitlist(ctx, admission.Cycle, admission.CourseID)
+	if err != nil {
+		return 0, 0, err
+	}
+	for i := range waitlist {
+		if waitlist[i].ID == admission.ID {
+			return i + 1, len(waitlist), nil
+		}
+	}
+	// Moved off the waitlist since it was loaded
+	return 0, 0, ErrNotWaitlisted
+}
+
+// fillFreedSeat offers a seat given up in courseID to the first application
+// on the course's waitlist in the same cycle that can take it. Candidates
+// can fail to when the seat is in a quota they do not qualify for.
+func (s *AdmissionService) fillFreedSeat(ctx context.Context, cycle string, courseID primitive.ObjectID) {
+	waitlist, err := s.Waitlist(ctx, cycle, courseID)
+	if err != nil {
+		log.Printf("Error loading waitlist for course %s: %v", courseID.Hex(), err)
+		return
+	}
+	for i := range waitlist {
+		candidate := &waitlist[i]
+		_, err := s.Transition(ctx, candidate, workflow.StatusOffered, SystemActor, "Seat freed, offered from the waitlist")
+		switch {
+		case err == nil:
+			updated, err := s.recordSystemChange(ctx, audit.ActionWaitlistPromote, candidate)
+			if err != nil {
+				log.Printf("Error auditing promotion of admission %s: %v", candidate.ID.Hex(), err)
+			}
+			if updated != nil && workflow.Normalize(updated.Status) == workflow.StatusOffered {
+				s.notifyOffer(ctx, updated)
+			}
+			return
+		case errors.Is(err, repositories.ErrNoSeats), errors.Is(err, repositories.ErrConflict):
+			continue
+		default:
+			log.Printf("Error offering freed seat to admission %s: %v", candidate.ID.Hex(), err)
+			return
+		}
+	}
+}
+
+// recordSystemChange audits a change the service made to before on its own
+// and returns the admission as it is now. The admission is returned even
+// when only the audit failed.
+func (s *AdmissionService) recordSystemChange(ctx context.Context, action string, before *models.Admission) (*models.Admission, error) {
+	after, err := s.Admissions.FindByID(ctx, before.ID)
+	if err != nil {
+		return nil, err
+	}
+	if s.Audit == nil {
+		return after, nil
+	}
+	entry := audit.Entry{
+		ActorRole:  workflow.RoleSystem,
+		Action:     action,
+		TargetType: audit.TargetAdmission,
+		TargetID:   before.ID.Hex(),
+		Before:     before,
+		After:      after,
+	}
+	if err := s.Audit.Record(ctx, entry); err != nil {
+		return after, fmt.Errorf("recording audit event %s for admission %s: %w", action, before.ID.Hex(), err)
+	}
+	return after, nil
+}
+
+// notifyOffer emails the applicant that they have been offered a seat and
+// when the offer expires.
+func (s *AdmissionService) notifyOffer(ctx context.Context, admission *models.Admission) {
+	if s.Mailer == nil {
+		return
+	}
+	student, err := s.Students.FindByID(ctx, admission.StudentID)
+	if err != nil {
+		log.Printf("Error loading student for offer on admission %s: %v", admission.ID.Hex(), err)
+		return
+	}
+	courseName := admission.CourseID.Hex()
+	if course, err := s.Courses.FindByID(ctx, admission.CourseID); err == nil {
+		courseName = course.Name
+	}
+	deadline := "There is no deadline to answer it."
+	if admission.OfferExpiresAt != nil {
+		deadline = "Please accept or decline it by " + admission.OfferExpiresAt.UTC().Format("2 January 2006 15:04 MST") + ", after which it will lapse."
+	}
+	msg := mailer.Message{
+		To:      student.Email,
+		Subject: "You have been offered a place",
+		Body: fmt.Sprintf("Hello %s,\n\nA seat has become available in %s and has been offered to you from the waitlist.\n\n%s\n",
+			student.Name, courseName, deadline),
+	}
+	if err := s.Mailer.Send(ctx, msg); err != nil {
+		log.Printf("Error sending offer notification for admission %s: %v", admission.ID.Hex(), err)
+	}
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"admission-portal-backend/internal/audit"
+	"admission-portal-backend/internal/mailer"
+	"admission-portal-backend/internal/models"
+	"admission-portal-backend/internal/query"
+	"admission-portal-backend/internal/rbac"
+	"admission-portal-backend/internal/workflow"
+)
+
+// sentMail keeps the messages it is asked to send.
+type sentMail struct {
+	messages []mailer.Message
+}
+
+func (m *sentMail) Send(ctx context.Context, msg mailer.Message) error {
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func TestWaitlistPosition(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestService(t)
+	course := addCourse(t, store, 1)
+	start := time.Now()
+	waitlisted := make([]*models.Admission, 3)
+	for i := range waitlisted {
+		waitlisted[i] = addAdmission(t, store, course, workflow.StatusWaitlisted)
+	}
+	// Joining the waitlist, not applying, decides the order
+	for i, admission := range []*models.Admission{waitlisted[2], waitlisted[0], waitlisted[1]} {
+		at := start.Add(time.Duration(i) * time.Minute)
+		change := models.StatusChange{From: workflow.StatusWaitlisted, To: workflow.StatusWaitlisted, ChangedAt: at}
+		if err := store.Admissions.Transition(ctx, admission.ID, change); err != nil {
+			t.Fatalf("rewaitlisting: %v", err)
+		}
+	}
+	addAdmission(t, store, addCourse(t, store, 1), workflow.StatusWaitlisted)
+
+	for want, admission := range []*models.Admission{waitlisted[2], waitlisted[0], waitlisted[1]} {
+		position, total, err := s.WaitlistPosition(ctx, reload(t, store, admission))
+		if err != nil || position != want+1 || total != 3 {
+			t.Errorf("WaitlistPosition = %d of %d, %v, want %d of 3", position, total, err, want+1)
+		}
+	}
+	offered := addAdmission(t, store, course, workflow.StatusOffered)
+	if _, _, err := s.WaitlistPosition(ctx, offered); !errors.Is(err, ErrNotWaitlisted) {
+		t.Errorf("WaitlistPosition of an offer = %v, want ErrNotWaitlisted", err)
+	}
+}
+
+func TestFreedSeatGoesToWaitlist(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestService(t)
+	mail := &sentMail{}
+	s.Mailer, s.Audit = mail, audit.NewRecorder(store.Audit, store.AuditQueue)
+	course := addCourse(t, store, 1)
+	student := &models.Student{Name: "Waiting", Email: "waiting@example.com"}
+	if err := store.Students.Create(ctx, student); err != nil {
+		t.Fatalf("creating student: %v", err)
+	}
+
+	holder := addAdmission(t, store, course, workflow.StatusUnderReview)
+	if _, err := s.Transition(ctx, holder, workflow.StatusOffered, officer, ""); err != nil {
+		t.Fatalf("offering the seat: %v", err)
+	}
+	now := time.Now()
+	first := &models.Admission{StudentID: student.ID, CourseID: course.ID, Status: workflow.StatusUnderReview, SubmittedAt: &now, CreatedAt: now}
+	if err := store.Admissions.Create(ctx, first); err != nil {
+		t.Fatalf("creating admission: %v", err)
+	}
+	waiting := []*models.Admission{first, addAdmission(t, store, course, workflow.StatusUnderReview)}
+	for _, admission := range waiting {
+		if to, err := s.Transition(ctx, admission, workflow.StatusOffered, officer, ""); err != nil || to != workflow.StatusWaitlisted {
+			t.Fatalf("offering a seat in a full course = %s, %v, want waitlisted", to, err)
+		}
+	}
+
+	if _, err := s.Transition(ctx, reload(t, store, holder), workflow.StatusDeclined, Actor{Role: rbac.RoleStudent}, ""); err != nil {
+		t.Fatalf("declining the offer: %v", err)
+	}
+	first, second := reload(t, store, first), reload(t, store, waiting[1])
+	if first.Status != workflow.StatusOffered || first.WaitlistedAt != nil || first.OfferExpiresAt == nil {
+		t.Errorf("first in line = %s, waitlistedAt %v, deadline %v, want offered with a deadline", first.Status, first.WaitlistedAt, first.OfferExpiresAt)
+	}
+	if second.Status != workflow.StatusWaitlisted {
+		t.Errorf("second in line = %s, want still waitlisted", second.Status)
+	}
+	if n := seatsFilled(t, store, course); n != 1 {
+		t.Errorf("seatsFilled = %d, want 1", n)
+	}
+
+	page, err := store.Audit.List(ctx, query.Options{}.Where("action", query.OpEq, audit.ActionWaitlistPromote))
+	if err != nil || page.Total != 1 || page.Items[0].TargetID != first.ID.Hex() {
+		t.Errorf("promotion audit events = %v, %v, want one for %s", page, err, first.ID.Hex())
+	}
+	if len(mail.messages) != 1 || mail.messages[0].To != student.Email {
+		t.Errorf("offer emails = %+v, want one to %s", mail.messages, student.Email)
+	}
+}
@@ -0,0 +1,154 @@
+// Package workflow defines the admission lifecycle: the statuses an
+// application can be in and which roles may move it between them.
+package workflow
+
+import (
+	"errors"
+	"sort"
+)
+
+const (
+	StatusDraft       = "draft"
+	StatusSubmitted   = "submitted"
+	StatusUnderReview = "under_review"
+	StatusShortlisted = "shortlisted"
+	StatusOffered     = "offered"
+	StatusAccepted    = "accepted"
+	StatusEnrolled    = "enrolled"
+	StatusRejected    = "rejected"
+	StatusWithdrawn   = "withdrawn"
+	StatusWaitlisted  = "waitlisted"
+	// StatusDeclined and StatusExpired end offers the applicant turned down
+	// or did not answer in time.
+	StatusDeclined = "declined"
+	StatusExpired  = "expired"
+)
+
+// Roles that can drive a transition. RoleStaff covers every staff role; which
+// staff may make a given move is further narrowed by their permissions.
+// RoleSystem is used for changes made by the server itself rather than on
+// behalf of a user.
+const (
+	RoleStudent = "student"
+	RoleStaff   = "staff"
+	RoleSystem  = "system"
+)
+
+var (
+	ErrUnknownStatus     = errors.New("unknown admission status")
+	ErrIllegalTransition = errors.New("illegal admission status transition")
+	ErrRoleNotAllowed    = errors.New("role is not allowed to perform this transition")
+)
+
+// transitions maps a status to the statuses reachable from it and the roles
+// allowed to make each move. Statuses without an entry are terminal.
+var transitions = map[string]map[string][]string{
+	StatusDraft: {
+		// The system submits applications once their fee is paid
+		StatusSubmitted: {RoleStudent, RoleSystem},
+		StatusWithdrawn: {RoleStudent},
+	},
+	StatusSubmitted: {
+		StatusUnderReview: {RoleStaff},
+		StatusRejected:    {RoleStaff},
+		StatusWithdrawn:   {RoleStudent},
+	},
+	StatusUnderReview: {
+		StatusShortlisted: {RoleStaff},
+		StatusOffered:     {RoleStaff},
+		StatusWaitlisted:  {RoleStaff},
+		StatusRejected:    {RoleStaff},
+		StatusWithdrawn:   {RoleStudent},
+	},
+	StatusShortlisted: {
+		StatusOffered:    {RoleStaff},
+		StatusWaitlisted: {RoleStaff},
+		StatusRejected:   {RoleStaff},
+		StatusWithdrawn:  {RoleStudent},
+	},
+	StatusWaitlisted: {
+		StatusOffered:   {RoleStaff, RoleSystem},
+		StatusRejected:  {RoleStaff},
+		StatusWithdrawn: {RoleStudent},
+	},
+	StatusOffered: {
+		StatusAccepted:  {RoleStudent},
+		StatusDeclined:  {RoleStudent},
+		StatusWithdrawn: {RoleStudent, RoleSystem},
+		StatusRejected:  {RoleStaff},
+		// The system expires offers left unanswered past their deadline
+		StatusExpired: {RoleSystem},
+	},
+	StatusAccepted: {
+		// The system enrols applicants once their tuition is paid
+		StatusEnrolled:  {RoleStaff, RoleSystem},
+		StatusWithdrawn: {RoleStudent},
+	},
+}
+
+// legacyStatuses maps statuses written before the lifecycle existed onto
+// their closest equivalent.
+var legacyStatuses = map[string]string{
+	"pending":  StatusSubmitted,
+	"approved": StatusOffered,
+}
+
+// Normalize returns the lifecycle status for s, translating legacy values.
+func Normalize(s string) string {
+	if mapped, ok := legacyStatuses[s]; ok {
+		return mapped
+	}
+	return s
+}
+
+// IsValid reports whether s is a lifecycle status.
+func IsValid(s string) bool {
+	switch s {
+	case StatusDraft, StatusSubmitted, StatusUnderReview, StatusShortlisted, StatusOffered,
+		StatusAccepted, StatusEnrolled, StatusRejected, StatusWithdrawn, StatusWaitlisted,
+		StatusDeclined, StatusExpired:
+		return true
+	}
+	return false
+}
+
+// IsTerminal reports whether no further transitions are possible from s.
+func IsTerminal(s string) bool {
+	_, ok := transitions[Normalize(s)]
+	return !ok
+}
+
+// Check validates moving an admission from one status to another on behalf
+// of role. It returns ErrUnknownStatus, ErrIllegalTransition or
+// ErrRoleNotAllowed when the move is not permitted.
+func Check(from, to, role string) error {
+	from = Normalize(from)
+	if !IsValid(from) || !IsValid(to) {
+		return ErrUnknownStatus
+	}
+	roles, ok := transitions[from][to]
+	if !ok {
+		return ErrIllegalTransition
+	}
+	for _, allowed := range roles {
+		if allowed == role {
+			return nil
+		}
+	}
+	return ErrRoleNotAllowed
+}
+
+// Next lists the statuses role may move an admission to from the given status.
+func Next(from, role string) []string {
+	next := []string{}
+	for to, roles := range transitions[Normalize(from)] {
+		for _, allowed := range roles {
+			if allowed == role {
+				next = append(next, to)
+				break
+			}
+		}
+	}
+	sort.Strings(next)
+	return next
+}
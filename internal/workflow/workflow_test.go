@@ -0,0 +1,80 @@
+package workflow
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		role     string
+		want     error
+	}{
+		{"student submits draft", StatusDraft, StatusSubmitted, RoleStudent, nil},
+		{"system submits paid draft", StatusDraft, StatusSubmitted, RoleSystem, nil},
+		{"staff cannot submit draft", StatusDraft, StatusSubmitted, RoleStaff, ErrRoleNotAllowed},
+		{"staff reviews", StatusSubmitted, StatusUnderReview, RoleStaff, nil},
+		{"student cannot review", StatusSubmitted, StatusUnderReview, RoleStudent, ErrRoleNotAllowed},
+		{"staff offers after review", StatusUnderReview, StatusOffered, RoleStaff, nil},
+		{"offer skips review", StatusSubmitted, StatusOffered, RoleStaff, ErrIllegalTransition},
+		{"system promotes from waitlist", StatusWaitlisted, StatusOffered, RoleSystem, nil},
+		{"student accepts offer", StatusOffered, StatusAccepted, RoleStudent, nil},
+		{"staff cannot accept for student", StatusOffered, StatusAccepted, RoleStaff, ErrRoleNotAllowed},
+		{"system expires offer", StatusOffered, StatusExpired, RoleSystem, nil},
+		{"student cannot expire offer", StatusOffered, StatusExpired, RoleStudent, ErrRoleNotAllowed},
+		{"system enrols after payment", StatusAccepted, StatusEnrolled, RoleSystem, nil},
+		{"rejected is terminal", StatusRejected, StatusSubmitted, RoleStaff, ErrIllegalTransition},
+		{"legacy pending is submitted", "pending", StatusUnderReview, RoleStaff, nil},
+		{"legacy approved is offered", "approved", StatusAccepted, RoleStudent, nil},
+		{"unknown from", "archived", StatusSubmitted, RoleStudent, ErrUnknownStatus},
+		{"unknown to", StatusSubmitted, "archived", RoleStaff, ErrUnknownStatus},
+		{"legacy value is not a target", StatusSubmitted, "approved", RoleStaff, ErrUnknownStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Check(tt.from, tt.to, tt.role); !errors.Is(err, tt.want) {
+				t.Errorf("Check(%q, %q, %q) = %v, want %v", tt.from, tt.to, tt.role, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		from, role string
+		want       []string
+	}{
+		{StatusDraft, RoleStudent, []string{StatusSubmitted, StatusWithdrawn}},
+		{StatusUnderReview, RoleStaff, []string{StatusOffered, StatusRejected, StatusShortlisted, StatusWaitlisted}},
+		{StatusOffered, RoleStudent, []string{StatusAccepted, StatusDeclined, StatusWithdrawn}},
+		{StatusOffered, RoleSystem, []string{StatusExpired, StatusWithdrawn}},
+		{"approved", RoleStudent, []string{StatusAccepted, StatusDeclined, StatusWithdrawn}},
+		{StatusEnrolled, RoleStaff, []string{}},
+	}
+	for _, tt := range tests {
+		if got := Next(tt.from, tt.role); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Next(%q, %q) = %v, want %v", tt.from, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	tests := map[string]bool{
+		StatusDraft:     false,
+		StatusOffered:   false,
+		"approved":      false,
+		StatusEnrolled:  true,
+		StatusRejected:  true,
+		StatusWithdrawn: true,
+		StatusDeclined:  true,
+		StatusExpired:   true,
+	}
+	for status, want := range tests {
+		if got := IsTerminal(status); got != want {
+			t.Errorf("IsTerminal(%q) = %v, want %v", status, got, want)
+		}
+	}
+}